@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -17,7 +14,8 @@ var cleanCmd = &cobra.Command{
 	Long: `WARNING: This is a destructive operation!
 
 Drops the configured keyspace and all its data, along with the migration
-metadata keyspace. Requires the --force flag and interactive confirmation.`,
+metadata keyspace. Requires the --force flag and interactive confirmation
+(or --yes, which still requires --force).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := loadConfig(); err != nil {
 			return err
@@ -28,17 +26,11 @@ metadata keyspace. Requires the --force flag and interactive confirmation.`,
 			return fmt.Errorf("this is a destructive operation — use --force to proceed")
 		}
 
-		// Interactive confirmation
-		fmt.Printf("WARNING: This will DROP keyspace '%s' and ALL its data!\n", cfg.Keyspace)
-		fmt.Printf("It will also DROP the metadata keyspace '%s'.\n\n", cfg.MetadataKeyspace)
-		fmt.Printf("Type the keyspace name '%s' to confirm: ", cfg.Keyspace)
-
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(response)
-
-		if response != cfg.Keyspace {
-			return fmt.Errorf("keyspace name does not match — aborting")
+		// Interactive confirmation, on top of --force
+		promptf("WARNING: This will DROP keyspace '%s' and ALL its data!\n", cfg.Keyspace)
+		promptf("It will also DROP the metadata keyspace '%s'.\n\n", cfg.MetadataKeyspace)
+		if err := confirmTyped(fmt.Sprintf("Type the keyspace name '%s' to confirm", cfg.Keyspace), cfg.Keyspace); err != nil {
+			return err
 		}
 
 		session, err := driver.NewSession(cfg, log)
@@ -47,6 +39,10 @@ metadata keyspace. Requires the --force flag and interactive confirmation.`,
 		}
 		defer session.Close()
 
+		if err := backupBeforeMutation(cmd, session); err != nil {
+			return err
+		}
+
 		// Drop target keyspace
 		log.Warn().Str("keyspace", cfg.Keyspace).Msg("Dropping keyspace")
 		if err := session.Execute(fmt.Sprintf("DROP KEYSPACE IF EXISTS %s", cfg.Keyspace)); err != nil {
@@ -75,4 +71,5 @@ metadata keyspace. Requires the --force flag and interactive confirmation.`,
 func init() {
 	rootCmd.AddCommand(cleanCmd)
 	cleanCmd.Flags().Bool("force", false, "required flag to confirm destructive operation")
+	cleanCmd.Flags().Bool("no-backup", false, "skip the automatic metadata backup")
 }
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionCmd(t *testing.T) {
+	markers := map[string]string{
+		"bash":       "__start_scylla-migrate",
+		"zsh":        "#compdef scylla-migrate",
+		"fish":       "complete -c scylla-migrate",
+		"powershell": "Register-ArgumentCompleter",
+	}
+
+	for shell, marker := range markers {
+		var out bytes.Buffer
+		completionCmd.SetOut(&out)
+		require.NoError(t, completionCmd.RunE(completionCmd, []string{shell}), shell)
+		assert.Contains(t, out.String(), marker, shell)
+	}
+	completionCmd.SetOut(nil)
+
+	assert.Error(t, completionCmd.Args(completionCmd, []string{"tcsh"}))
+}
+
+func TestCompletion_FlagValues(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"__complete", "status", "--format", ""})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, out.String(), "table\njson\n")
+}
+
+func TestManCmd(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "man")
+	t.Setenv("SOURCE_DATE_EPOCH", "1709251200")
+	require.NoError(t, manCmd.Flags().Set("dir", dir))
+	defer manCmd.Flags().Set("dir", "man")
+
+	require.NoError(t, manCmd.RunE(manCmd, nil))
+
+	page, err := os.ReadFile(filepath.Join(dir, "scylla-migrate-migrate.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `.TH "SCYLLA-MIGRATE-MIGRATE" "1" "Mar 2024" "scylla-migrate"`)
+	_, err = os.Stat(filepath.Join(dir, "scylla-migrate-man.1"))
+	assert.True(t, os.IsNotExist(err), "hidden commands get no page")
+}
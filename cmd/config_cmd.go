@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the effective configuration",
+}
+
+// whichFields are the connection-critical keys reported by "config which",
+// with the persistent flag that can override each (if any).
+var whichFields = []struct {
+	key   string
+	flag  string
+	value func(c *config.Config) string
+}{
+	{key: "hosts", flag: "hosts", value: func(c *config.Config) string { return strings.Join(c.Hosts, ",") }},
+	{key: "keyspace", flag: "keyspace", value: func(c *config.Config) string { return c.Keyspace }},
+	{key: "migrations_dir", flag: "migrations-dir", value: func(c *config.Config) string { return strings.Join(c.MigrationsDirs, ",") }},
+	{key: "username", flag: "username", value: func(c *config.Config) string { return c.Username }},
+	{key: "password", flag: "password", value: func(c *config.Config) string {
+		if c.Password == "" {
+			return ""
+		}
+		return "********"
+	}},
+	{key: "consistency", value: func(c *config.Config) string { return c.Consistency }},
+	{key: "metadata_keyspace", value: func(c *config.Config) string { return c.MetadataKeyspace }},
+	{key: "lock_owner_id", value: func(c *config.Config) string { return c.LockOwnerID }},
+}
+
+var configWhichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "Show which config file is in effect and where key settings come from",
+	Long: `Print the config file that was loaded (or that none was found) and, for each
+connection-critical setting, whether its value came from a flag, an
+environment variable, the config file, or the built-in default.
+
+No cluster connection is made and the configuration is not validated; with
+vault configured, the credentials are fetched from Vault.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if err := c.ResolveCredentials(); err != nil {
+			return err
+		}
+
+		file := viper.ConfigFileUsed()
+		if file != "" {
+			if _, err := os.Stat(file); err != nil {
+				file = fmt.Sprintf("%s (not readable: %v)", file, err)
+			}
+		} else {
+			file = "none, using defaults"
+		}
+		fmt.Printf("Config file: %s\n\n", file)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SETTING\tSOURCE\tVALUE")
+		for _, f := range whichFields {
+			value := f.value(c)
+			if value == "" {
+				value = "-"
+			}
+			source := settingSource(f.key, f.flag)
+			if c.CredentialsResolved() && source == "default" && (f.key == "username" || f.key == "password") {
+				source = "vault"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", f.key, source, value)
+		}
+		return w.Flush()
+	},
+}
+
+// settingSource mirrors viper's precedence: flag, env, config file, default.
+// Like viper, it ignores environment variables that are set but empty.
+func settingSource(key, flag string) string {
+	if flag != "" {
+		if pf := rootCmd.PersistentFlags().Lookup(flag); pf != nil && pf.Changed {
+			return "flag --" + flag
+		}
+	}
+	env := "SCYLLA_MIGRATE_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if os.Getenv(env) != "" {
+		return "env " + env
+	}
+	if viper.InConfig(key) {
+		return "file"
+	}
+	return "default"
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configWhichCmd)
+}
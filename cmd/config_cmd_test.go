@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingSource_Env(t *testing.T) {
+	t.Setenv("SCYLLA_MIGRATE_CONSISTENCY", "one")
+	assert.Equal(t, "env SCYLLA_MIGRATE_CONSISTENCY", settingSource("consistency", ""))
+
+	t.Setenv("SCYLLA_MIGRATE_VAULT_TOKEN", "s.token")
+	assert.Equal(t, "env SCYLLA_MIGRATE_VAULT_TOKEN", settingSource("vault.token", ""))
+
+	// viper ignores empty variables, so the value comes from elsewhere
+	t.Setenv("SCYLLA_MIGRATE_METADATA_KEYSPACE", "")
+	assert.Equal(t, "default", settingSource("metadata_keyspace", ""))
+}
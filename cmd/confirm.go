@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// stdinReader is shared by all prompts so that input buffered by one prompt
+// is not lost to the next.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// stdinIsTerminal reports whether stdin is attached to an interactive
+// terminal. Prompts must never block on a pipe or a closed stdin in CI.
+// Tests replace it to drive the prompts from stdinReader.
+var stdinIsTerminal = func() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// promptf prints confirmation prompt text. Under --quiet it is suppressed
+// when stdin is not a terminal, since nobody is there to read it.
+func promptf(format string, a ...interface{}) {
+	if isQuiet() && !stdinIsTerminal() {
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+// assumeYes reports whether --yes (or SCYLLA_MIGRATE_ASSUME_YES) was given,
+// answering every confirmation prompt with yes. It is deliberately not a
+// config file setting: a shared file must not silently disable prompts such
+// as clean's typed keyspace check.
+func assumeYes() bool {
+	if yes, _ := rootCmd.PersistentFlags().GetBool("yes"); yes {
+		return true
+	}
+	yes, _ := strconv.ParseBool(os.Getenv("SCYLLA_MIGRATE_ASSUME_YES"))
+	return yes
+}
+
+// readAnswer prints prompt and reads one line of input.
+func readAnswer(prompt string) (string, error) {
+	fmt.Print(prompt)
+	response, err := stdinReader.ReadString('\n')
+	if err != nil && response == "" {
+		return "", err
+	}
+	return response, nil
+}
+
+// errNoTerminal is returned when a confirmation is needed but nobody can
+// answer it.
+var errNoTerminal = fmt.Errorf("confirmation required but stdin is not a terminal — pass --yes to proceed non-interactively")
+
+// confirm asks a yes/no question, defaulting to no.
+func confirm(question string) (bool, error) {
+	if assumeYes() {
+		log.Info().Str("prompt", question).Msg("Confirmation skipped (--yes)")
+		return true, nil
+	}
+	if !stdinIsTerminal() {
+		return false, errNoTerminal
+	}
+
+	response, _ := readAnswer(question + " [y/N]: ")
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+// confirmTyped asks the operator to type expected (e.g. a keyspace name)
+// before an irreversible operation, and fails unless they do.
+func confirmTyped(prompt, expected string) error {
+	if assumeYes() {
+		log.Warn().Str("expected", expected).Msg("Confirmation skipped (--yes)")
+		return nil
+	}
+	if !stdinIsTerminal() {
+		return errNoTerminal
+	}
+
+	response, _ := readAnswer(prompt + ": ")
+	if strings.TrimSpace(response) != expected {
+		return fmt.Errorf("confirmation does not match %q — aborting", expected)
+	}
+	return nil
+}
+
+// choose asks until the answer parses to one of choices and returns it.
+// --yes does not apply: there is no answer that is safe to assume.
+func choose(prompt string, choices []string, parse func(response string) (string, bool)) (string, error) {
+	if !stdinIsTerminal() {
+		return "", errNoTerminal
+	}
+	for {
+		response, err := readAnswer(fmt.Sprintf("%s [%s]: ", prompt, strings.Join(choices, "/")))
+		if err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+		if choice, ok := parse(response); ok {
+			return choice, nil
+		}
+		fmt.Printf("Please answer %s.\n", strings.Join(choices, ", "))
+	}
+}
+
+// confirmDestructive lists the DROP/TRUNCATE statements about to run and
+// asks the operator to confirm them by typing "yes". Without a terminal the
+// run is refused; --allow-destructive (or --yes) is the non-interactive opt-in.
+func confirmDestructive(found []migration.DestructiveStatement) error {
+	if len(found) == 0 {
+		return nil
+	}
+
+	for _, d := range found {
+		log.Warn().
+			Str("version", d.Migration.Version).
+			Str("file", d.Migration.Filename).
+			Int("statement", d.Index+1).
+			Str("object", d.Object).
+			Msg("Destructive statement in pending migration")
+	}
+
+	if !assumeYes() && !stdinIsTerminal() {
+		return fmt.Errorf("pending migrations contain %d destructive statement(s) — re-run with --allow-destructive to apply them", len(found))
+	}
+
+	promptf("\nThe following objects will be dropped or truncated:\n")
+	for _, d := range found {
+		promptf("  %s (%s, statement %d)\n", d.Object, d.Migration.Filename, d.Index+1)
+	}
+	if err := confirmTyped("\nType 'yes' to continue", "yes"); err != nil {
+		return fmt.Errorf("destructive statements not confirmed — aborting")
+	}
+	return nil
+}
+
+// migrationApprover prompts before each migration for migrate --interactive.
+// Answers: y (apply), n (skip), all (apply this and the rest without asking),
+// abort (stop the run). Skipping a versioned migration also skips every later
+// versioned migration, since applying them would leave a gap behind.
+type migrationApprover struct {
+	approveAll bool
+	skipFrom   string
+}
+
+func newMigrationApprover() *migrationApprover {
+	return &migrationApprover{}
+}
+
+func (a *migrationApprover) Approve(mig *migration.Migration) (bool, error) {
+	if a.approveAll {
+		return true, nil
+	}
+
+	if mig.Type == migration.TypeVersioned && a.skipFrom != "" {
+		log.Warn().
+			Str("version", mig.Version).
+			Str("skipped_from", a.skipFrom).
+			Msg("Skipping migration because an earlier version was skipped")
+		return false, nil
+	}
+
+	label := "V" + mig.Version
+	if mig.Type == migration.TypeRepeatable {
+		label = "R"
+	}
+
+	if mig.Streamed {
+		fmt.Printf("\n%s: %s (large file, statements streamed from %s)\n", label, mig.Description, mig.FilePath)
+	} else {
+		fmt.Printf("\n%s: %s (%d statement(s))\n", label, mig.Description, len(mig.Statements))
+		for i, stmt := range mig.Statements {
+			fmt.Printf("  [%d] %s;\n", i+1, stmt)
+		}
+	}
+
+	for {
+		fmt.Print("\nApply this migration? [y/n/all/abort]: ")
+
+		response, err := stdinReader.ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("migration aborted: failed to read response: %w", err)
+		}
+
+		switch strings.TrimSpace(strings.ToLower(response)) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			if mig.Type == migration.TypeVersioned {
+				a.skipFrom = mig.Version
+			}
+			return false, nil
+		case "all", "a":
+			a.approveAll = true
+			return true, nil
+		case "abort", "q":
+			return false, fmt.Errorf("migration aborted by operator at %s", label)
+		default:
+			fmt.Println("Please answer y, n, all, or abort.")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withStdin makes the prompts read input, as if from a terminal unless
+// terminal is false.
+func withStdin(t *testing.T, input string, terminal bool) {
+	t.Helper()
+	reader, isTerminal := stdinReader, stdinIsTerminal
+	t.Cleanup(func() { stdinReader, stdinIsTerminal = reader, isTerminal })
+	stdinReader = bufio.NewReader(strings.NewReader(input))
+	stdinIsTerminal = func() bool { return terminal }
+}
+
+func withYesFlag(t *testing.T) {
+	t.Helper()
+	require.NoError(t, rootCmd.PersistentFlags().Set("yes", "true"))
+	t.Cleanup(func() { _ = rootCmd.PersistentFlags().Set("yes", "false") })
+}
+
+func TestConfirm(t *testing.T) {
+	withStdin(t, "y\n", true)
+	ok, err := confirm("Proceed?")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	withStdin(t, "\n", true)
+	ok, err = confirm("Proceed?")
+	require.NoError(t, err)
+	assert.False(t, ok, "the default answer is no")
+
+	withStdin(t, "", false)
+	_, err = confirm("Proceed?")
+	assert.ErrorIs(t, err, errNoTerminal)
+
+	withYesFlag(t)
+	ok, err = confirm("Proceed?")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestConfirmTyped(t *testing.T) {
+	withStdin(t, "app\n", true)
+	assert.NoError(t, confirmTyped("Type the keyspace", "app"))
+
+	withStdin(t, "other\n", true)
+	assert.Error(t, confirmTyped("Type the keyspace", "app"))
+
+	withStdin(t, "", false)
+	assert.ErrorIs(t, confirmTyped("Type the keyspace", "app"), errNoTerminal)
+
+	withYesFlag(t)
+	assert.NoError(t, confirmTyped("Type the keyspace", "app"))
+}
+
+func TestAssumeYes_Env(t *testing.T) {
+	assert.False(t, assumeYes())
+
+	t.Setenv("SCYLLA_MIGRATE_ASSUME_YES", "true")
+	assert.True(t, assumeYes())
+}
+
+func TestChoose(t *testing.T) {
+	parse := func(r string) (string, bool) {
+		r = strings.TrimSpace(r)
+		return r, r == "a" || r == "b"
+	}
+
+	withStdin(t, "x\nb\n", true)
+	choice, err := choose("Pick", []string{"a", "b"}, parse)
+	require.NoError(t, err)
+	assert.Equal(t, "b", choice)
+
+	// Running out of input aborts instead of looping
+	withStdin(t, "x\n", true)
+	_, err = choose("Pick", []string{"a", "b"}, parse)
+	assert.Error(t, err)
+
+	// --yes has no answer to assume
+	withYesFlag(t)
+	withStdin(t, "", false)
+	_, err = choose("Pick", []string{"a", "b"}, parse)
+	assert.ErrorIs(t, err, errNoTerminal)
+}
@@ -18,15 +18,27 @@ var createCmd = &cobra.Command{
 	Long:  "Generate migration file scaffolding with auto-incremented version number.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := loadConfig(); err != nil {
+		if err := loadOfflineConfig(); err != nil {
 			return err
 		}
 
 		name := args[0]
 		withUndo, _ := cmd.Flags().GetBool("with-undo")
 		repeatable, _ := cmd.Flags().GetBool("repeatable")
+		draft, _ := cmd.Flags().GetBool("draft")
 
-		migrationsDir := cfg.MigrationsDir
+		table, err := tableSpecFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if table != nil && repeatable {
+			return fmt.Errorf("--table cannot be used with --repeatable")
+		}
+		if draft && repeatable {
+			return fmt.Errorf("--draft cannot be used with --repeatable (repeatable migrations have no version)")
+		}
+
+		migrationsDir := cfg.PrimaryMigrationsDir()
 		if err := os.MkdirAll(migrationsDir, 0755); err != nil {
 			return fmt.Errorf("failed to create migrations directory: %w", err)
 		}
@@ -36,7 +48,12 @@ var createCmd = &cobra.Command{
 
 		var files []string
 
-		if repeatable {
+		if draft {
+			files, err = writeDraft(migrationsDir, name, sanitized, timestamp, table, withUndo)
+			if err != nil {
+				return err
+			}
+		} else if repeatable {
 			filename := fmt.Sprintf("R__%s.cql", sanitized)
 			path := filepath.Join(migrationsDir, filename)
 			content := fmt.Sprintf(`-- Repeatable Migration: %s
@@ -52,7 +69,7 @@ var createCmd = &cobra.Command{
 			}
 			files = append(files, path)
 		} else {
-			nextVersion, err := migration.GetNextVersion(migrationsDir)
+			nextVersion, err := migration.GetNextVersion(cfg.MigrationsDirs...)
 			if err != nil {
 				return fmt.Errorf("failed to determine next version: %w", err)
 			}
@@ -65,6 +82,9 @@ var createCmd = &cobra.Command{
 -- Created: %s
 
 `, name, nextVersion, timestamp)
+			if table != nil {
+				content += table.CreateCQL()
+			}
 
 			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 				return fmt.Errorf("failed to create file: %w", err)
@@ -82,6 +102,9 @@ var createCmd = &cobra.Command{
 -- This script reverses the changes made by V%03d__%s.cql
 
 `, name, nextVersion, timestamp, nextVersion, sanitized)
+				if table != nil {
+					undoContent += table.DropCQL()
+				}
 
 				if err := os.WriteFile(undoPath, []byte(undoContent), 0644); err != nil {
 					return fmt.Errorf("failed to create undo file: %w", err)
@@ -98,6 +121,42 @@ var createCmd = &cobra.Command{
 	},
 }
 
+// tableSpecFromFlags builds the table to scaffold from --table, --pk and
+// --col, or returns nil when --table is not set.
+func tableSpecFromFlags(cmd *cobra.Command) (*migration.TableSpec, error) {
+	name, _ := cmd.Flags().GetString("table")
+	pks, _ := cmd.Flags().GetStringArray("pk")
+	cols, _ := cmd.Flags().GetStringArray("col")
+
+	if name == "" {
+		if len(pks) > 0 || len(cols) > 0 {
+			return nil, fmt.Errorf("--pk and --col require --table")
+		}
+		return nil, nil
+	}
+
+	spec := &migration.TableSpec{Name: name}
+	for _, p := range pks {
+		col, err := migration.ParseColumn(p)
+		if err != nil {
+			return nil, err
+		}
+		spec.PrimaryKey = append(spec.PrimaryKey, col)
+	}
+	for _, c := range cols {
+		col, err := migration.ParseColumn(c)
+		if err != nil {
+			return nil, err
+		}
+		spec.Columns = append(spec.Columns, col)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
 func sanitizeName(name string) string {
 	s := strings.ToLower(name)
 	s = strings.ReplaceAll(s, " ", "_")
@@ -116,4 +175,8 @@ func init() {
 	rootCmd.AddCommand(createCmd)
 	createCmd.Flags().Bool("with-undo", false, "also create an undo migration file")
 	createCmd.Flags().Bool("repeatable", false, "create a repeatable migration (no version number)")
+	createCmd.Flags().Bool("draft", false, "create the migration in drafts/ without a version; see 'promote'")
+	createCmd.Flags().String("table", "", "scaffold a CREATE TABLE statement for this table (and DROP TABLE in the undo)")
+	createCmd.Flags().StringArray("pk", nil, "primary key column as name:type (repeatable; the first is the partition key)")
+	createCmd.Flags().StringArray("col", nil, "regular column as name:type (repeatable)")
 }
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show recent migration events",
+	Long: `Display migration events (started, applied, failed, rolled back) recorded by
+any scylla-migrate process against this cluster. With --follow, new events are
+streamed as they happen, e.g. to watch a migration job running elsewhere.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		follow, _ := cmd.Flags().GetBool("follow")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		if outputFile, _ := cmd.Flags().GetString("output-file"); follow && outputFile != "" {
+			return fmt.Errorf("--output-file cannot be used with --follow")
+		}
+
+		out, err := openReport(cmd)
+		if err != nil {
+			return err
+		}
+		defer out.Discard()
+
+		since, err := parseSince(sinceFlag)
+		if err != nil {
+			return err
+		}
+
+		ctx, err := newReadOnlyContext()
+		if err != nil {
+			return err
+		}
+		defer ctx.Close()
+
+		lastSeen := gocql.MinTimeUUID(since)
+		printEvents := func() error {
+			polled := time.Now()
+			events, err := ctx.MetadataManager.GetEventsAfter(lastSeen)
+			if err != nil {
+				return err
+			}
+			for _, e := range events {
+				printEvent(out, e)
+				lastSeen = e.ID
+			}
+			// Move the start of the next poll up to the time just read, less
+			// a margin for events becoming visible late, so a quiet log does
+			// not keep old day buckets in every poll
+			if horizon := polled.Add(-eventsPollMargin); lastSeen.Time().Before(horizon) {
+				lastSeen = gocql.MinTimeUUID(horizon)
+			}
+			return nil
+		}
+
+		if err := printEvents(); err != nil {
+			return err
+		}
+		if !follow {
+			return out.Commit()
+		}
+
+		sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-sigCtx.Done():
+				return nil
+			case <-ticker.C:
+				if err := printEvents(); err != nil {
+					// Keep following through transient read failures
+					log.Warn().Err(err).Msg("Failed to poll events, retrying")
+				}
+			}
+		}
+	},
+}
+
+// eventsPollMargin is how far behind the previous poll events --follow
+// keeps looking, for events written with a slightly earlier timestamp.
+const eventsPollMargin = time.Minute
+
+// parseSince accepts either a duration relative to now ("1h") or an
+// absolute RFC3339 timestamp.
+func parseSince(value string) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration (e.g. 1h) or an RFC3339 timestamp", value)
+}
+
+func printEvent(w io.Writer, e schema.Event) {
+	fmt.Fprintf(w, "%s  %-18s %-8s %-30s %s", formatTime(e.Time()),
+		e.Type, e.Version, e.Description, e.Actor)
+	if e.Message != "" {
+		fmt.Fprintf(w, "  %s", e.Message)
+	}
+	fmt.Fprintln(w)
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.Flags().Bool("follow", false, "keep polling and print new events as they are recorded")
+	eventsCmd.Flags().String("since", "1h", "show events since a duration ago (e.g. 30m) or an RFC3339 time")
+	eventsCmd.Flags().Duration("interval", 2*time.Second, "polling interval for --follow")
+	addOutputFileFlag(eventsCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <file.cql>",
+	Short: "Run a CQL script under the migration lock",
+	Long: `Run a one-off script, such as a data fix, while holding the migration lock so
+no migration runs at the same time. Statements are split and executed like a
+migration's, with schema agreement waits after DDL, but nothing is recorded in
+schema_migrations: the script can be run again and never shows up as applied.
+Each run is noted in the event log (script_executed or script_failed).
+
+Like migrate, it refuses to start on a cluster in schema disagreement (unless
+--force) and waits for readiness_query before taking the lock.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		script, err := migration.LoadScript(args[0], migration.ParseOptionsFor(cfg))
+		if err != nil {
+			return err
+		}
+
+		ctx, err := migration.NewExecutionContext(cfg, log)
+		if err != nil {
+			return err
+		}
+		defer ctx.Close()
+		ctx.DryRun = dryRun
+
+		if !dryRun {
+			if err := migration.CheckSchemaAgreement(ctx, force); err != nil {
+				return err
+			}
+			if err := migration.WaitForReadiness(ctx); err != nil {
+				return err
+			}
+			log.Info().Msg("Acquiring migration lock...")
+			if err := ctx.LockManager.Acquire(cfg.LockTimeout); err != nil {
+				return fmt.Errorf("failed to acquire lock: %w", err)
+			}
+			defer func() {
+				if err := ctx.LockManager.Release(); err != nil {
+					log.Error().Err(err).Msg("Failed to release lock")
+				}
+			}()
+		}
+
+		return migration.NewExecutor(ctx).ExecuteScript(script)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().Bool("dry-run", false, "print the statements without executing them")
+	execCmd.Flags().Bool("force", false, "start even if the cluster is already in schema disagreement")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the migration ordering graph",
+	Long: `Print the ordering graph of the versioned migrations: one node per version,
+a dashed edge between consecutive steps of the forward plan, in the order
+migrate would run them against an empty keyspace, and a solid edge for every
+depends-on directive. Reads only the migrations directories; no cluster
+connection is made.
+
+Render DOT output with Graphviz, e.g. 'scylla-migrate graph | dot -Tsvg > graph.svg',
+or embed --format mermaid in Markdown. Dependency cycles and unknown
+dependencies are drawn rather than rejected, and reported as warnings.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadOfflineConfig(); err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		var render func(*migration.Graph, io.Writer) error
+		switch format {
+		case "dot":
+			render = (*migration.Graph).WriteDOT
+		case "mermaid":
+			render = (*migration.Graph).WriteMermaid
+		default:
+			return fmt.Errorf("unsupported format %q (use dot or mermaid)", format)
+		}
+
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+		if err != nil {
+			return err
+		}
+		for _, mig := range scanned {
+			if err := migration.ParseMigrationFile(mig); err != nil {
+				return err
+			}
+		}
+
+		graph := migration.NewResolverFor(cfg, scanned).Graph()
+
+		out, err := openReport(cmd)
+		if err != nil {
+			return err
+		}
+		defer out.Discard()
+		if err := render(graph, out); err != nil {
+			return err
+		}
+		if err := out.Commit(); err != nil {
+			return err
+		}
+
+		if graph.Err != nil {
+			log.Warn().Err(graph.Err).Msg("Migrations cannot be ordered")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().String("format", "dot", "graph format (dot, mermaid)")
+	_ = graphCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"dot", "mermaid"}, cobra.ShellCompDirectiveNoFileComp))
+	addOutputFileFlag(graphCmd)
+}
@@ -2,10 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
 )
 
 var infoCmd = &cobra.Command{
@@ -17,7 +18,7 @@ var infoCmd = &cobra.Command{
 			return err
 		}
 
-		ctx, err := migration.NewExecutionContext(cfg, log)
+		ctx, err := newReadOnlyContext()
 		if err != nil {
 			return err
 		}
@@ -35,8 +36,16 @@ var infoCmd = &cobra.Command{
 		if lastVersion == "" {
 			lastVersion = "none"
 		}
+		lastAppliedAt := "-"
+		if applied, err := ctx.MetadataManager.GetAppliedMigrations(); err == nil {
+			for _, a := range applied {
+				if a.Version == lastVersion {
+					lastAppliedAt = formatTime(a.AppliedAt)
+				}
+			}
+		}
 
-		fmt.Printf("scylla-migrate %s\n\n", version)
+		printInfo("scylla-migrate %s\n\n", version)
 
 		fmt.Println("Cluster:")
 		if metadata != nil {
@@ -47,9 +56,13 @@ var infoCmd = &cobra.Command{
 		fmt.Printf("  Keyspace:       %s\n", cfg.Keyspace)
 
 		fmt.Println("\nMigration:")
-		fmt.Printf("  Directory:      %s\n", cfg.MigrationsDir)
+		fmt.Printf("  Directory:      %s\n", strings.Join(cfg.MigrationsDirs, ", "))
 		fmt.Printf("  Metadata:       %s\n", cfg.MetadataKeyspace)
+		if v, err := schema.ReadMetadataSchemaVersion(ctx.Session, cfg.MetadataKeyspace); err == nil {
+			fmt.Printf("  Meta Schema:    v%d (this build: v%d)\n", v, schema.MetadataSchemaVersion)
+		}
 		fmt.Printf("  Current:        V%s\n", lastVersion)
+		fmt.Printf("  Applied At:     %s\n", lastAppliedAt)
 
 		fmt.Println("\nSettings:")
 		fmt.Printf("  Consistency:    %s\n", cfg.Consistency)
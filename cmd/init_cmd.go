@@ -13,7 +13,9 @@ var initCmd = &cobra.Command{
 	Short: "Initialize scylla-migrate project",
 	Long:  "Create a configuration file and migrations directory to get started.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		initLogger()
+		if err := initLogger(); err != nil {
+			return err
+		}
 
 		migrationsDir := "./migrations"
 
@@ -45,11 +47,11 @@ var initCmd = &cobra.Command{
 			log.Info().Str("path", examplePath).Msg("Created example migration")
 		}
 
-		fmt.Println("\nInitialization complete! Next steps:")
-		fmt.Println("  1. Edit scylla-migrate.yaml with your cluster settings")
-		fmt.Println("  2. Edit or replace migrations/V001__example_migration.cql")
-		fmt.Println("  3. Create more migrations: scylla-migrate create <name>")
-		fmt.Println("  4. Apply migrations:       scylla-migrate migrate")
+		printInfo("\nInitialization complete! Next steps:\n")
+		printInfo("  1. Edit scylla-migrate.yaml with your cluster settings\n")
+		printInfo("  2. Edit or replace migrations/V001__example_migration.cql\n")
+		printInfo("  3. Create more migrations: scylla-migrate create <name>\n")
+		printInfo("  4. Apply migrations:       scylla-migrate migrate\n")
 
 		return nil
 	},
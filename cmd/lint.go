@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/lint"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check migration files for common mistakes",
+	Long: `Run offline checks over the migration files. No cluster connection is made.
+
+Without check flags, all checks are run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadOfflineConfig(); err != nil {
+			return err
+		}
+
+		checkNames, _ := cmd.Flags().GetBool("names")
+		checkReserved, _ := cmd.Flags().GetBool("reserved-keywords")
+		checkIdempotency, _ := cmd.Flags().GetBool("repeatable-idempotency")
+		checkKeyspaces, _ := cmd.Flags().GetBool("keyspaces")
+		checkGaps, _ := cmd.Flags().GetBool("gaps")
+		checkDuplicates, _ := cmd.Flags().GetBool("duplicates")
+		strict, _ := cmd.Flags().GetBool("strict")
+		all := !checkNames && !checkReserved && !checkIdempotency && !checkKeyspaces && !checkGaps && !checkDuplicates
+
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+		if err != nil {
+			return err
+		}
+		for _, mig := range scanned {
+			if err := migration.ParseMigrationFile(mig); err != nil {
+				return err
+			}
+		}
+
+		var issues []lint.Issue
+
+		if all || checkNames {
+			found, err := lint.CheckNames(scanned, cfg.Lint.Names)
+			if err != nil {
+				return err
+			}
+			issues = append(issues, found...)
+		}
+
+		if all || checkReserved {
+			issues = append(issues, lint.CheckReservedKeywords(scanned)...)
+		}
+
+		if all || checkIdempotency {
+			rules := cfg.Lint.RepeatableIdempotency.Rules
+			if len(rules) == 0 {
+				rules = nil
+			}
+			found, err := lint.CheckRepeatableIdempotency(scanned, rules)
+			if err != nil {
+				return err
+			}
+			issues = append(issues, found...)
+		}
+
+		if all || checkKeyspaces {
+			allowed := append([]string{cfg.Keyspace, cfg.MetadataKeyspace}, cfg.Lint.Keyspaces.Allowed...)
+			issues = append(issues, lint.CheckKeyspaces(scanned, allowed)...)
+		}
+
+		if all || checkGaps {
+			issues = append(issues, lint.CheckGaps(scanned, strict)...)
+		}
+
+		if all || checkDuplicates {
+			issues = append(issues, lint.CheckDuplicates(scanned)...)
+		}
+
+		lint.Sort(issues)
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+
+		errCount := lint.CountErrors(issues)
+		if errCount > 0 {
+			return fmt.Errorf("lint found %d error(s) and %d warning(s)", errCount, len(issues)-errCount)
+		}
+
+		log.Info().Int("files", len(scanned)).Int("warnings", len(issues)).Msg("Lint complete")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().Bool("names", false, "check migration descriptions against lint.names rules")
+	lintCmd.Flags().Bool("reserved-keywords", false, "warn about unquoted identifiers that are reserved CQL keywords")
+	lintCmd.Flags().Bool("repeatable-idempotency", false, "warn about repeatable migration statements that are unsafe to re-run")
+	lintCmd.Flags().Bool("gaps", false, "warn about gaps in the versioned sequence (V001, V003 without V002)")
+	lintCmd.Flags().Bool("strict", false, "report version gaps as errors instead of warnings")
+	lintCmd.Flags().Bool("duplicates", false, "warn about statements repeated within a migration")
+	lintCmd.Flags().Bool("keyspaces", false, "warn about statements referencing a keyspace other than the configured ones")
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/lock"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// stressLockID keeps lock stress off the real migration lock row.
+const stressLockID = "stress_lock"
+
+var lockCmd = &cobra.Command{
+	Use:    "lock",
+	Short:  "Diagnose the migration lock",
+	Hidden: true,
+}
+
+var lockStressCmd = &cobra.Command{
+	Use:   "stress",
+	Short: "Run concurrent lock attempts against the cluster",
+	Long: `Start N runners that each acquire the lock, hold it briefly and release it,
+using the configured lock_strategy against the real cluster. The lock row used
+is separate from the migration lock, so running migrations are not blocked.
+
+The run passes when every runner got the lock and no two ever held it at the
+same time. A failure points at missing LWT support or a misconfigured serial
+consistency before it affects a real migration.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		runners, _ := cmd.Flags().GetInt("runners")
+		hold, _ := cmd.Flags().GetDuration("hold")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if runners < 2 {
+			return fmt.Errorf("--runners must be at least 2")
+		}
+
+		ctx, err := migration.NewExecutionContext(cfg, log)
+		if err != nil {
+			return err
+		}
+		defer ctx.Close()
+
+		var (
+			active, maxActive atomic.Int32
+			acquired          atomic.Int32
+			mu                sync.Mutex
+			waits             []time.Duration
+			failures          []string
+			wg                sync.WaitGroup
+		)
+
+		start := time.Now()
+		for i := 0; i < runners; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				lm := lock.NewLockManager(ctx.Session, cfg.MetadataKeyspace, "", lock.Strategy(cfg.LockStrategy), log)
+				lm.SetLockID(stressLockID)
+
+				begin := time.Now()
+				if err := lm.Acquire(timeout); err != nil {
+					mu.Lock()
+					failures = append(failures, fmt.Sprintf("runner %d: %v", i+1, err))
+					mu.Unlock()
+					return
+				}
+				wait := time.Since(begin)
+
+				now := active.Add(1)
+				for {
+					prev := maxActive.Load()
+					if now <= prev || maxActive.CompareAndSwap(prev, now) {
+						break
+					}
+				}
+				acquired.Add(1)
+				time.Sleep(hold)
+				active.Add(-1)
+
+				if err := lm.Release(); err != nil {
+					mu.Lock()
+					failures = append(failures, fmt.Sprintf("runner %d: release: %v", i+1, err))
+					mu.Unlock()
+				}
+
+				mu.Lock()
+				waits = append(waits, wait)
+				mu.Unlock()
+			}(i)
+		}
+		wg.Wait()
+		elapsed := time.Since(start)
+
+		var total, longest time.Duration
+		for _, w := range waits {
+			total += w
+			if w > longest {
+				longest = w
+			}
+		}
+		var avg time.Duration
+		if len(waits) > 0 {
+			avg = total / time.Duration(len(waits))
+		}
+
+		fmt.Printf("Strategy:            %s\n", cfg.LockStrategy)
+		fmt.Printf("Runners:             %d\n", runners)
+		fmt.Printf("Acquired:            %d\n", acquired.Load())
+		fmt.Printf("Max simultaneous:    %d\n", maxActive.Load())
+		fmt.Printf("Avg wait to acquire: %s\n", avg.Round(time.Millisecond))
+		fmt.Printf("Max wait to acquire: %s\n", longest.Round(time.Millisecond))
+		fmt.Printf("Total time:          %s\n", elapsed.Round(time.Millisecond))
+		for _, f := range failures {
+			fmt.Printf("  %s\n", f)
+		}
+
+		if maxActive.Load() > 1 {
+			fmt.Println("FAIL: the lock was held by more than one runner at a time")
+			return fmt.Errorf("lock stress failed: %d runners held the lock simultaneously", maxActive.Load())
+		}
+		if len(failures) > 0 {
+			fmt.Println("FAIL: not every runner acquired and released the lock")
+			return fmt.Errorf("lock stress failed: %d error(s)", len(failures))
+		}
+		fmt.Println("PASS")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	lockCmd.AddCommand(lockStressCmd)
+	lockStressCmd.Flags().Int("runners", 5, "number of concurrent runners")
+	lockStressCmd.Flags().Duration("hold", 200*time.Millisecond, "how long each runner holds the lock")
+	lockStressCmd.Flags().Duration("timeout", 2*time.Minute, "how long each runner waits for the lock")
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Log formats accepted by --log-format.
+const (
+	logFormatConsole = "console"
+	logFormatJSON    = "json"
+	logFormatJSONL   = "jsonl"
+)
+
+// newLogger builds the CLI logger. console is for humans, json is plain
+// zerolog output, and jsonl is the stable schema written by jsonlWriter.
+func newLogger(format string, out io.Writer, level zerolog.Level) (zerolog.Logger, error) {
+	var w io.Writer
+	switch format {
+	case logFormatConsole, "":
+		w = zerolog.ConsoleWriter{Out: out, TimeFormat: "15:04:05"}
+	case logFormatJSON:
+		w = out
+	case logFormatJSONL:
+		w = &jsonlWriter{out: out}
+	default:
+		return zerolog.Nop(), fmt.Errorf("invalid log format %q (use %s, %s or %s)", format, logFormatConsole, logFormatJSON, logFormatJSONL)
+	}
+	return zerolog.New(w).Level(level).With().Timestamp().Logger(), nil
+}
+
+// jsonlEvent is the --log-format jsonl schema. Its top-level fields are a
+// contract with log pipelines: add new context under Fields, never here.
+type jsonlEvent struct {
+	TS        string                 `json:"ts"`
+	Level     string                 `json:"level"`
+	Msg       string                 `json:"msg"`
+	Version   string                 `json:"version,omitempty"`
+	Statement json.Number            `json:"statement,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// jsonlWriter rewrites zerolog events into jsonlEvent, one compact object
+// per line, so the output does not depend on zerolog's field names.
+type jsonlWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *jsonlWriter) Write(p []byte) (int, error) {
+	var raw map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		// not an event we understand; pass it through untouched
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.out.Write(p)
+	}
+
+	ev := jsonlEvent{
+		TS:    take(raw, zerolog.TimestampFieldName),
+		Level: take(raw, zerolog.LevelFieldName),
+		Msg:   take(raw, zerolog.MessageFieldName),
+	}
+	if v, ok := raw["version"]; ok {
+		ev.Version = fmt.Sprint(v)
+		delete(raw, "version")
+	}
+	if n, ok := raw["statement"].(json.Number); ok {
+		ev.Statement = n
+		delete(raw, "statement")
+	}
+	if len(raw) > 0 {
+		ev.Fields = raw
+	}
+
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// take removes key from raw and returns it as a string.
+func take(raw map[string]interface{}, key string) string {
+	v, ok := raw[key]
+	if !ok {
+		return ""
+	}
+	delete(raw, key)
+	return fmt.Sprint(v)
+}
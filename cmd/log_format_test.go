@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+// TestJSONLLog_Golden pins the --log-format jsonl field set. Log pipelines
+// parse these fields; if this fails, the change breaks them.
+func TestJSONLLog_Golden(t *testing.T) {
+	defer func(f func() time.Time) { zerolog.TimestampFunc = f }(zerolog.TimestampFunc)
+	zerolog.TimestampFunc = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	var buf bytes.Buffer
+	l, err := newLogger(logFormatJSONL, &buf, zerolog.DebugLevel)
+	require.NoError(t, err)
+
+	l.Info().Msg("Connected to cluster")
+	l.Info().Str("version", "003").Str("description", "add users").Msg("Migration applied successfully")
+	l.Debug().Int("statement", 2).Int("total", 5).Msg("Executing statement")
+	l.Error().Str("version", "004").Int("statement", 1).Err(errors.New("syntax error")).Msg("Migration failed")
+
+	golden := filepath.Join("testdata", "log_jsonl.golden")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0644))
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestNewLogger_RejectsUnknownFormat(t *testing.T) {
+	_, err := newLogger("yaml", &bytes.Buffer{}, zerolog.InfoLevel)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"yaml"`)
+
+	_, err = newLogger("", &bytes.Buffer{}, zerolog.InfoLevel)
+	assert.NoError(t, err, "unset means console")
+}
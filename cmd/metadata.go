@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+var metadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "Inspect and manage migration metadata",
+}
+
+var metadataExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the schema_migrations table",
+	Long: `Export all rows of schema_migrations as JSON or CSV.
+
+The CSV format has a header row and the table's column order, so it can be
+restored without scylla-migrate:
+
+  COPY <metadata_keyspace>.schema_migrations (version, description, type, script,
+    checksum, applied_by, applied_at, execution_time_ms, success)
+    FROM 'migrations.csv' WITH HEADER = true;
+
+With --as-script, writes the applied versioned migrations instead, in version
+order, as one runnable CQL file that recreates the schema as applied. Each
+migration is taken from its file, or from the content stored with
+store_script_content when the file is missing or was edited since.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output-file")
+		compress, _ := cmd.Flags().GetBool("gzip")
+		scriptPath, _ := cmd.Flags().GetString("as-script")
+
+		if scriptPath != "" {
+			if output != "" || compress || cmd.Flags().Changed("format") {
+				return fmt.Errorf("--as-script cannot be combined with --format, --output-file or --gzip")
+			}
+			return exportReplayScript(scriptPath)
+		}
+
+		var export func(io.Writer, []schema.AppliedMigration) error
+		switch format {
+		case "json":
+			export = schema.ExportJSON
+		case "csv":
+			export = schema.ExportCSV
+		default:
+			return fmt.Errorf("unsupported format %q (use json or csv)", format)
+		}
+
+		ctx, err := newReadOnlyContext()
+		if err != nil {
+			return err
+		}
+		defer ctx.Close()
+
+		applied, err := ctx.MetadataManager.GetAppliedMigrations()
+		if err != nil {
+			return fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+
+		out, err := openReport(cmd)
+		if err != nil {
+			return err
+		}
+		defer out.Discard()
+
+		if compress {
+			gz := gzip.NewWriter(out)
+			if err := export(gz, applied); err != nil {
+				return err
+			}
+			if err := gz.Close(); err != nil {
+				return fmt.Errorf("failed to finish gzip stream: %w", err)
+			}
+		} else if err := export(out, applied); err != nil {
+			return err
+		}
+		if err := out.Commit(); err != nil {
+			return err
+		}
+
+		log.Info().Int("rows", len(applied)).Str("format", format).Msg("Metadata exported")
+		return nil
+	},
+}
+
+// exportReplayScript implements metadata export --as-script.
+func exportReplayScript(path string) error {
+	ctx, err := newReadOnlyContext()
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	applied, err := ctx.MetadataManager.GetAppliedMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	stored, err := ctx.MetadataManager.GetStoredScripts()
+	if err != nil {
+		return err
+	}
+	scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+	if err != nil {
+		return err
+	}
+
+	out, err := openReportFile(path)
+	if err != nil {
+		return err
+	}
+	defer out.Discard()
+
+	if err := migration.WriteReplayScript(out, applied, scanned, stored, migration.ParseOptionsFor(cfg)); err != nil {
+		return err
+	}
+	return out.Commit()
+}
+
+var metadataBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Snapshot schema_migrations and schema_lock to a JSON file",
+	Long: `Write the metadata tables to a timestamped JSON file and print its path.
+
+clean and repair take this backup automatically before changing anything,
+unless --no-backup is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		dir, _ := cmd.Flags().GetString("dir")
+
+		session, err := driver.NewSession(cfg.ForReadOnly(), log)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+
+		path, err := backupMetadata(session, dir)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			return fmt.Errorf("metadata keyspace %s does not exist — nothing to back up", cfg.MetadataKeyspace)
+		}
+
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var metadataCheckReplicationCmd = &cobra.Command{
+	Use:   "check-replication",
+	Short: "Compare the metadata keyspace replication with the config",
+	Long: `Read the metadata keyspace's replication from system_schema and compare it
+with metadata_replication. CREATE KEYSPACE IF NOT EXISTS never changes an
+existing keyspace, so editing metadata_replication after the first run has no
+effect until the keyspace is altered.
+
+With --fix-replication, the keyspace is altered to match the config. Run a
+full repair of the keyspace afterwards when increasing the replication factor.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		fix, _ := cmd.Flags().GetBool("fix-replication")
+
+		// Only --fix-replication writes; a plain check reads like status
+		sessionCfg := cfg.ForReadOnly()
+		if fix {
+			sessionCfg = cfg
+		}
+		session, err := driver.NewSession(sessionCfg, log)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+
+		exists, err := session.KeyspaceExists(cfg.MetadataKeyspace)
+		if err != nil {
+			return fmt.Errorf("failed to check metadata keyspace: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("metadata keyspace %s does not exist", cfg.MetadataKeyspace)
+		}
+
+		drift, err := schema.CheckMetadataReplication(session, cfg, log, fix)
+		if err != nil {
+			return err
+		}
+		if len(drift) == 0 {
+			log.Info().Str("keyspace", cfg.MetadataKeyspace).Msg("Metadata keyspace replication matches config")
+			return nil
+		}
+
+		for _, d := range drift {
+			fmt.Println(d)
+		}
+		if !fix {
+			return fmt.Errorf("metadata keyspace replication differs from config (use --fix-replication to alter it)")
+		}
+		return nil
+	},
+}
+
+// backupMetadata writes a snapshot of the metadata tables into dir and
+// returns the file path. It returns an empty path when the metadata keyspace
+// does not exist yet.
+func backupMetadata(session *driver.Session, dir string) (string, error) {
+	exists, err := session.KeyspaceExists(cfg.MetadataKeyspace)
+	if err != nil {
+		return "", fmt.Errorf("failed to check metadata keyspace: %w", err)
+	}
+	if !exists {
+		return "", nil
+	}
+
+	mm := schema.NewMetadataManager(session, cfg.MetadataKeyspace, log)
+	applied, err := mm.GetAppliedMigrations()
+	if err != nil {
+		return "", fmt.Errorf("failed to read schema_migrations for backup: %w", err)
+	}
+	locks, err := mm.GetLockRows()
+	if err != nil {
+		return "", fmt.Errorf("failed to read schema_lock for backup: %w", err)
+	}
+
+	backup := schema.NewBackup(cfg.MetadataKeyspace, applied, locks)
+	name := fmt.Sprintf("%s-backup-%s.json", cfg.MetadataKeyspace, backup.CreatedAt.Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+
+	// O_EXCL: never overwrite an earlier snapshot taken in the same second
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer f.Close()
+
+	if err := backup.WriteJSON(f); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	log.Info().Str("path", path).Int("migrations", len(applied)).Msg("Metadata backed up")
+	return path, nil
+}
+
+// backupBeforeMutation runs the automatic backup for destructive commands.
+// A failed backup aborts the command: it is the operator's safety net.
+func backupBeforeMutation(cmd *cobra.Command, session *driver.Session) error {
+	noBackup, _ := cmd.Flags().GetBool("no-backup")
+	return backupUnless(noBackup, session)
+}
+
+// backupUnless is backupBeforeMutation for callers that read --no-backup
+// themselves.
+func backupUnless(noBackup bool, session *driver.Session) error {
+	if noBackup {
+		log.Warn().Msg("Skipping metadata backup (--no-backup)")
+		return nil
+	}
+
+	path, err := backupMetadata(session, ".")
+	if err != nil {
+		return fmt.Errorf("%w (use --no-backup to skip)", err)
+	}
+	if path != "" {
+		printInfo("Metadata backup written to %s\n", path)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(metadataCmd)
+	metadataCmd.AddCommand(metadataExportCmd)
+	metadataCmd.AddCommand(metadataBackupCmd)
+	metadataCmd.AddCommand(metadataCheckReplicationCmd)
+	metadataCheckReplicationCmd.Flags().Bool("fix-replication", false, "ALTER the metadata keyspace to match metadata_replication")
+	metadataBackupCmd.Flags().String("dir", ".", "directory to write the backup file to")
+	metadataExportCmd.Flags().String("format", "json", "export format (json, csv)")
+	_ = metadataExportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"json", "csv"}, cobra.ShellCompDirectiveNoFileComp))
+	addOutputFileFlag(metadataExportCmd)
+	metadataExportCmd.Flags().Bool("gzip", false, "gzip-compress the output")
+	metadataExportCmd.Flags().String("as-script", "", "write the applied versioned migrations as one replayable .cql script to this path")
+	_ = metadataExportCmd.MarkFlagFilename("as-script", "cql")
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+var metadataPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Fold old successful migration records into a baseline",
+	Long: `Remove old successful versioned records from schema_migrations so it stops
+growing without bound.
+
+The removed records are replaced by one baseline record at the newest pruned
+version, which counts that version and every older one as applied. Pruning
+stops before any migration file without a successful record, so nothing
+pending is ever hidden. Failed and repeatable records are kept.
+
+--dry-run only previews. Otherwise the plan is shown and must be confirmed
+(or --yes given), and a metadata backup is written first unless --no-backup.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		var opts migration.PruneOptions
+		opts.KeepLast, _ = cmd.Flags().GetInt("keep-last")
+		olderThan, _ := cmd.Flags().GetDuration("older-than")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if olderThan < 0 {
+			return fmt.Errorf("--older-than must be positive")
+		}
+		if olderThan > 0 {
+			opts.Before = time.Now().Add(-olderThan)
+		}
+
+		ctx, err := migration.NewExecutionContext(cfg, log)
+		if err != nil {
+			return err
+		}
+		defer ctx.Close()
+
+		// Read the records under the lock, so a concurrent migrate cannot
+		// add or fail one between planning and pruning
+		if !dryRun {
+			log.Info().Msg("Acquiring migration lock...")
+			if err := ctx.LockManager.Acquire(cfg.LockTimeout); err != nil {
+				return fmt.Errorf("failed to acquire lock: %w", err)
+			}
+			defer func() {
+				if err := ctx.LockManager.Release(); err != nil {
+					log.Error().Err(err).Msg("Failed to release lock")
+				}
+			}()
+		}
+
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+		if err != nil {
+			return err
+		}
+		applied, err := ctx.MetadataManager.GetAppliedMigrations()
+		if err != nil {
+			return fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+
+		plan, err := migration.PlanPrune(applied, scanned, opts)
+		if err != nil {
+			return err
+		}
+		if plan.StoppedAt != "" {
+			log.Warn().Msg("Pruning stopped early: " + plan.StoppedAt)
+		}
+		if plan.Baseline == "" {
+			log.Info().Msg("Nothing to prune")
+			return nil
+		}
+
+		printPrunePlan(plan)
+		if dryRun {
+			log.Info().Msg("Dry run: no records removed")
+			return nil
+		}
+
+		ok, err := confirm(fmt.Sprintf("Fold %d record(s) into a baseline at V%s?", plan.Folded(), plan.Baseline))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("prune cancelled")
+		}
+
+		if err := backupBeforeMutation(cmd, ctx.Session); err != nil {
+			return err
+		}
+
+		// The baseline goes in first, so an interrupted prune never leaves
+		// a removed record uncovered
+		description := fmt.Sprintf("baseline of %d pruned record(s)", plan.Folded())
+		if err := ctx.MetadataManager.RecordBaseline(plan.Baseline, description, ctx.Operator()); err != nil {
+			return err
+		}
+		for _, a := range plan.Remove {
+			if err := ctx.MetadataManager.RemoveMigration(a.Version); err != nil {
+				return fmt.Errorf("failed to remove record %s: %w", a.Version, err)
+			}
+		}
+
+		ctx.RecordEvent(schema.EventMetadataPruned, plan.Baseline, description, "")
+		log.Info().Str("baseline", plan.Baseline).Int("removed", len(plan.Remove)).Msg("Metadata pruned")
+		return nil
+	},
+}
+
+func printPrunePlan(plan *migration.PrunePlan) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tDESCRIPTION\tAPPLIED AT\tACTION")
+	for _, a := range plan.Remove {
+		fmt.Fprintf(w, "%s\t%s\t%s\tremove\n", a.Version, a.Description, formatTime(a.AppliedAt))
+	}
+	fmt.Fprintf(w, "%s\t\t\treplace with baseline\n", plan.Baseline)
+	w.Flush()
+	fmt.Printf("\n%d record(s) folded into a baseline at V%s\n\n", plan.Folded(), plan.Baseline)
+}
+
+func init() {
+	metadataCmd.AddCommand(metadataPruneCmd)
+	metadataPruneCmd.Flags().Int("keep-last", 0, "keep the newest N successful versioned records")
+	metadataPruneCmd.Flags().Duration("older-than", 0, "only prune records applied longer ago than this (e.g. 8760h)")
+	metadataPruneCmd.Flags().Bool("dry-run", false, "show what would be pruned without changing anything")
+	metadataPruneCmd.Flags().Bool("no-backup", false, "skip the automatic metadata backup")
+}
@@ -1,13 +1,46 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
 	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/notify"
+	"github.com/scylla-migrate/scylla-migrate/internal/report"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
 )
 
+type migrateOptions struct {
+	dryRun             bool
+	target             string
+	from               string
+	to                 string
+	includeRepeatables bool
+	promptEach         bool
+	allowDestructive   bool
+	output             string
+	// planOut receives the plan when output is json
+	planOut    io.Writer
+	reportPath string
+	// metricsPath receives Prometheus metrics after the run
+	metricsPath string
+	// force starts even though the cluster is in schema disagreement
+	force bool
+	// repairChecksums accepts changed applied migrations by updating their
+	// recorded checksums before resolving
+	repairChecksums bool
+	// noBackup skips the metadata backup taken before repairChecksums
+	noBackup bool
+}
+
 var migrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Apply pending migrations",
@@ -17,98 +50,543 @@ var migrateCmd = &cobra.Command{
 			return err
 		}
 
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		target, _ := cmd.Flags().GetString("target")
+		var opts migrateOptions
+		opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
+		opts.target, _ = cmd.Flags().GetString("target")
+		opts.from, _ = cmd.Flags().GetString("from")
+		opts.to, _ = cmd.Flags().GetString("to")
+		opts.includeRepeatables, _ = cmd.Flags().GetBool("include-repeatables")
+		opts.allowDestructive, _ = cmd.Flags().GetBool("allow-destructive")
+		opts.output, _ = cmd.Flags().GetString("output")
+		opts.reportPath, _ = cmd.Flags().GetString("report")
+		opts.force, _ = cmd.Flags().GetBool("force")
+		opts.metricsPath, _ = cmd.Flags().GetString("metrics-file")
+		opts.repairChecksums, _ = cmd.Flags().GetBool("repair-checksums")
+		opts.noBackup, _ = cmd.Flags().GetBool("no-backup")
+		verifyLock, _ := cmd.Flags().GetBool("verify-lock")
+		updateLock, _ := cmd.Flags().GetBool("update-lock")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		allKeyspaces, _ := cmd.Flags().GetString("all-keyspaces")
 
-		ctx, err := migration.NewExecutionContext(cfg, log)
-		if err != nil {
-			return err
+		if dev, _ := cmd.Flags().GetBool("dev"); dev {
+			cfg.AllowLocalModifications = true
+			if err := cfg.Validate(); err != nil {
+				return fmt.Errorf("--dev: %w", err)
+			}
+		}
+		if cfg.AllowLocalModifications {
+			log.Warn().Msg("DEVELOPMENT MODE: checksum mismatches of applied migrations are reported as warnings, not errors")
 		}
-		defer ctx.Close()
 
-		ctx.DryRun = dryRun
+		if opts.target != "" && opts.to != "" {
+			return fmt.Errorf("--target and --to cannot be used together")
+		}
+		switch opts.output {
+		case "text":
+		case "json":
+			if !opts.dryRun {
+				return fmt.Errorf("--output json requires --dry-run")
+			}
+			if allKeyspaces != "" {
+				return fmt.Errorf("--output json cannot be used with --all-keyspaces")
+			}
+		default:
+			return fmt.Errorf("unsupported output %q (use text or json)", opts.output)
+		}
+		if outputFile, _ := cmd.Flags().GetString("output-file"); outputFile != "" && opts.output != "json" {
+			return fmt.Errorf("--output-file requires --output json")
+		}
+		if opts.reportPath != "" && allKeyspaces != "" {
+			return fmt.Errorf("--report cannot be used with --all-keyspaces")
+		}
+		if opts.metricsPath != "" && (allKeyspaces != "" || opts.dryRun) {
+			return fmt.Errorf("--metrics-file cannot be used with --all-keyspaces or --dry-run")
+		}
+		if opts.from != "" && opts.to != "" && migration.CompareVersions(opts.from, opts.to) > 0 {
+			return fmt.Errorf("--from %s is greater than --to %s", opts.from, opts.to)
+		}
 
-		// Acquire lock (skip for dry run)
-		if !dryRun {
-			log.Info().Msg("Acquiring migration lock...")
-			if err := ctx.LockManager.Acquire(cfg.LockTimeout); err != nil {
-				return fmt.Errorf("failed to acquire lock: %w", err)
+		// Interactive approval needs a terminal; in CI require an explicit --yes
+		if interactive && !opts.dryRun && !assumeYes() && !stdinIsTerminal() {
+			return fmt.Errorf("--interactive requires a terminal — pass --yes to run non-interactively")
+		}
+		opts.promptEach = interactive && !opts.dryRun && !assumeYes()
+
+		// Verify the migration files against the pinned manifest before
+		// touching the cluster
+		if verifyLock || updateLock {
+			if err := checkManifest(cfg.MigrationsDirs, updateLock); err != nil {
+				return err
 			}
-			defer func() {
-				if err := ctx.LockManager.Release(); err != nil {
-					log.Error().Err(err).Msg("Failed to release lock")
-				}
-			}()
 		}
 
-		// Scan migrations directory
-		scanned, err := migration.ScanMigrationsDir(cfg.MigrationsDir)
-		if err != nil {
-			return err
+		if allKeyspaces != "" {
+			return runMigrateAllKeyspaces(allKeyspaces, opts)
 		}
 
-		if len(scanned) == 0 {
-			log.Info().Str("dir", cfg.MigrationsDir).Msg("No migration files found")
-			return nil
+		if opts.output == "json" {
+			out, err := openReport(cmd)
+			if err != nil {
+				return err
+			}
+			defer out.Discard()
+			opts.planOut = out
+			if _, err := runMigrate(cfg, opts); err != nil {
+				return err
+			}
+			return out.Commit()
 		}
 
-		// Get applied migrations
-		applied, err := ctx.MetadataManager.GetAppliedMigrations()
-		if err != nil {
-			return fmt.Errorf("failed to get applied migrations: %w", err)
+		_, err := runMigrate(cfg, opts)
+		return err
+	},
+}
+
+// runMigrate applies pending migrations to the keyspace described by c. The
+// returned result is nil when nothing was pending.
+func runMigrate(c *config.Config, opts migrateOptions) (result *migration.RunResult, retErr error) {
+	var rep *report.Report
+	if opts.reportPath != "" {
+		rep = newRunReport(c, opts.dryRun)
+	}
+	started := time.Now()
+
+	// Notify about every real run that applied something or failed,
+	// including failures before execution (connect, lock, validation)
+	if !opts.dryRun {
+		defer func() {
+			if result == nil && retErr == nil {
+				return
+			}
+			var versions []string
+			duration := time.Since(started)
+			if result != nil {
+				versions = result.AppliedVersions()
+				duration = result.Duration
+			}
+			notify.NewNotifier(c, log).Send(notify.NewPayload("migrate", c.Keyspace, versions, duration, retErr))
+		}()
+	}
+
+	// Statement types are checked on the local files, before connecting
+	err := checkLocalStatementTypes(c, opts)
+	var ctx *migration.ExecutionContext
+	if err == nil {
+		ctx, err = newExecutionContext(c, log)
+	}
+	if err != nil {
+		if rep != nil {
+			if repErr := writeRunReport(opts.reportPath, rep, nil, nil, err); repErr != nil {
+				log.Error().Err(repErr).Msg("Failed to write report")
+			}
+		}
+		if opts.metricsPath != "" {
+			if metErr := writeRunMetrics(opts.metricsPath, c, nil, started, nil, err); metErr != nil {
+				log.Error().Err(metErr).Msg("Failed to write metrics")
+			}
 		}
+		return nil, err
+	}
+	defer ctx.Close()
+
+	if opts.metricsPath != "" {
+		defer func() {
+			if err := writeRunMetrics(opts.metricsPath, c, ctx, started, result, retErr); err != nil {
+				if retErr == nil {
+					retErr = fmt.Errorf("failed to write metrics: %w", err)
+					return
+				}
+				log.Error().Err(err).Msg("Failed to write metrics")
+			}
+		}()
+	}
 
-		// Validate checksums of applied migrations
-		resolver := migration.NewResolver(scanned)
-		if errors := resolver.ValidateAppliedChecksums(applied); len(errors) > 0 {
-			log.Error().Msg("Checksum validation failed:")
-			for _, e := range errors {
-				log.Error().Msg("  " + e)
+	if rep != nil {
+		recordClusterBefore(rep, ctx)
+		defer func() {
+			if err := writeRunReport(opts.reportPath, rep, ctx, result, retErr); err != nil {
+				if retErr == nil {
+					retErr = fmt.Errorf("failed to write report: %w", err)
+					return
+				}
+				log.Error().Err(err).Msg("Failed to write report")
 			}
-			return fmt.Errorf("checksum validation failed — run 'scylla-migrate validate' for details or 'scylla-migrate repair' to fix")
+		}()
+	}
+
+	ctx.DryRun = opts.dryRun
+
+	// Acquire lock (skip for dry run)
+	if !opts.dryRun {
+		if err := migration.CheckSchemaAgreement(ctx, opts.force); err != nil {
+			return nil, err
+		}
+		if err := migration.WaitForReadiness(ctx); err != nil {
+			return nil, err
+		}
+		log.Info().Msg("Acquiring migration lock...")
+		if err := ctx.LockManager.Acquire(c.LockTimeout); err != nil {
+			return nil, fmt.Errorf("failed to acquire lock: %w", err)
 		}
+		defer func() {
+			if err := ctx.LockManager.Release(); err != nil {
+				log.Error().Err(err).Msg("Failed to release lock")
+			}
+		}()
+	}
 
-		// Resolve pending migrations
-		pending, err := resolver.GetPendingMigrations(applied)
-		if err != nil {
-			return err
+	// Scan migrations directory
+	scanned, err := migration.ScanMigrationsDirs(c.MigrationsDirs, migration.ParseOptionsFor(c))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scanned) == 0 {
+		if opts.output == "json" {
+			return nil, writePlan(opts.planOut, nil)
 		}
+		log.Info().Strs("dirs", c.MigrationsDirs).Msg("No migration files found")
+		return nil, nil
+	}
 
-		// Filter by target version if specified
-		if target != "" {
-			pending = resolver.FilterUpToTarget(pending, target)
+	if c.WarnVersionGaps {
+		for _, gap := range migration.FindVersionGaps(scanned) {
+			log.Warn().Str("file", gap.Next).Msg("Gap in migration versions: " + gap.String())
+			rep.Warn(gap.String())
 		}
+	}
 
-		if len(pending) == 0 {
-			log.Info().Msg("Schema is up to date — no pending migrations")
-			return nil
+	// Get applied migrations
+	applied, err := ctx.MetadataManager.GetAppliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	resolver := migration.NewConfiguredResolver(ctx, scanned)
+
+	// Validate checksums of applied migrations
+	var failures []string
+	var mismatched []migration.ValidationIssue
+	for _, issue := range resolver.ValidateAppliedChecksumsDetailed(applied) {
+		if opts.repairChecksums && issue.Kind == migration.IssueChecksumMismatch {
+			mismatched = append(mismatched, issue)
+			continue
+		}
+		if c.AllowLocalModifications && issue.Kind == migration.IssueChecksumMismatch {
+			log.Warn().Msg("Ignoring locally modified migration (allow_local_modifications): " + issue.Message)
+			rep.Warn(issue.Message + " (ignored: allow_local_modifications)")
+			continue
 		}
+		failures = append(failures, issue.Message)
+	}
+	if len(failures) > 0 {
+		log.Error().Msg("Checksum validation failed:")
+		for _, e := range failures {
+			log.Error().Msg("  " + e)
+		}
+		return nil, fmt.Errorf("checksum validation failed — run 'scylla-migrate validate' for details or 'scylla-migrate repair' to fix")
+	}
+	if len(mismatched) > 0 {
+		backup := func() error { return backupUnless(opts.noBackup, ctx.Session) }
+		if err := repairChecksums(contextChecksumStore{ctx}, backup, mismatched, opts.dryRun); err != nil {
+			return nil, err
+		}
+		for _, issue := range mismatched {
+			rep.Warn(fmt.Sprintf("V%s: recorded checksum updated (--repair-checksums)", issue.Version))
+		}
+	}
 
-		// Execute
-		executor := migration.NewExecutor(ctx)
-		successCount, err := executor.ExecuteAll(pending)
+	// Resolve pending migrations, up to the target version if specified
+	plan, err := resolver.BuildPlan(applied, opts.target, migration.DirectionForward)
+	if err != nil {
+		return nil, err
+	}
+	pending := plan.ExecutionOrder()
+	for _, w := range plan.Warnings {
+		rep.Warn(w)
+	}
 
-		if err != nil {
-			log.Error().
-				Int("applied", successCount).
-				Int("total", len(pending)).
-				Err(err).
-				Msg("Migration failed")
-			return err
+	// A skipped migration is skipped on every run; log it each time but
+	// record the event only once
+	var skipRecorded map[string]bool
+	if skipped := resolver.Skipped(); len(skipped) > 0 && !opts.dryRun {
+		if skipRecorded, err = ctx.MetadataManager.RecordedVersions(schema.EventMigrationSkipped); err != nil {
+			log.Warn().Err(err).Msg("Failed to read earlier skip events")
+		}
+	}
+	for _, mig := range resolver.Skipped() {
+		log.Info().Str("version", mig.Version).Str("description", mig.Description).
+			Strs("environments", mig.Environments()).Str("environment", c.Environment).
+			Msg("Skipping migration not meant for this environment")
+		rep.Warn(fmt.Sprintf("V%s skipped: not meant for environment %q", mig.Version, c.Environment))
+		if !opts.dryRun && !skipRecorded[mig.Version] {
+			ctx.RecordEvent(schema.EventMigrationSkipped, mig.Version, mig.Description,
+				"environments: "+strings.Join(mig.Environments(), ","))
 		}
+	}
 
-		if dryRun {
-			log.Info().Int("count", len(pending)).Msg("Dry run complete — no changes applied")
+	for _, mig := range resolver.Ignored() {
+		log.Warn().Str("version", mig.Version).Str("description", mig.Description).
+			Msg("Ignoring migration older than the latest applied version (out_of_order: ignore)")
+		rep.Warn(fmt.Sprintf("V%s ignored: older than the latest applied version (out_of_order: ignore)", mig.Version))
+	}
+
+	// Restrict to an explicit version window if specified
+	if opts.from != "" || opts.to != "" {
+		pending = resolver.FilterRange(pending, opts.from, opts.to, opts.includeRepeatables)
+
+		// The range filter may drop a migration that a remaining one depends on
+		if pending, err = resolver.OrderByDependencies(pending, applied); err != nil {
+			return nil, err
+		}
+	}
+
+	// The JSON plan is emitted even when empty so CI always has an artifact
+	if opts.output == "json" {
+		return nil, writePlan(opts.planOut, pending)
+	}
+
+	if len(pending) == 0 {
+		log.Info().Msg("Schema is up to date — no pending migrations")
+		return nil, nil
+	}
+
+	// Destructive statement gate
+	if c.ConfirmDestructive && !opts.dryRun && !opts.allowDestructive {
+		if err := confirmDestructive(migration.FindDestructiveStatements(pending)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Execute
+	executor := migration.NewExecutor(ctx)
+	if opts.promptEach {
+		executor.BeforeEach = newMigrationApprover().Approve
+	}
+	result = executor.Run(pending)
+
+	if result.Err != nil {
+		log.Error().
+			Int("applied", len(result.Applied)).
+			Int("total", result.Total).
+			Err(result.Err).
+			Msg("Migration failed")
+		return result, result.Err
+	}
+
+	if opts.dryRun {
+		log.Info().Int("count", len(pending)).Msg("Dry run complete — no changes applied")
+	} else {
+		if result.Skipped > 0 {
+			log.Warn().
+				Int("applied", len(result.Applied)).
+				Int("skipped", result.Skipped).
+				Msg("Migrations applied; some were skipped and remain pending")
 		} else {
-			log.Info().Int("count", successCount).Msg("All migrations applied successfully")
+			log.Info().Int("count", len(result.Applied)).Msg("All migrations applied successfully")
 		}
+	}
+
+	return result, nil
+}
 
+// checksumStore is the metadata access repairChecksums needs.
+type checksumStore interface {
+	UpdateChecksum(version, newChecksum string) error
+	RecordEvent(eventType, version, description, message string)
+}
+
+// contextChecksumStore is the checksumStore of an execution context.
+type contextChecksumStore struct {
+	*migration.ExecutionContext
+}
+
+func (s contextChecksumStore) UpdateChecksum(version, newChecksum string) error {
+	return s.MetadataManager.UpdateChecksum(version, newChecksum)
+}
+
+// repairChecksums records the current checksum of every changed applied
+// migration in issues, after confirmation and backup, logging each update
+// and adding it to the event log. A dry run only lists them.
+func repairChecksums(store checksumStore, backup func() error, issues []migration.ValidationIssue, dryRun bool) error {
+	if len(issues) == 0 {
 		return nil
-	},
+	}
+	for _, issue := range issues {
+		log.Warn().Str("version", issue.Version).
+			Str("recorded", issue.RecordedChecksum).Str("current", issue.CurrentChecksum).
+			Msg("Applied migration changed since it was applied")
+	}
+	if dryRun {
+		log.Info().Int("count", len(issues)).Msg("[DRY RUN] Would update recorded checksums (--repair-checksums)")
+		return nil
+	}
+
+	ok, err := confirm(fmt.Sprintf("Update the recorded checksums of %d applied migration(s) and continue?", len(issues)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("checksum repair declined — nothing was changed")
+	}
+	if err := backup(); err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		if err := store.UpdateChecksum(issue.Version, issue.CurrentChecksum); err != nil {
+			return fmt.Errorf("failed to update checksum of %s: %w", issue.Version, err)
+		}
+		store.RecordEvent(schema.EventChecksumUpdated, issue.Version, "",
+			fmt.Sprintf("%s -> %s (migrate --repair-checksums)", issue.RecordedChecksum, issue.CurrentChecksum))
+		log.Info().Str("version", issue.Version).
+			Str("old", issue.RecordedChecksum).Str("new", issue.CurrentChecksum).
+			Msg("Updated checksum")
+	}
+	return nil
+}
+
+func writePlan(w io.Writer, pending []*migration.Migration) error {
+	plan, err := migration.NewPlan(pending)
+	if err != nil {
+		return fmt.Errorf("failed to build plan: %w", err)
+	}
+	return plan.WriteJSON(w)
+}
+
+// checkManifest compares the migration files against migrations.lock. With
+// several migrations directories, the manifest lives in the first one and
+// covers all of them.
+func checkManifest(dirs []string, update bool) error {
+	scanned, err := migration.ScanMigrationsDirs(dirs, migration.ParseOptionsFor(cfg))
+	if err != nil {
+		return err
+	}
+
+	current, err := migration.BuildManifest(scanned)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dirs[0], migration.ManifestFileName)
+	found := true
+	expected, err := migration.ReadManifest(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read manifest: %w", err)
+		}
+		if !update {
+			return fmt.Errorf("manifest %s not found — run with --update-lock to create it", path)
+		}
+		found = false
+	}
+
+	diff := migration.CompareManifest(expected, current)
+	if found && diff.Empty() {
+		log.Info().Str("manifest", path).Int("files", len(current)).Msg("Migration files match manifest")
+		return nil
+	}
+
+	for _, f := range diff.Added {
+		log.Warn().Str("file", f).Msg("Migration file added since manifest was generated")
+	}
+	for _, f := range diff.Removed {
+		log.Warn().Str("file", f).Msg("Migration file removed since manifest was generated")
+	}
+	for _, f := range diff.Changed {
+		log.Warn().Str("file", f).Msg("Migration file changed since manifest was generated")
+	}
+
+	if !update {
+		return fmt.Errorf("migration files differ from %s (%d added, %d removed, %d changed) — review the changes and run with --update-lock to accept them",
+			path, len(diff.Added), len(diff.Removed), len(diff.Changed))
+	}
+
+	if err := migration.WriteManifest(path, current); err != nil {
+		return err
+	}
+	log.Info().Str("manifest", path).Int("files", len(current)).Msg("Manifest updated")
+	return nil
+}
+
+// newExecutionContext connects migrate; replaced in tests.
+var newExecutionContext = migration.NewExecutionContext
+
+// checkLocalStatementTypes enforces allowed_statement_types on the local
+// migrations the run may apply: the versioned ones within --target or
+// --from/--to and the repeatables, all meant for the environment. Telling
+// applied migrations apart needs the cluster, so they are checked too.
+func checkLocalStatementTypes(c *config.Config, opts migrateOptions) error {
+	if len(c.AllowedStatementTypes) == 0 {
+		return nil
+	}
+	scanned, err := migration.ScanMigrationsDirs(c.MigrationsDirs, migration.ParseOptionsFor(c))
+	if err != nil {
+		return err
+	}
+
+	var scope []*migration.Migration
+	for _, mig := range scanned {
+		if mig.Type == migration.TypeUndo {
+			continue
+		}
+		if err := migration.ParseMigrationFile(mig); err != nil {
+			return fmt.Errorf("failed to parse migration %s: %w", mig.Filename, err)
+		}
+		if mig.RunsIn(c.Environment) {
+			scope = append(scope, mig)
+		}
+	}
+	resolver := migration.NewResolverFor(c, scope)
+	if opts.target != "" {
+		scope = resolver.FilterUpToTarget(scope, opts.target)
+	}
+	if opts.from != "" || opts.to != "" {
+		scope = resolver.FilterRange(scope, opts.from, opts.to, opts.includeRepeatables)
+	}
+	return checkStatementTypes(c, scope)
+}
+
+// checkStatementTypes enforces allowed_statement_types before anything is
+// executed, logging every offending statement.
+func checkStatementTypes(c *config.Config, migs []*migration.Migration) error {
+	err := migration.CheckStatementTypes(migs, c.AllowedStatementTypes)
+	var typeErr *migration.StatementTypeError
+	if errors.As(err, &typeErr) {
+		for _, d := range typeErr.Found {
+			log.Error().
+				Str("version", d.Migration.Version).
+				Str("file", d.Migration.Filename).
+				Int("statement", d.Index+1).
+				Str("type", d.Type).
+				Msg("Statement type not allowed by allowed_statement_types")
+		}
+	}
+	return err
 }
 
 func init() {
 	rootCmd.AddCommand(migrateCmd)
 	migrateCmd.Flags().Bool("dry-run", false, "show migrations without applying them")
 	migrateCmd.Flags().String("target", "", "target version to migrate to (e.g., 003)")
+	migrateCmd.Flags().String("from", "", "lowest version to apply (inclusive)")
+	migrateCmd.Flags().String("to", "", "highest version to apply (inclusive)")
+	migrateCmd.Flags().Bool("include-repeatables", false, "also apply pending repeatable migrations when --from/--to is set")
+	migrateCmd.Flags().Bool("verify-lock", false, "refuse to run if migration files differ from migrations.lock")
+	migrateCmd.Flags().Bool("update-lock", false, "write migrations.lock from the current migration files")
+	migrateCmd.Flags().Bool("interactive", false, "show each migration's statements and ask for approval before running it")
+	migrateCmd.Flags().Bool("allow-destructive", false, "allow DROP/TRUNCATE statements when confirm_destructive is enabled")
+	migrateCmd.Flags().String("all-keyspaces", "", "apply to every keyspace whose name matches this prefix/regex (keyspace-per-tenant)")
+	migrateCmd.Flags().String("output", "text", "dry-run output format (text, json)")
+	_ = migrateCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	addOutputFileFlag(migrateCmd)
+	migrateCmd.Flags().Bool("dev", false, "development mode: warn instead of failing on locally modified applied migrations (requires a non-production --environment)")
+	migrateCmd.Flags().Bool("no-backup", false, "skip the metadata backup taken before --repair-checksums")
+	migrateCmd.Flags().Bool("repair-checksums", false, "update the recorded checksums of changed applied migrations, then migrate (asks for confirmation unless --yes)")
+	migrateCmd.Flags().Bool("force", false, "start even if the cluster is already in schema disagreement")
+	migrateCmd.Flags().String("report", "", "write an execution report to this file, also on failure (.md for Markdown, otherwise JSON)")
+	_ = migrateCmd.MarkFlagFilename("report", "json", "md")
+	migrateCmd.Flags().String("metrics-file", "", "write Prometheus metrics to this file after the run, for node-exporter's textfile collector")
+	_ = migrateCmd.MarkFlagFilename("metrics-file", "prom")
 }
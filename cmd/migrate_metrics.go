@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/metrics"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+// writeRunMetrics writes Prometheus metrics for the run to path, replacing
+// the file atomically since node-exporter may read it at any time. ctx is
+// nil when the run could not connect; the metrics then only report the
+// failure.
+func writeRunMetrics(path string, c *config.Config, ctx *migration.ExecutionContext, started time.Time,
+	result *migration.RunResult, runErr error) error {
+	run := metrics.Run{
+		Keyspace: c.Keyspace,
+		Success:  runErr == nil,
+		Duration: time.Since(started),
+		Pending:  -1,
+	}
+	if result != nil {
+		run.Applied = len(result.Applied)
+	}
+
+	if ctx != nil {
+		if applied, err := ctx.MetadataManager.GetAppliedMigrations(); err != nil {
+			log.Warn().Err(err).Msg("Failed to read applied migrations for metrics")
+		} else {
+			for _, a := range applied {
+				run.Records = append(run.Records, metrics.Record{
+					Version: a.Version, Type: a.Type, Success: a.Success, AppliedAt: a.AppliedAt,
+				})
+			}
+			if pending, err := countPending(c, ctx, applied); err != nil {
+				log.Warn().Err(err).Msg("Failed to count pending migrations for metrics")
+			} else {
+				run.Pending = pending
+			}
+		}
+	}
+
+	out, err := openReportFile(path)
+	if err != nil {
+		return err
+	}
+	defer out.Discard()
+	if err := metrics.WriteTextfile(out, run); err != nil {
+		return err
+	}
+	return out.Commit()
+}
+
+func countPending(c *config.Config, ctx *migration.ExecutionContext, applied []schema.AppliedMigration) (int, error) {
+	scanned, err := migration.ScanMigrationsDirs(c.MigrationsDirs, migration.ParseOptionsFor(c))
+	if err != nil {
+		return 0, err
+	}
+	pending, err := migration.NewConfiguredResolver(ctx, scanned).GetPendingMigrations(applied)
+	if err != nil {
+		return 0, err
+	}
+	return len(pending), nil
+}
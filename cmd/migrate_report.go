@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/report"
+)
+
+func newRunReport(c *config.Config, dryRun bool) *report.Report {
+	return &report.Report{
+		StartedAt: time.Now(),
+		Keyspace:  c.Keyspace,
+		DryRun:    dryRun,
+	}
+}
+
+// recordClusterBefore notes who runs the migration and the cluster's schema
+// version before anything is applied.
+func recordClusterBefore(rep *report.Report, ctx *migration.ExecutionContext) {
+	rep.Operator = ctx.Operator()
+	if meta, err := ctx.Session.GetClusterMetadata(); err == nil {
+		rep.Cluster = meta.ClusterName
+		rep.SchemaVersionBefore = meta.SchemaVer
+	}
+}
+
+// writeRunReport completes the report with the outcome of the run and writes
+// it to path, as Markdown for .md files and JSON otherwise. ctx is nil when
+// the run could not connect. It is called on
+// success and on failure alike, so a failed run still leaves its partial
+// state and the failing statement on record.
+func writeRunReport(path string, rep *report.Report, ctx *migration.ExecutionContext, result *migration.RunResult, runErr error) error {
+	rep.DurationMS = time.Since(rep.StartedAt).Milliseconds()
+	rep.Success = runErr == nil
+
+	if ctx != nil {
+		if meta, err := ctx.Session.GetClusterMetadata(); err == nil {
+			rep.SchemaVersionAfter = meta.SchemaVer
+		}
+	}
+
+	if result != nil {
+		for _, a := range result.Applied {
+			rep.Migrations = append(rep.Migrations, report.Migration{
+				Version:     a.Version,
+				Type:        string(a.Type),
+				Description: a.Description,
+				Statements:  a.Statements,
+				DurationMS:  a.Duration.Milliseconds(),
+			})
+		}
+		rep.Warnings = append(rep.Warnings, result.Warnings...)
+	}
+
+	if runErr != nil {
+		rep.Failure = &report.Failure{Error: runErr.Error()}
+		var stmtErr *migration.StatementError
+		if errors.As(runErr, &stmtErr) {
+			rep.Failure.Version = stmtErr.Version
+			rep.Failure.File = stmtErr.Filename
+			rep.Failure.Statement = stmtErr.Index + 1
+			rep.Failure.CQL = stmtErr.Statement
+		}
+	}
+
+	out, err := openReportFile(path)
+	if err != nil {
+		return err
+	}
+	defer out.Discard()
+
+	if report.IsMarkdown(path) {
+		err = rep.WriteMarkdown(out)
+	} else {
+		err = rep.WriteJSON(out)
+	}
+	if err != nil {
+		return err
+	}
+	return out.Commit()
+}
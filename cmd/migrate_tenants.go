@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+)
+
+// runMigrateAllKeyspaces applies the migration set to every keyspace whose
+// name matches pattern (keyspace-per-tenant deployments). Each tenant keeps
+// its own metadata tables and lock inside its keyspace, so tenants never
+// share history. A failing tenant is reported and the loop moves on.
+func runMigrateAllKeyspaces(pattern string, opts migrateOptions) error {
+	re, err := regexp.Compile("^(?:" + pattern + ")")
+	if err != nil {
+		return fmt.Errorf("invalid --all-keyspaces pattern: %w", err)
+	}
+
+	session, err := driver.NewSession(cfg, log)
+	if err != nil {
+		return err
+	}
+	keyspaces, err := session.ListKeyspaces()
+	session.Close()
+	if err != nil {
+		return fmt.Errorf("failed to list keyspaces: %w", err)
+	}
+
+	var matched []string
+	for _, ks := range keyspaces {
+		if isSystemKeyspace(ks) || ks == cfg.MetadataKeyspace {
+			continue
+		}
+		if re.MatchString(ks) {
+			matched = append(matched, ks)
+		}
+	}
+
+	if len(matched) == 0 {
+		return fmt.Errorf("no keyspaces match pattern %q", pattern)
+	}
+
+	log.Info().Int("keyspaces", len(matched)).Str("pattern", pattern).Msg("Migrating tenant keyspaces")
+
+	type tenantResult struct {
+		keyspace string
+		applied  int
+		err      error
+	}
+
+	var results []tenantResult
+	failed := 0
+	for i, ks := range matched {
+		log.Info().
+			Int("current", i+1).
+			Int("total", len(matched)).
+			Str("keyspace", ks).
+			Msg("Migrating keyspace")
+
+		res, err := runMigrate(tenantConfig(cfg, ks), opts)
+		r := tenantResult{keyspace: ks, err: err}
+		if res != nil {
+			r.applied = len(res.Applied)
+		}
+		if err != nil {
+			failed++
+			log.Error().Str("keyspace", ks).Err(err).Msg("Keyspace migration failed, continuing")
+		}
+		results = append(results, r)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEYSPACE\tAPPLIED\tSTATUS")
+	fmt.Fprintln(w, "--------\t-------\t------")
+	for _, r := range results {
+		status := "OK"
+		if r.err != nil {
+			status = "FAILED: " + r.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", r.keyspace, r.applied, status)
+	}
+	w.Flush()
+
+	fmt.Printf("\nKeyspaces: %d | Succeeded: %d | Failed: %d\n", len(results), len(results)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keyspace(s) failed to migrate", failed, len(results))
+	}
+	return nil
+}
+
+// tenantConfig is base pointed at the tenant keyspace ks, with the metadata
+// tables inside it. The environment only suffixes the lock id: a suffixed
+// metadata keyspace would be a new keyspace, matched as a tenant next run.
+func tenantConfig(base *config.Config, ks string) *config.Config {
+	c := *base
+	c.Keyspace = ks
+	c.MetadataKeyspace = ks
+	c.BindKeyspace = true
+	return &c
+}
+
+func isSystemKeyspace(ks string) bool {
+	return strings.HasPrefix(ks, "system") || ks == "dse_system" || ks == "cassandra"
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+func TestTenantConfig(t *testing.T) {
+	base := &config.Config{Keyspace: "app", MetadataKeyspace: "app_meta_staging", Environment: "staging"}
+
+	c := tenantConfig(base, "tenant_a")
+	assert.Equal(t, "tenant_a", c.Keyspace)
+	// Metadata stays inside the tenant keyspace whatever the environment
+	assert.Equal(t, "tenant_a", c.MetadataKeyspace)
+	assert.Equal(t, "staging", c.Environment)
+	assert.True(t, c.BindKeyspace)
+	assert.Equal(t, "app_meta_staging", base.MetadataKeyspace, "base config is left alone")
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+func TestRunMigrate_StatementTypesCheckedBeforeConnecting(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("V001__create.cql", "CREATE TABLE t (id int PRIMARY KEY);")
+	write("V002__backfill.cql", "INSERT INTO t (id) VALUES (1);")
+	write("U001__create.cql", "DROP TABLE t;")
+
+	connected := false
+	orig := newExecutionContext
+	t.Cleanup(func() { newExecutionContext = orig })
+	errOffline := errors.New("not connecting in tests")
+	newExecutionContext = func(*config.Config, zerolog.Logger) (*migration.ExecutionContext, error) {
+		connected = true
+		return nil, errOffline
+	}
+
+	c := &config.Config{MigrationsDirs: []string{dir}, AllowedStatementTypes: []string{"dml"}}
+	_, err := runMigrate(c, migrateOptions{dryRun: true})
+	var typeErr *migration.StatementTypeError
+	require.ErrorAs(t, err, &typeErr)
+	assert.Equal(t, "001", typeErr.Found[0].Migration.Version)
+	assert.False(t, connected, "no session is opened for a rejected run")
+
+	// A window holding only DML passes the check and goes on to connect
+	_, err = runMigrate(c, migrateOptions{dryRun: true, from: "002"})
+	assert.ErrorIs(t, err, errOffline)
+	assert.True(t, connected)
+}
+
+type fakeChecksumStore struct {
+	updated map[string]string
+	events  []string
+}
+
+func (s *fakeChecksumStore) UpdateChecksum(version, newChecksum string) error {
+	if s.updated == nil {
+		s.updated = make(map[string]string)
+	}
+	s.updated[version] = newChecksum
+	return nil
+}
+
+func (s *fakeChecksumStore) RecordEvent(eventType, version, _, _ string) {
+	s.events = append(s.events, eventType+" "+version)
+}
+
+func TestRepairChecksums(t *testing.T) {
+	issues := []migration.ValidationIssue{
+		{Version: "001", Kind: migration.IssueChecksumMismatch, RecordedChecksum: "old", CurrentChecksum: "new"},
+	}
+	backups := 0
+	backup := func() error { backups++; return nil }
+
+	t.Run("mismatch fixed", func(t *testing.T) {
+		withYesFlag(t)
+		backups = 0
+		store := &fakeChecksumStore{}
+		require.NoError(t, repairChecksums(store, backup, issues, false))
+		assert.Equal(t, map[string]string{"001": "new"}, store.updated)
+		assert.Equal(t, []string{"checksum_updated 001"}, store.events)
+		assert.Equal(t, 1, backups)
+	})
+
+	t.Run("nothing to repair", func(t *testing.T) {
+		backups = 0
+		store := &fakeChecksumStore{}
+		require.NoError(t, repairChecksums(store, backup, nil, false))
+		assert.Empty(t, store.updated)
+		assert.Zero(t, backups)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		withYesFlag(t)
+		backups = 0
+		store := &fakeChecksumStore{}
+		require.NoError(t, repairChecksums(store, backup, issues, true))
+		assert.Empty(t, store.updated)
+		assert.Empty(t, store.events)
+		assert.Zero(t, backups)
+	})
+
+	t.Run("failed backup changes nothing", func(t *testing.T) {
+		withYesFlag(t)
+		store := &fakeChecksumStore{}
+		err := repairChecksums(store, func() error { return errors.New("disk full") }, issues, false)
+		assert.ErrorContains(t, err, "disk full")
+		assert.Empty(t, store.updated)
+	})
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+var nextVersionCmd = &cobra.Command{
+	Use:   "next-version",
+	Short: "Print the next version numbers create would assign",
+	Long: `Print the next version numbers that 'create' would assign, one per line,
+so a batch of migrations can be planned before the files exist. Reads only
+the migrations directories; no cluster connection is made.
+
+Numbers are not reserved: another 'create' in the same directories takes the
+first of them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadOfflineConfig(); err != nil {
+			return err
+		}
+
+		count, _ := cmd.Flags().GetInt("count")
+		if count < 1 {
+			return fmt.Errorf("--count must be at least 1")
+		}
+
+		versions, err := migration.GetNextVersions(count, cfg.MigrationsDirs...)
+		if err != nil {
+			return fmt.Errorf("failed to determine next version: %w", err)
+		}
+		for _, v := range versions {
+			fmt.Printf("%03d\n", v)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nextVersionCmd)
+	nextVersionCmd.Flags().Int("count", 1, "number of versions to print")
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// addOutputFileFlag registers --output-file on a command that produces a
+// report, so every report can be written to a file the same way.
+func addOutputFileFlag(cmd *cobra.Command) {
+	cmd.Flags().String("output-file", "", "write the report to this file instead of stdout (replaced atomically)")
+	_ = cmd.MarkFlagFilename("output-file")
+}
+
+// reportOutput is where a command writes its report: stdout, or a temporary
+// file next to --output-file that Commit renames into place. Logs keep going
+// to stderr either way.
+type reportOutput struct {
+	io.Writer
+	tmp  *os.File
+	path string
+}
+
+func openReport(cmd *cobra.Command) (*reportOutput, error) {
+	path, _ := cmd.Flags().GetString("output-file")
+	return openReportFile(path)
+}
+
+// openReportFile is openReport for a path not taken from --output-file;
+// "" and "-" mean stdout.
+func openReportFile(path string) (*reportOutput, error) {
+	if path == "" || path == "-" {
+		return &reportOutput{Writer: os.Stdout}, nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return &reportOutput{Writer: tmp, tmp: tmp, path: path}, nil
+}
+
+// Commit publishes the report. Readers of --output-file see either the
+// previous file or the complete new one, never a partial write.
+func (r *reportOutput) Commit() error {
+	if r.tmp == nil {
+		return nil
+	}
+	tmp := r.tmp
+	r.tmp = nil
+
+	// CreateTemp uses 0600; match what os.Create would have produced
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", r.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", r.path, err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", r.path, err)
+	}
+	log.Info().Str("file", r.path).Msg("Report written")
+	return nil
+}
+
+// Discard drops an uncommitted report; it is safe to defer after Commit.
+func (r *reportOutput) Discard() {
+	if r.tmp != nil {
+		r.tmp.Close()
+		os.Remove(r.tmp.Name())
+		r.tmp = nil
+	}
+}
+
+// formatTime renders a stored timestamp per time_display. Validate has
+// already checked the timezone, so the UTC fallback is never used in
+// practice.
+func formatTime(t time.Time) string {
+	loc, err := cfg.TimeDisplay.Location()
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(cfg.TimeDisplay.Layout())
+}
+
+// encodeReport writes v as indented JSON or as YAML.
+func encodeReport(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportOutput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "status.txt")
+	require.NoError(t, os.WriteFile(path, []byte("previous"), 0o644))
+
+	cmd := &cobra.Command{}
+	addOutputFileFlag(cmd)
+	require.NoError(t, cmd.Flags().Set("output-file", path))
+
+	// A discarded report leaves the previous file and no temp file behind
+	out, err := openReport(cmd)
+	require.NoError(t, err)
+	fmt.Fprint(out, "partial")
+	out.Discard()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(data))
+
+	out, err = openReport(cmd)
+	require.NoError(t, err)
+	fmt.Fprint(out, "report")
+	require.NoError(t, out.Commit())
+	out.Discard()
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "report", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestEncodeReport(t *testing.T) {
+	v := []struct {
+		Version string `json:"version" yaml:"version"`
+	}{{Version: "001"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, encodeReport(&buf, "yaml", v))
+	assert.Equal(t, "- version: \"001\"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, encodeReport(&buf, "json", v))
+	assert.JSONEq(t, `[{"version":"001"}]`, buf.String())
+
+	assert.Error(t, encodeReport(&buf, "xml", v))
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+var pendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "List pending migrations in execution order",
+	Long: `List only the migrations that migrate would apply, in the order it would
+apply them. Unlike status, the output is meant for scripts, e.g. generating a
+changelog.
+
+Applied migrations are read from the cluster, or, with --applied-from, from a
+file written by 'metadata export --format json', without connecting at all.
+--offline treats nothing as applied.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		target, _ := cmd.Flags().GetString("target")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		includeRepeatables, _ := cmd.Flags().GetBool("include-repeatables")
+		appliedFrom, _ := cmd.Flags().GetString("applied-from")
+		offline, _ := cmd.Flags().GetBool("offline")
+
+		if format != "text" && format != "json" && format != "yaml" {
+			return fmt.Errorf("unsupported format %q (use text, json or yaml)", format)
+		}
+
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+		if err != nil {
+			return err
+		}
+
+		applied, resolver, err := loadApplied(appliedFrom, offline, scanned)
+		if err != nil {
+			return err
+		}
+
+		plan, err := resolver.BuildPlan(applied, target, migration.DirectionForward)
+		if err != nil {
+			return err
+		}
+		pending := plan.ExecutionOrder()
+
+		if from != "" || to != "" {
+			pending = resolver.FilterRange(pending, from, to, includeRepeatables)
+			if pending, err = resolver.OrderByDependencies(pending, applied); err != nil {
+				return err
+			}
+		}
+
+		type pendingEntry struct {
+			Version     string `json:"version" yaml:"version"`
+			Type        string `json:"type" yaml:"type"`
+			Description string `json:"description" yaml:"description"`
+			Filename    string `json:"filename" yaml:"filename"`
+		}
+
+		entries := make([]pendingEntry, 0, len(pending))
+		for _, mig := range pending {
+			entries = append(entries, pendingEntry{
+				Version:     mig.Version,
+				Type:        string(mig.Type),
+				Description: mig.Description,
+				Filename:    mig.Filename,
+			})
+		}
+
+		out, err := openReport(cmd)
+		if err != nil {
+			return err
+		}
+		defer out.Discard()
+
+		if format != "text" {
+			if err := encodeReport(out, format, entries); err != nil {
+				return err
+			}
+			return out.Commit()
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tTYPE\tDESCRIPTION\tFILENAME")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Version, e.Type, e.Description, e.Filename)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return out.Commit()
+	},
+}
+
+// loadApplied returns the applied migrations from an export file, from
+// nowhere (offline), or from the cluster, with a resolver for scanned. Only
+// a connected resolver knows the repeatable index.
+func loadApplied(appliedFrom string, offline bool, scanned []*migration.Migration) ([]schema.AppliedMigration, *migration.Resolver, error) {
+	if appliedFrom != "" {
+		f, err := os.Open(appliedFrom)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %s: %w", appliedFrom, err)
+		}
+		defer f.Close()
+		applied, err := schema.ImportJSON(f)
+		return applied, migration.NewResolverFor(cfg, scanned), err
+	}
+	if offline {
+		return nil, migration.NewResolverFor(cfg, scanned), nil
+	}
+
+	ctx, err := newReadOnlyContext()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer ctx.Close()
+
+	applied, err := ctx.MetadataManager.GetAppliedMigrations()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	return applied, migration.NewConfiguredResolver(ctx, scanned), nil
+}
+
+func init() {
+	rootCmd.AddCommand(pendingCmd)
+	pendingCmd.Flags().String("format", "text", "output format (text, json, yaml)")
+	_ = pendingCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"text", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
+	addOutputFileFlag(pendingCmd)
+	pendingCmd.Flags().String("target", "", "only list migrations up to this version")
+	pendingCmd.Flags().String("from", "", "lowest version to list (inclusive)")
+	pendingCmd.Flags().String("to", "", "highest version to list (inclusive)")
+	pendingCmd.Flags().Bool("include-repeatables", false, "also list repeatable migrations when --from/--to is set")
+	pendingCmd.Flags().String("applied-from", "", "read applied migrations from a 'metadata export --format json' file instead of the cluster")
+	pendingCmd.Flags().Bool("offline", false, "do not connect; treat every migration as pending")
+}
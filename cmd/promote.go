@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// draftsDir is where create --draft writes migrations awaiting review. The
+// scanner does not descend into it, so drafts never run.
+const draftsDir = "drafts"
+
+// draftVersionHeader stands in for the version header until promote.
+const draftVersionHeader = "-- Version: draft"
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote <draft>",
+	Short: "Assign the next version to a draft migration",
+	Long: `Move a draft created with 'create --draft' from the drafts/ directory into the
+migrations directory as V<next>__<name>.cql, together with its undo draft if
+there is one. Run it when the migration is merged, so version numbers are
+only taken at merge time.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadOfflineConfig(); err != nil {
+			return err
+		}
+
+		files, err := promoteDraft(cfg.PrimaryMigrationsDir(), cfg.MigrationsDirs, args[0])
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			log.Info().Str("file", f).Msg("Promoted draft migration")
+		}
+		return nil
+	},
+}
+
+// draftFilenames returns the draft and undo draft filenames for name.
+func draftFilenames(name string) (string, string) {
+	return name + ".cql", name + ".undo.cql"
+}
+
+// writeDraft scaffolds a draft migration, and its undo when withUndo is set,
+// in the drafts directory of migrationsDir.
+func writeDraft(migrationsDir, name, sanitized, timestamp string, table *migration.TableSpec, withUndo bool) ([]string, error) {
+	dir := filepath.Join(migrationsDir, draftsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create drafts directory: %w", err)
+	}
+
+	draftFile, undoFile := draftFilenames(sanitized)
+	path := filepath.Join(dir, draftFile)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("draft %s already exists", path)
+	}
+
+	content := fmt.Sprintf(`-- Migration: %s
+%s
+-- Created: %s
+
+`, name, draftVersionHeader, timestamp)
+	if table != nil {
+		content += table.CreateCQL()
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	files := []string{path}
+
+	if withUndo {
+		undoPath := filepath.Join(dir, undoFile)
+		undoContent := fmt.Sprintf(`-- Undo Migration: %s
+%s
+-- Created: %s
+--
+-- This script reverses the changes made by %s/%s
+
+`, name, draftVersionHeader, timestamp, draftsDir, draftFile)
+		if table != nil {
+			undoContent += table.DropCQL()
+		}
+		if err := os.WriteFile(undoPath, []byte(undoContent), 0644); err != nil {
+			return nil, fmt.Errorf("failed to create undo file: %w", err)
+		}
+		files = append(files, undoPath)
+	}
+	return files, nil
+}
+
+// promoteDraft moves the draft name (a draft name, filename or path) into
+// migrationsDir under the next version across dirs.
+func promoteDraft(migrationsDir string, dirs []string, name string) ([]string, error) {
+	name = strings.TrimSuffix(filepath.Base(name), ".cql")
+	draftFile, undoFile := draftFilenames(name)
+	draftPath := filepath.Join(migrationsDir, draftsDir, draftFile)
+	undoPath := filepath.Join(migrationsDir, draftsDir, undoFile)
+
+	content, err := os.ReadFile(draftPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+	undoContent, err := os.ReadFile(undoPath)
+	hasUndo := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read undo draft: %w", err)
+	}
+
+	version, err := migration.GetNextVersion(dirs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine next version: %w", err)
+	}
+	filename := fmt.Sprintf("V%03d__%s.cql", version, name)
+	versionHeader := fmt.Sprintf("-- Version: %03d", version)
+
+	type move struct {
+		from, to string
+		content  string
+	}
+	targets := []move{{draftPath, filepath.Join(migrationsDir, filename), string(content)}}
+	if hasUndo {
+		undo := strings.Replace(string(undoContent), draftsDir+"/"+draftFile, filename, 1)
+		targets = append(targets, move{undoPath, filepath.Join(migrationsDir, fmt.Sprintf("U%03d__%s.cql", version, name)), undo})
+	}
+
+	for _, t := range targets {
+		if _, err := os.Stat(t.to); err == nil {
+			return nil, fmt.Errorf("%s already exists", t.to)
+		}
+	}
+
+	var files []string
+	for _, t := range targets {
+		promoted := strings.Replace(t.content, draftVersionHeader, versionHeader, 1)
+		if err := writePromoted(t.to, promoted); err != nil {
+			return files, fmt.Errorf("failed to write %s: %w", t.to, err)
+		}
+		if err := os.Remove(t.from); err != nil {
+			return files, fmt.Errorf("failed to remove draft %s: %w", t.from, err)
+		}
+		files = append(files, t.to)
+	}
+	return files, nil
+}
+
+// renameFile is os.Rename, replaced in tests to fail the promotion.
+var renameFile = os.Rename
+
+// writePromoted writes content to a temporary file next to path and renames
+// it into place, so a failed promotion leaves no partial migration behind
+// and the draft, removed only afterwards, is still there to retry.
+func writePromoted(path, content string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.WriteString(content)
+	if err == nil {
+		// CreateTemp uses 0600; match the rest of the migrations
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = renameFile(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromoteDraft(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "V001__init.cql"), []byte("SELECT 1;"), 0644))
+
+	drafts, err := writeDraft(dir, "add orders", "add_orders", "2026-01-01 00:00:00", nil, true)
+	require.NoError(t, err)
+	require.Len(t, drafts, 2)
+	assert.Equal(t, filepath.Join(dir, "drafts", "add_orders.cql"), drafts[0])
+
+	_, err = writeDraft(dir, "add orders", "add_orders", "2026-01-01 00:00:00", nil, false)
+	assert.Error(t, err, "an existing draft is not overwritten")
+
+	files, err := promoteDraft(dir, []string{dir}, "drafts/add_orders.cql")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "V002__add_orders.cql"),
+		filepath.Join(dir, "U002__add_orders.cql"),
+	}, files)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "-- Version: 002\n")
+	undo, err := os.ReadFile(files[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(undo), "reverses the changes made by V002__add_orders.cql")
+
+	assert.NoFileExists(t, drafts[0])
+	assert.NoFileExists(t, drafts[1])
+
+	_, err = promoteDraft(dir, []string{dir}, "add_orders")
+	assert.Error(t, err, "the draft is gone")
+}
+
+func TestPromoteDraft_FailedWriteKeepsDraft(t *testing.T) {
+	dir := t.TempDir()
+	drafts, err := writeDraft(dir, "add orders", "add_orders", "2026-01-01 00:00:00", nil, false)
+	require.NoError(t, err)
+
+	renameFile = func(string, string) error { return errors.New("disk full") }
+	t.Cleanup(func() { renameFile = os.Rename })
+
+	_, err = promoteDraft(dir, []string{dir}, "add_orders")
+	require.ErrorContains(t, err, "disk full")
+	assert.FileExists(t, drafts[0])
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "neither the migration nor its temporary file is left behind")
+	assert.Equal(t, "drafts", entries[0].Name())
+
+	renameFile = os.Rename
+	files, err := promoteDraft(dir, []string{dir}, "add_orders")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "V001__add_orders.cql")}, files)
+	assert.NoFileExists(t, drafts[0])
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -11,7 +12,11 @@ import (
 var repairCmd = &cobra.Command{
 	Use:   "repair",
 	Short: "Repair migration metadata",
-	Long:  "Fix migration metadata: recalculate checksums for applied migrations or remove failed migration records.",
+	Long: `Fix migration metadata: recalculate checksums for applied migrations or remove failed migration records.
+
+With --interactive, walk through each validation issue (checksum mismatch,
+applied migration without a file, failed record) and choose what to do with
+it instead of applying a blanket fix. Requires a terminal.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := loadConfig(); err != nil {
 			return err
@@ -19,9 +24,18 @@ var repairCmd = &cobra.Command{
 
 		recalcChecksums, _ := cmd.Flags().GetBool("recalculate-checksums")
 		removeFailed, _ := cmd.Flags().GetBool("remove-failed")
+		normalizeChecksums, _ := cmd.Flags().GetBool("normalize-checksums")
+		interactive, _ := cmd.Flags().GetBool("interactive")
 
-		if !recalcChecksums && !removeFailed {
-			return fmt.Errorf("specify at least one repair action: --recalculate-checksums or --remove-failed")
+		if interactive {
+			if recalcChecksums || removeFailed || normalizeChecksums {
+				return fmt.Errorf("--interactive cannot be combined with other repair actions")
+			}
+			if !stdinIsTerminal() {
+				return fmt.Errorf("--interactive requires a terminal — use --recalculate-checksums or --remove-failed instead")
+			}
+		} else if !recalcChecksums && !removeFailed && !normalizeChecksums {
+			return fmt.Errorf("specify at least one repair action: --recalculate-checksums, --normalize-checksums or --remove-failed")
 		}
 
 		ctx, err := migration.NewExecutionContext(cfg, log)
@@ -30,10 +44,18 @@ var repairCmd = &cobra.Command{
 		}
 		defer ctx.Close()
 
+		if err := backupBeforeMutation(cmd, ctx.Session); err != nil {
+			return err
+		}
+
+		if interactive {
+			return repairInteractively(ctx)
+		}
+
 		if recalcChecksums {
 			log.Info().Msg("Recalculating checksums for applied migrations...")
 
-			scanned, err := migration.ScanMigrationsDir(cfg.MigrationsDir)
+			scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
 			if err != nil {
 				return err
 			}
@@ -81,6 +103,12 @@ var repairCmd = &cobra.Command{
 			log.Info().Int("updated", updated).Msg("Checksum recalculation complete")
 		}
 
+		if normalizeChecksums {
+			if err := normalizeRecordedChecksums(ctx); err != nil {
+				return err
+			}
+		}
+
 		if removeFailed {
 			log.Info().Msg("Removing failed migration records...")
 
@@ -106,8 +134,176 @@ var repairCmd = &cobra.Command{
 	},
 }
 
+// normalizeRecordedChecksums rewrites recorded checksums to the current
+// checksum_normalization. A record is only updated when it matches the file
+// under some normalization policy, so real content changes still surface as
+// validation errors.
+func normalizeRecordedChecksums(ctx *migration.ExecutionContext) error {
+	log.Info().Msg("Recomputing recorded checksums under checksum_normalization...")
+
+	scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+	if err != nil {
+		return err
+	}
+
+	fileMap := make(map[string]*migration.Migration)
+	for _, mig := range scanned {
+		key := mig.Version
+		switch mig.Type {
+		case migration.TypeRepeatable:
+			key = mig.Version + "_" + mig.Description
+		case migration.TypeUndo:
+			continue
+		}
+		if err := migration.ParseMigrationFile(mig); err != nil {
+			log.Warn().Str("file", mig.Filename).Err(err).Msg("Failed to parse, skipping")
+			continue
+		}
+		fileMap[key] = mig
+	}
+
+	applied, err := ctx.MetadataManager.GetAppliedMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	updated, skipped := 0, 0
+	for _, a := range applied {
+		fileMig, exists := fileMap[a.Version]
+		if !a.Success || !exists || fileMig.Checksum == a.Checksum {
+			continue
+		}
+
+		matches, err := migration.MatchesAnyNormalization(fileMig, a.Checksum)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			log.Warn().Str("version", a.Version).
+				Msg("File content changed beyond whitespace, not updated (use --recalculate-checksums to accept it)")
+			skipped++
+			continue
+		}
+
+		if err := ctx.MetadataManager.UpdateChecksum(a.Version, fileMig.Checksum); err != nil {
+			log.Error().Str("version", a.Version).Err(err).Msg("Failed to update checksum")
+			continue
+		}
+		log.Info().Str("version", a.Version).Str("old", a.Checksum).Str("new", fileMig.Checksum).Msg("Normalized checksum")
+		updated++
+	}
+
+	log.Info().Int("updated", updated).Int("skipped", skipped).Msg("Checksum normalization complete")
+	return nil
+}
+
+// Actions offered by repair --interactive.
+const (
+	repairUpdateChecksum = "update"
+	repairRemoveRecord   = "remove"
+	repairSkip           = "skip"
+	repairAbort          = "abort"
+)
+
+// repairChoices lists the actions that make sense for an issue kind;
+// updating a checksum needs a parseable file to take it from.
+func repairChoices(kind string) []string {
+	if kind == migration.IssueChecksumMismatch {
+		return []string{repairUpdateChecksum, repairRemoveRecord, repairSkip, repairAbort}
+	}
+	return []string{repairRemoveRecord, repairSkip, repairAbort}
+}
+
+// parseRepairChoice matches an answer against choices, accepting the first
+// letter of each action.
+func parseRepairChoice(answer string, choices []string) (string, bool) {
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	for _, c := range choices {
+		if answer == c || (answer != "" && answer == c[:1]) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// repairInteractively prompts for each validation issue and failed record
+// and applies the chosen fix through the same metadata methods as the
+// blanket flags.
+func repairInteractively(ctx *migration.ExecutionContext) error {
+	scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+	if err != nil {
+		return err
+	}
+
+	applied, err := ctx.MetadataManager.GetAppliedMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	issues := migration.NewResolver(scanned).ValidateAppliedChecksumsDetailed(applied)
+	for _, a := range applied {
+		if !a.Success {
+			issues = append(issues, migration.ValidationIssue{
+				Version:          a.Version,
+				Kind:             "failed",
+				RecordedChecksum: a.Checksum,
+				Message:          fmt.Sprintf("V%s (%s) is recorded as failed", a.Version, a.Description),
+			})
+		}
+	}
+
+	if len(issues) == 0 {
+		log.Info().Msg("No validation issues found, nothing to repair")
+		return nil
+	}
+
+	updated, removed, skipped := 0, 0, 0
+	for i, issue := range issues {
+		choices := repairChoices(issue.Kind)
+
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(issues), issue.Message)
+		fmt.Printf("  recorded checksum: %s\n", issue.RecordedChecksum)
+		if issue.CurrentChecksum != "" {
+			fmt.Printf("  file checksum:     %s\n", issue.CurrentChecksum)
+		}
+
+		action, err := choose("Action?", choices, func(response string) (string, bool) {
+			return parseRepairChoice(response, choices)
+		})
+		if err != nil {
+			return fmt.Errorf("repair aborted: %w", err)
+		}
+
+		switch action {
+		case repairUpdateChecksum:
+			if err := ctx.MetadataManager.UpdateChecksum(issue.Version, issue.CurrentChecksum); err != nil {
+				return fmt.Errorf("failed to update checksum for %s: %w", issue.Version, err)
+			}
+			log.Info().Str("version", issue.Version).Str("old", issue.RecordedChecksum).Str("new", issue.CurrentChecksum).Msg("Updated checksum")
+			updated++
+		case repairRemoveRecord:
+			if err := ctx.MetadataManager.RemoveMigration(issue.Version); err != nil {
+				return fmt.Errorf("failed to remove record %s: %w", issue.Version, err)
+			}
+			log.Info().Str("version", issue.Version).Msg("Removed migration record")
+			removed++
+		case repairSkip:
+			skipped++
+		case repairAbort:
+			log.Warn().Int("updated", updated).Int("removed", removed).Msg("Repair aborted by operator")
+			return nil
+		}
+	}
+
+	log.Info().Int("updated", updated).Int("removed", removed).Int("skipped", skipped).Msg("Interactive repair complete")
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(repairCmd)
 	repairCmd.Flags().Bool("recalculate-checksums", false, "recalculate checksums for all applied migrations")
+	repairCmd.Flags().Bool("normalize-checksums", false, "rewrite recorded checksums after a checksum_normalization change (whitespace-only differences)")
 	repairCmd.Flags().Bool("remove-failed", false, "remove failed migration records from metadata")
+	repairCmd.Flags().Bool("interactive", false, "prompt for an action on each validation issue (requires a terminal)")
+	repairCmd.Flags().Bool("no-backup", false, "skip the automatic metadata backup")
 }
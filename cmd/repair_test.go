@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+func TestParseRepairChoice(t *testing.T) {
+	choices := repairChoices(migration.IssueChecksumMismatch)
+
+	action, ok := parseRepairChoice("u\n", choices)
+	assert.True(t, ok)
+	assert.Equal(t, repairUpdateChecksum, action)
+
+	action, ok = parseRepairChoice(" Remove ", choices)
+	assert.True(t, ok)
+	assert.Equal(t, repairRemoveRecord, action)
+
+	_, ok = parseRepairChoice("", choices)
+	assert.False(t, ok)
+
+	// a missing file has nothing to take a new checksum from
+	_, ok = parseRepairChoice("update", repairChoices(migration.IssueMissingFile))
+	assert.False(t, ok)
+}
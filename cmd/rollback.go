@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -25,6 +22,13 @@ var rollbackCmd = &cobra.Command{
 		target, _ := cmd.Flags().GetString("to")
 		steps, _ := cmd.Flags().GetInt("steps")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		reason, _ := cmd.Flags().GetString("reason")
+		all, _ := cmd.Flags().GetBool("all")
+
+		reason = strings.TrimSpace(reason)
+		if reason == "" && cfg.RequireRollbackReason && !dryRun {
+			return fmt.Errorf("a --reason is required for rollback (require_rollback_reason is enabled)")
+		}
 
 		ctx, err := migration.NewExecutionContext(cfg, log)
 		if err != nil {
@@ -47,80 +51,65 @@ var rollbackCmd = &cobra.Command{
 			}()
 		}
 
-		// Get applied migrations (versioned only, sorted by version desc)
 		applied, err := ctx.MetadataManager.GetAppliedMigrations()
 		if err != nil {
 			return fmt.Errorf("failed to get applied migrations: %w", err)
 		}
 
-		var versioned []schema.AppliedMigration
-		for _, a := range applied {
-			if a.Success && a.Type == "versioned" {
-				versioned = append(versioned, a)
-			}
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+		if err != nil {
+			return err
 		}
-		sort.Slice(versioned, func(i, j int) bool {
-			return migration.CompareVersions(versioned[i].Version, versioned[j].Version) > 0 // descending
-		})
-
-		// Determine which migrations to rollback
-		var toRollback []schema.AppliedMigration
-		if target != "" {
-			for _, a := range versioned {
-				if migration.CompareVersions(a.Version, target) > 0 {
-					toRollback = append(toRollback, a)
-				}
-			}
-		} else {
-			if steps <= 0 {
-				steps = 1
-			}
-			if steps > len(versioned) {
-				steps = len(versioned)
+		resolver := migration.NewResolver(scanned)
+
+		switch {
+		case all:
+			target = "0"
+		case target == "":
+			target = resolver.RollbackTarget(applied, steps)
+		}
+
+		// Newest first, with an undo file verified for every step
+		plan, err := resolver.BuildPlan(applied, target, migration.DirectionRollback)
+		if err != nil {
+			if all {
+				return fmt.Errorf("cannot rollback all migrations, nothing was changed: %w", err)
 			}
-			toRollback = versioned[:steps]
+			return err
 		}
 
-		if len(toRollback) == 0 {
+		if len(plan.Steps) == 0 {
 			log.Info().Msg("No migrations to rollback")
 			return nil
 		}
 
-		// Scan migration files to find undo scripts
-		scanned, err := migration.ScanMigrationsDir(cfg.MigrationsDir)
-		if err != nil {
+		if err := checkStatementTypes(cfg, plan.ExecutionOrder()); err != nil {
 			return err
 		}
-		resolver := migration.NewResolver(scanned)
-
-		// Verify undo files exist for all target migrations
-		var undoMigrations []*migration.Migration
-		for _, a := range toRollback {
-			undo := resolver.GetUndoMigration(a.Version)
-			if undo == nil {
-				return fmt.Errorf("no undo migration file found for version %s (%s) — expected U%s__*.cql",
-					a.Version, a.Description, a.Version)
-			}
-			if err := migration.ParseMigrationFile(undo); err != nil {
-				return fmt.Errorf("failed to parse undo migration %s: %w", undo.Filename, err)
-			}
-			undoMigrations = append(undoMigrations, undo)
-		}
 
 		// Confirm
 		if !dryRun {
-			fmt.Printf("\nAbout to rollback %d migration(s):\n", len(toRollback))
-			for _, a := range toRollback {
-				fmt.Printf("  V%s: %s\n", a.Version, a.Description)
+			promptf("\nAbout to rollback %d migration(s):\n", len(plan.Steps))
+			for _, step := range plan.Steps {
+				promptf("  V%s: %s\n", step.Applied.Version, step.Applied.Description)
+			}
+			if reason != "" {
+				promptf("Reason: %s\n", reason)
 			}
-			fmt.Print("\nContinue? [y/N]: ")
-
-			reader := bufio.NewReader(os.Stdin)
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
-				log.Info().Msg("Rollback cancelled")
-				return nil
+			if all {
+				promptf("\nWARNING: This will undo EVERY applied migration in keyspace '%s'!\n", cfg.Keyspace)
+				if err := confirmTyped(fmt.Sprintf("Type the keyspace name '%s' to confirm", cfg.Keyspace), cfg.Keyspace); err != nil {
+					return err
+				}
+			} else {
+				ok, err := confirm("\nContinue?")
+				if err != nil {
+					return err
+				}
+				if !ok {
+					log.Info().Msg("Rollback cancelled")
+					return nil
+				}
 			}
 		}
 
@@ -129,24 +118,30 @@ var rollbackCmd = &cobra.Command{
 		// versioned migration record (don't record undo as a new migration)
 		if dryRun {
 			executor := migration.NewExecutor(ctx)
-			for _, undo := range undoMigrations {
+			for _, undo := range plan.ExecutionOrder() {
 				if err := executor.Execute(undo); err != nil {
 					return err
 				}
 			}
-			log.Info().Int("count", len(toRollback)).Msg("Dry run complete — no changes applied")
+			log.Info().Int("count", len(plan.Steps)).Msg("Dry run complete — no changes applied")
 			return nil
 		}
 
-		for i, undo := range undoMigrations {
+		for _, step := range plan.Steps {
+			undo, rolledBack := step.Migration, step.Applied
 			log.Info().
 				Str("version", undo.Version).
 				Str("description", undo.Description).
+				Str("reason", reason).
 				Msg("Rolling back migration")
 
 			// Execute undo statements directly (don't record in metadata)
 			for j, stmt := range undo.Statements {
-				if err := ctx.Session.Execute(stmt); err != nil {
+				exec := ctx.Session.Execute
+				if migration.IsDDL(stmt) {
+					exec = ctx.Session.ExecuteDDL
+				}
+				if err := exec(stmt); err != nil {
 					return fmt.Errorf("rollback failed at version %s, statement %d: %w", undo.Version, j+1, err)
 				}
 				if migration.IsDDL(stmt) {
@@ -157,14 +152,16 @@ var rollbackCmd = &cobra.Command{
 			}
 
 			// Remove the versioned migration record from metadata
-			if err := ctx.MetadataManager.RemoveMigration(toRollback[i].Version); err != nil {
-				return fmt.Errorf("failed to remove migration record for version %s: %w", toRollback[i].Version, err)
+			if err := ctx.MetadataManager.RemoveMigration(rolledBack.Version); err != nil {
+				return fmt.Errorf("failed to remove migration record for version %s: %w", rolledBack.Version, err)
 			}
 
+			ctx.RecordEvent(schema.EventRolledBack, rolledBack.Version, rolledBack.Description, reason)
+
 			log.Info().Str("version", undo.Version).Msg("Rollback applied")
 		}
 
-		log.Info().Int("count", len(toRollback)).Msg("Rollback completed successfully")
+		log.Info().Int("count", len(plan.Steps)).Msg("Rollback completed successfully")
 		return nil
 	},
 }
@@ -174,4 +171,7 @@ func init() {
 	rollbackCmd.Flags().String("to", "", "target version to rollback to (exclusive)")
 	rollbackCmd.Flags().Int("steps", 1, "number of migrations to rollback")
 	rollbackCmd.Flags().Bool("dry-run", false, "show rollback plan without executing")
+	rollbackCmd.Flags().Bool("all", false, "rollback every applied versioned migration (requires typing the keyspace name)")
+	rollbackCmd.MarkFlagsMutuallyExclusive("all", "to", "steps")
+	rollbackCmd.Flags().String("reason", "", "why the rollback is done; recorded with the operator in the event log")
 }
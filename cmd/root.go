@@ -6,9 +6,11 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
 )
 
 var (
@@ -48,17 +50,44 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ./scylla-migrate.yaml)")
 	rootCmd.PersistentFlags().StringSlice("hosts", nil, "ScyllaDB hosts (comma-separated)")
 	rootCmd.PersistentFlags().String("keyspace", "", "target keyspace")
-	rootCmd.PersistentFlags().String("migrations-dir", "", "migrations directory (default: ./migrations)")
+	rootCmd.PersistentFlags().StringSlice("migrations-dir", nil, "migrations directory, repeatable to merge several (default: ./migrations)")
+	rootCmd.PersistentFlags().String("environment", "", "environment name; suffixes the metadata keyspace and lock id (e.g. staging)")
 	rootCmd.PersistentFlags().String("username", "", "authentication username")
 	rootCmd.PersistentFlags().String("password", "", "authentication password")
 	rootCmd.PersistentFlags().String("log-level", "info", "log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().String("log-format", logFormatConsole, "log format (console, json, jsonl)")
+	rootCmd.PersistentFlags().String("timezone", "", "timezone for displayed times: UTC (default), local or an IANA name")
+	rootCmd.PersistentFlags().String("time-format", "", "format for displayed times (datetime, rfc3339)")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "suppress all non-error output (structured results are still printed)")
+	rootCmd.PersistentFlags().Bool("yes", false, "answer yes to all confirmation prompts, for automation (alias: --assume-yes)")
+
+	_ = rootCmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions(
+		[]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions(
+		[]string{logFormatConsole, logFormatJSON, logFormatJSONL}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.RegisterFlagCompletionFunc("time-format", cobra.FixedCompletions(
+		[]string{"datetime", "rfc3339"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.MarkPersistentFlagFilename("config", "yaml", "yml")
+	_ = rootCmd.MarkPersistentFlagDirname("migrations-dir")
 
 	_ = viper.BindPFlag("hosts", rootCmd.PersistentFlags().Lookup("hosts"))
 	_ = viper.BindPFlag("keyspace", rootCmd.PersistentFlags().Lookup("keyspace"))
 	_ = viper.BindPFlag("migrations_dir", rootCmd.PersistentFlags().Lookup("migrations-dir"))
+	_ = viper.BindPFlag("environment", rootCmd.PersistentFlags().Lookup("environment"))
 	_ = viper.BindPFlag("username", rootCmd.PersistentFlags().Lookup("username"))
 	_ = viper.BindPFlag("password", rootCmd.PersistentFlags().Lookup("password"))
 	_ = viper.BindPFlag("log_level", rootCmd.PersistentFlags().Lookup("log-level"))
+	_ = viper.BindPFlag("log_format", rootCmd.PersistentFlags().Lookup("log-format"))
+	_ = viper.BindPFlag("time_display.timezone", rootCmd.PersistentFlags().Lookup("timezone"))
+	_ = viper.BindPFlag("time_display.format", rootCmd.PersistentFlags().Lookup("time-format"))
+	_ = viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
+
+	rootCmd.SetGlobalNormalizationFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "assume-yes" {
+			name = "yes"
+		}
+		return pflag.NormalizedName(name)
+	})
 
 	rootCmd.SetVersionTemplate(fmt.Sprintf("scylla-migrate %s (commit: %s, built: %s)\n", version, commit, date))
 }
@@ -76,18 +105,28 @@ func initConfig() {
 
 	viper.SetEnvPrefix("SCYLLA_MIGRATE")
 	viper.AutomaticEnv()
-
-	if err := viper.ReadInConfig(); err == nil {
+	// Keys that are usually set per process rather than in the shared config
+	// file must be bound explicitly for Unmarshal to see the env var
+	_ = viper.BindEnv("lock_owner_id")
+	_ = viper.BindEnv("vault.token", "SCYLLA_MIGRATE_VAULT_TOKEN")
+	_ = viper.BindEnv("time_display.timezone", "SCYLLA_MIGRATE_TIME_DISPLAY_TIMEZONE")
+	_ = viper.BindEnv("time_display.format", "SCYLLA_MIGRATE_TIME_DISPLAY_FORMAT")
+
+	if err := viper.ReadInConfig(); err == nil && !isQuiet() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
 
-func initLogger() {
+func initLogger() error {
 	level := viper.GetString("log_level")
 	if level == "" {
 		level = "info"
 	}
 
+	if isQuiet() {
+		level = "error"
+	}
+
 	var l zerolog.Level
 	switch level {
 	case "debug":
@@ -100,14 +139,52 @@ func initLogger() {
 		l = zerolog.InfoLevel
 	}
 
-	log = zerolog.New(zerolog.ConsoleWriter{
-		Out:        os.Stderr,
-		TimeFormat: "15:04:05",
-	}).Level(l).With().Timestamp().Logger()
+	logger, err := newLogger(viper.GetString("log_format"), os.Stderr, l)
+	if err != nil {
+		return err
+	}
+	log = logger
+	return nil
+}
+
+func isQuiet() bool {
+	return viper.GetBool("quiet")
+}
+
+// printInfo writes informational output to stdout unless --quiet is set.
+// Requested results (status tables, JSON output) should use fmt directly.
+func printInfo(format string, a ...interface{}) {
+	if isQuiet() {
+		return
+	}
+	fmt.Printf(format, a...)
 }
 
 func loadConfig() error {
-	initLogger()
+	if err := loadConfigWith((*config.Config).Validate); err != nil {
+		return err
+	}
+
+	// A config file is usually shared by every runner, and runners sharing
+	// an owner id each take the others' lock for their own
+	if cfg.LockOwnerID != "" && viper.InConfig("lock_owner_id") && os.Getenv("SCYLLA_MIGRATE_LOCK_OWNER_ID") == "" {
+		log.Warn().
+			Str("lock_owner_id", cfg.LockOwnerID).
+			Msg("lock_owner_id is set in the config file; every runner using this file shares it and will not be locked out by the others — set SCYLLA_MIGRATE_LOCK_OWNER_ID per runner instead")
+	}
+	return nil
+}
+
+// loadOfflineConfig is loadConfig for commands that never connect to the
+// cluster, so hosts and keyspace need not be configured.
+func loadOfflineConfig() error {
+	return loadConfigWith((*config.Config).ValidateOffline)
+}
+
+func loadConfigWith(validate func(*config.Config) error) error {
+	if err := initLogger(); err != nil {
+		return err
+	}
 
 	var err error
 	cfg, err = config.Load()
@@ -115,9 +192,36 @@ func loadConfig() error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	if err := cfg.Validate(); err != nil {
+	if err := validate(cfg); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	return nil
 }
+
+// newReadOnlyContext connects a read-only command: through status_hosts
+// when configured, reading metadata at read_consistency and never creating
+// the metadata keyspace.
+func newReadOnlyContext() (*migration.ExecutionContext, error) {
+	ctx, err := migration.NewReadOnlyContext(cfg.ForReadOnly(), log)
+	if err != nil {
+		return nil, err
+	}
+	if err := useReadConsistency(ctx); err != nil {
+		ctx.Close()
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// useReadConsistency switches metadata reads to the configured
+// read_consistency. It is meant for read-only commands, where a stale view
+// is acceptable; migrate always reads at the global consistency.
+func useReadConsistency(ctx *migration.ExecutionContext) error {
+	readCL, err := cfg.GetReadConsistency()
+	if err != nil {
+		return err
+	}
+	ctx.MetadataManager.SetReadConsistency(readCL)
+	return nil
+}
@@ -1,14 +1,13 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
 )
 
 var statusCmd = &cobra.Command{
@@ -21,17 +20,27 @@ var statusCmd = &cobra.Command{
 		}
 
 		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "json" && format != "yaml" {
+			return fmt.Errorf("unsupported format %q (use table, json or yaml)", format)
+		}
+
+		out, err := openReport(cmd)
+		if err != nil {
+			return err
+		}
+		defer out.Discard()
 
-		ctx, err := migration.NewExecutionContext(cfg, log)
+		ctx, err := newReadOnlyContext()
 		if err != nil {
 			return err
 		}
 		defer ctx.Close()
 
-		scanned, err := migration.ScanMigrationsDir(cfg.MigrationsDir)
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
 		if err != nil {
 			return err
 		}
+		resolver := migration.NewConfiguredResolver(ctx, scanned)
 
 		applied, err := ctx.MetadataManager.GetAppliedMigrations()
 		if err != nil {
@@ -41,16 +50,19 @@ var statusCmd = &cobra.Command{
 		appliedMap := make(map[string]struct {
 			AppliedAt string
 			Checksum  string
+			Type      string
 			Success   bool
 		})
 		for _, a := range applied {
 			appliedMap[a.Version] = struct {
 				AppliedAt string
 				Checksum  string
+				Type      string
 				Success   bool
 			}{
-				AppliedAt: a.AppliedAt.Format("2006-01-02 15:04:05"),
+				AppliedAt: formatTime(a.AppliedAt),
 				Checksum:  a.Checksum,
+				Type:      a.Type,
 				Success:   a.Success,
 			}
 		}
@@ -61,17 +73,20 @@ var statusCmd = &cobra.Command{
 		}
 
 		type statusEntry struct {
-			Version       string `json:"version"`
-			Description   string `json:"description"`
-			Type          string `json:"type"`
-			Status        string `json:"status"`
-			AppliedAt     string `json:"applied_at"`
-			ChecksumMatch string `json:"checksum_match"`
+			Version       string `json:"version" yaml:"version"`
+			Description   string `json:"description" yaml:"description"`
+			Type          string `json:"type" yaml:"type"`
+			Status        string `json:"status" yaml:"status"`
+			AppliedAt     string `json:"applied_at" yaml:"applied_at"`
+			ChecksumMatch string `json:"checksum_match" yaml:"checksum_match"`
 		}
 
 		var entries []statusEntry
 		appliedCount := 0
 		pendingCount := 0
+		skippedCount := 0
+
+		baseline := migration.BaselineVersion(applied)
 
 		for _, mig := range scanned {
 			entry := statusEntry{
@@ -85,7 +100,12 @@ var statusCmd = &cobra.Command{
 				key = mig.Version + "_" + mig.Description
 			}
 
-			if a, exists := appliedMap[key]; exists {
+			if a, exists := appliedMap[key]; exists && a.Type == schema.RecordTypeBaseline {
+				entry.Status = "Baseline"
+				entry.AppliedAt = a.AppliedAt
+				entry.ChecksumMatch = "-"
+				appliedCount++
+			} else if exists {
 				if a.Success {
 					entry.Status = "Applied"
 					appliedCount++
@@ -93,14 +113,18 @@ var statusCmd = &cobra.Command{
 					entry.Status = "Failed"
 				}
 				entry.AppliedAt = a.AppliedAt
-				if mig.Checksum == a.Checksum {
-					entry.ChecksumMatch = "OK"
-				} else {
-					entry.ChecksumMatch = "MISMATCH"
-				}
+				entry.ChecksumMatch = checksumStatus(resolver, mig, a.Checksum, a.Success)
 			} else {
 				if mig.Type == migration.TypeUndo {
 					entry.Status = "Available"
+				} else if mig.Type == migration.TypeVersioned && baseline != "" && migration.CompareVersions(mig.Version, baseline) <= 0 {
+					// record pruned into the baseline
+					entry.Status = "Baseline"
+					appliedCount++
+				} else if !mig.RunsIn(cfg.Environment) {
+					// Listed environments don't include this one
+					entry.Status = "Skipped"
+					skippedCount++
 				} else {
 					entry.Status = "Pending"
 					pendingCount++
@@ -112,14 +136,15 @@ var statusCmd = &cobra.Command{
 			entries = append(entries, entry)
 		}
 
-		if format == "json" {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(entries)
+		if format != "table" {
+			if err := encodeReport(out, format, entries); err != nil {
+				return err
+			}
+			return out.Commit()
 		}
 
 		// Table format
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 		fmt.Fprintln(w, "VERSION\tDESCRIPTION\tTYPE\tSTATUS\tAPPLIED AT\tCHECKSUM")
 		fmt.Fprintln(w, "-------\t-----------\t----\t------\t----------\t--------")
 
@@ -129,14 +154,35 @@ var statusCmd = &cobra.Command{
 		}
 		w.Flush()
 
-		fmt.Printf("\nTotal: %d | Applied: %d | Pending: %d\n",
-			len(scanned), appliedCount, pendingCount)
+		fmt.Fprintf(out, "\nTotal: %d | Applied: %d | Pending: %d", len(scanned), appliedCount, pendingCount)
+		if skippedCount > 0 {
+			fmt.Fprintf(out, " | Skipped: %d (environment %q)", skippedCount, cfg.Environment)
+		}
+		fmt.Fprintln(out)
 
-		return nil
+		return out.Commit()
 	},
 }
 
+// checksumStatus compares mig with the content applied for it, judged like
+// migrate does: through the repeatable index for repeatables it lists.
+func checksumStatus(resolver *migration.Resolver, mig *migration.Migration, recorded string, success bool) string {
+	switch {
+	case mig.Checksum == resolver.AppliedChecksum(mig, recorded):
+		return "OK"
+	case mig.Type == migration.TypeRepeatable && success &&
+		migration.RepeatableMode(cfg.RepeatableMode) == migration.RepeatableOnce:
+		// The resolver never runs it again, so the edit is not pending
+		return "IGNORED"
+	default:
+		return "MISMATCH"
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
-	statusCmd.Flags().String("format", "table", "output format (table, json)")
+	statusCmd.Flags().String("format", "table", "output format (table, json, yaml)")
+	_ = statusCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"table", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
+	addOutputFileFlag(statusCmd)
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+func TestChecksumStatus_RepeatableIndex(t *testing.T) {
+	orig := cfg
+	cfg = &config.Config{RepeatableMode: string(migration.RepeatableChecksum)}
+	t.Cleanup(func() { cfg = orig })
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "R__views.cql"), []byte("SELECT now() FROM system.local;"), 0644))
+	scanned, err := migration.ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	views := scanned[0]
+	require.NoError(t, migration.ParseMigrationFile(views))
+
+	resolver := migration.NewResolverFor(cfg, scanned)
+	assert.Equal(t, "MISMATCH", checksumStatus(resolver, views, "old", true))
+
+	// A concurrent run applied the current content after the record was
+	// written: migrate sees nothing to do, and so must status
+	resolver.SetRepeatableRuns([]schema.RepeatableRun{
+		{Name: "R_views", Checksum: views.Checksum, Status: schema.RepeatableApplied, AppliedAt: time.Now()},
+	})
+	assert.Equal(t, "OK", checksumStatus(resolver, views, "old", true))
+	pending, err := resolver.GetPendingMigrations([]schema.AppliedMigration{
+		{Version: "R_views", Type: "repeatable", Checksum: "old", Success: true},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
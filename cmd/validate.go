@@ -1,29 +1,60 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/scylla-migrate/scylla-migrate/internal/contract"
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
 	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+	"github.com/scylla-migrate/scylla-migrate/internal/notify"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
 )
 
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate migration checksums",
-	Long:  "Verify that applied migration files have not been modified since they were applied.",
+	Long: `Verify that applied migration files have not been modified since they were applied.
+
+With --daemon, keep running and repeat the check every --interval, optionally
+comparing the live schema with a contract file (--against). Drift is logged
+and sent to the notification webhook when it first appears. The daemon is
+read-only: it never creates, repairs or locks anything.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := loadConfig(); err != nil {
 			return err
 		}
 
-		ctx, err := migration.NewExecutionContext(cfg, log)
+		output, _ := cmd.Flags().GetString("output")
+		if output != "text" && output != "json" {
+			return fmt.Errorf("unsupported output %q (use text or json)", output)
+		}
+
+		if outputFile, _ := cmd.Flags().GetString("output-file"); outputFile != "" && output != "json" {
+			return fmt.Errorf("--output-file requires --output json")
+		}
+
+		if daemon, _ := cmd.Flags().GetBool("daemon"); daemon {
+			interval, _ := cmd.Flags().GetDuration("interval")
+			against, _ := cmd.Flags().GetString("against")
+			return runValidateDaemon(interval, against)
+		}
+
+		ctx, err := newReadOnlyContext()
 		if err != nil {
 			return err
 		}
 		defer ctx.Close()
 
-		scanned, err := migration.ScanMigrationsDir(cfg.MigrationsDir)
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
 		if err != nil {
 			return err
 		}
@@ -34,14 +65,32 @@ var validateCmd = &cobra.Command{
 		}
 
 		resolver := migration.NewResolver(scanned)
-		errors := resolver.ValidateAppliedChecksums(applied)
+		issues := resolver.ValidateAppliedChecksumsDetailed(applied)
 
-		if len(errors) > 0 {
+		if output == "json" {
+			if issues == nil {
+				issues = []migration.ValidationIssue{}
+			}
+			out, err := openReport(cmd)
+			if err != nil {
+				return err
+			}
+			defer out.Discard()
+			if err := encodeReport(out, "json", issues); err != nil {
+				return fmt.Errorf("failed to write validation result: %w", err)
+			}
+			if err := out.Commit(); err != nil {
+				return err
+			}
+		} else if len(issues) > 0 {
 			log.Error().Msg("Validation failed:")
-			for _, e := range errors {
-				log.Error().Msg("  " + e)
+			for _, issue := range issues {
+				log.Error().Msg("  " + issue.Message)
 			}
-			return fmt.Errorf("found %d validation error(s) — run 'scylla-migrate repair --recalculate-checksums' to fix", len(errors))
+		}
+
+		if len(issues) > 0 {
+			return fmt.Errorf("found %d validation error(s) — run 'scylla-migrate repair --recalculate-checksums' to fix", len(issues))
 		}
 
 		log.Info().Int("checked", len(applied)).Msg("All migration checksums are valid")
@@ -49,6 +98,136 @@ var validateCmd = &cobra.Command{
 	},
 }
 
+// driftMonitor holds the state of validate --daemon between checks. The
+// session is dropped after a failed check so the next one reconnects.
+type driftMonitor struct {
+	expected *driver.KeyspaceDump
+	session  *driver.Session
+	notifier *notify.Notifier
+	last     string
+}
+
+func runValidateDaemon(interval time.Duration, against string) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	m := &driftMonitor{notifier: notify.NewNotifier(cfg, log)}
+	if against != "" {
+		content, err := os.ReadFile(against)
+		if err != nil {
+			return fmt.Errorf("failed to read contract: %w", err)
+		}
+		if m.expected, err = contract.Parse(string(content), cfg.Keyspace); err != nil {
+			return fmt.Errorf("invalid contract %s: %w", against, err)
+		}
+	}
+	defer m.close()
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Info().Dur("interval", interval).Str("contract", against).Msg("Watching for drift (read-only)")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.tick()
+		select {
+		case <-sigCtx.Done():
+			log.Info().Msg("Drift monitor stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *driftMonitor) tick() {
+	findings, versions, err := m.check()
+	if err != nil {
+		// Keep watching through transient cluster failures
+		log.Warn().Err(err).Msg("Drift check failed, retrying next interval")
+		m.close()
+		return
+	}
+
+	current := strings.Join(findings, "\n")
+	switch {
+	case current == m.last && len(findings) > 0:
+		log.Debug().Int("issues", len(findings)).Msg("Drift unchanged")
+	case len(findings) > 0:
+		log.Error().Int("issues", len(findings)).Msg("Drift detected:")
+		for _, f := range findings {
+			log.Error().Msg("  " + f)
+		}
+		m.notifier.Send(notify.NewPayload("drift", cfg.Keyspace, versions, 0, errors.New(current)))
+	case m.last != "":
+		log.Info().Msg("Drift resolved — migrations and schema match again")
+	default:
+		log.Debug().Msg("No drift")
+	}
+	m.last = current
+}
+
+// check returns the checksum issues and contract differences, plus the
+// versions involved in checksum issues.
+func (m *driftMonitor) check() ([]string, []string, error) {
+	if m.session == nil {
+		session, err := driver.NewSession(cfg.ForReadOnly(), log)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.session = session
+	}
+
+	readCL, err := cfg.GetReadConsistency()
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := schema.NewMetadataManager(m.session, cfg.MetadataKeyspace, log)
+	metadata.SetReadConsistency(readCL)
+
+	scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+	if err != nil {
+		return nil, nil, err
+	}
+	applied, err := metadata.GetAppliedMigrations()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	var findings, versions []string
+	for _, issue := range migration.NewResolver(scanned).ValidateAppliedChecksumsDetailed(applied) {
+		findings = append(findings, issue.Message)
+		versions = append(versions, issue.Version)
+	}
+
+	if m.expected != nil {
+		actual, err := m.session.DumpKeyspace(cfg.Keyspace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read schema of keyspace %s: %w", cfg.Keyspace, err)
+		}
+		findings = append(findings, contract.Diff(m.expected, actual)...)
+	}
+
+	return findings, versions, nil
+}
+
+func (m *driftMonitor) close() {
+	if m.session != nil {
+		m.session.Close()
+		m.session = nil
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
+	addOutputFileFlag(validateCmd)
+	validateCmd.Flags().Bool("daemon", false, "keep running and report checksum/schema drift every --interval (read-only)")
+	validateCmd.Flags().Duration("interval", 5*time.Minute, "time between checks with --daemon")
+	validateCmd.Flags().String("against", "", "with --daemon, also compare the live schema with this contract .cql file")
+	validateCmd.Flags().String("output", "text", "output format (text, json)")
+	_ = validateCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
 }
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+var verifyImmutableCmd = &cobra.Command{
+	Use:   "verify-immutable",
+	Short: "Check that released migrations were only appended to",
+	Long: `Compare the migration files with a baseline and fail if a versioned migration
+that existed in the baseline was changed or removed. New files are fine.
+
+The baseline is either a manifest file (migrations.lock format, e.g. the one
+from the last release) or a git ref such as a release tag, in which case the
+migration files are read from that commit. The cluster is never contacted,
+so this can run in CI on every pull request.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadOfflineConfig(); err != nil {
+			return err
+		}
+
+		baselineRef, _ := cmd.Flags().GetString("baseline")
+		if baselineRef == "" {
+			return fmt.Errorf("--baseline is required (a manifest file or a git ref)")
+		}
+
+		baseline, err := loadBaselineManifest(baselineRef, cfg.MigrationsDirs)
+		if err != nil {
+			return err
+		}
+
+		scanned, err := migration.ScanMigrationsDirs(cfg.MigrationsDirs, migration.ParseOptionsFor(cfg))
+		if err != nil {
+			return err
+		}
+		current, err := migration.BuildManifest(scanned)
+		if err != nil {
+			return err
+		}
+
+		diff := migration.CompareAppendOnly(baseline, current)
+		for _, f := range diff.Added {
+			log.Info().Str("file", f).Msg("New migration")
+		}
+		for _, f := range diff.Changed {
+			log.Error().Str("file", f).Msg("Released migration was modified")
+		}
+		for _, f := range diff.Removed {
+			log.Error().Str("file", f).Msg("Released migration was removed")
+		}
+
+		if n := len(diff.Changed) + len(diff.Removed); n > 0 {
+			return fmt.Errorf("%d migration(s) from %s were modified or removed — migrations are append-only, add a new migration instead", n, baselineRef)
+		}
+		log.Info().Int("baseline", len(baseline)).Int("added", len(diff.Added)).Str("baseline_ref", baselineRef).
+			Msg("Migrations are append-only")
+		return nil
+	},
+}
+
+// loadBaselineManifest reads ref as a manifest file if one exists at that
+// path, and otherwise as a git ref.
+func loadBaselineManifest(ref string, dirs []string) ([]migration.ManifestEntry, error) {
+	if fi, err := os.Stat(ref); err == nil && fi.Mode().IsRegular() {
+		entries, err := migration.ReadManifest(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read baseline manifest: %w", err)
+		}
+		return entries, nil
+	}
+	return manifestFromGit(ref, dirs)
+}
+
+// manifestFromGit builds a manifest of the migration files in dirs as they
+// were at ref. Directories that did not exist at ref contribute nothing.
+func manifestFromGit(ref string, dirs []string) ([]migration.ManifestEntry, error) {
+	var entries []migration.ManifestEntry
+	for _, dir := range dirs {
+		if _, err := git(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return nil, fmt.Errorf("baseline %q is neither a manifest file nor a git ref", ref)
+		}
+
+		out, err := git(dir, "ls-tree", "--name-only", ref, "--", ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s at %s: %w", dir, ref, err)
+		}
+		for _, name := range strings.Split(strings.TrimSpace(out), "\n") {
+			name = path.Base(name)
+			if name == "" || !strings.HasSuffix(name, ".cql") {
+				continue
+			}
+			content, err := git(dir, "show", ref+":./"+name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s at %s: %w", name, ref, err)
+			}
+			mig, err := migration.ParseWith(name, content, migration.ParseOptionsFor(cfg))
+			if err != nil {
+				// not a migration file name, or unparseable then as now
+				log.Debug().Str("file", name).Err(err).Msg("Skipping baseline file")
+				continue
+			}
+			entries = append(entries, migration.ManifestEntry{Filename: name, Checksum: mig.Checksum})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Filename < entries[j].Filename })
+	return entries, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	c := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyImmutableCmd)
+	verifyImmutableCmd.Flags().String("baseline", "", "manifest file or git ref (e.g. v1.4.0) to compare against")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/contract"
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+)
+
+var verifySchemaCmd = &cobra.Command{
+	Use:   "verify-schema",
+	Short: "Compare the live keyspace schema with a contract file",
+	Long: `Read the tables, user-defined types and indexes of the configured keyspace
+from system_schema and compare them with a contract: a checked-in .cql file of
+CREATE TABLE, CREATE TYPE and CREATE INDEX statements describing the intended
+end state. Every discrepancy is printed and the command exits non-zero.
+
+Column types, primary key layout, clustering order and static columns are
+compared; table options (compaction, TTL, ...) are not.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(); err != nil {
+			return err
+		}
+
+		against, _ := cmd.Flags().GetString("against")
+		if against == "" {
+			return fmt.Errorf("--against is required")
+		}
+
+		content, err := os.ReadFile(against)
+		if err != nil {
+			return fmt.Errorf("failed to read contract: %w", err)
+		}
+		expected, err := contract.Parse(string(content), cfg.Keyspace)
+		if err != nil {
+			return fmt.Errorf("invalid contract %s: %w", against, err)
+		}
+
+		session, err := driver.NewSession(cfg.ForReadOnly(), log)
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+
+		actual, err := session.DumpKeyspace(cfg.Keyspace)
+		if err != nil {
+			return fmt.Errorf("failed to read schema of keyspace %s: %w", cfg.Keyspace, err)
+		}
+
+		diff := contract.Diff(expected, actual)
+		if len(diff) == 0 {
+			log.Info().Str("keyspace", cfg.Keyspace).Str("contract", against).Msg("Schema matches contract")
+			return nil
+		}
+
+		for _, d := range diff {
+			fmt.Println(d)
+		}
+		return fmt.Errorf("schema of keyspace %s differs from %s in %d place(s)", cfg.Keyspace, against, len(diff))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifySchemaCmd)
+	verifySchemaCmd.Flags().String("against", "", "contract .cql file describing the expected schema")
+}
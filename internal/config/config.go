@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -11,22 +15,124 @@ import (
 
 var validIdentifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
 
+var validEnvironment = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// maxKeyspaceNameLength is the longest keyspace name Scylla accepts.
+const maxKeyspaceNameLength = 48
+
 type Config struct {
-	Hosts                  []string          `mapstructure:"hosts" yaml:"hosts"`
-	Keyspace               string            `mapstructure:"keyspace" yaml:"keyspace"`
-	MigrationsDir          string            `mapstructure:"migrations_dir" yaml:"migrations_dir"`
-	Username               string            `mapstructure:"username" yaml:"username"`
-	Password               string            `mapstructure:"password" yaml:"password"`
-	SSL                    SSLConfig         `mapstructure:"ssl" yaml:"ssl"`
-	Consistency            string            `mapstructure:"consistency" yaml:"consistency"`
-	Timeout                time.Duration     `mapstructure:"timeout" yaml:"timeout"`
-	ConnectionTimeout      time.Duration     `mapstructure:"connection_timeout" yaml:"connection_timeout"`
-	LockTimeout            time.Duration     `mapstructure:"lock_timeout" yaml:"lock_timeout"`
-	SchemaAgreementTimeout time.Duration     `mapstructure:"schema_agreement_timeout" yaml:"schema_agreement_timeout"`
-	MetadataKeyspace       string            `mapstructure:"metadata_keyspace" yaml:"metadata_keyspace"`
-	MetadataReplication    ReplicationConfig `mapstructure:"metadata_replication" yaml:"metadata_replication"`
-	MaxRetries             int               `mapstructure:"max_retries" yaml:"max_retries"`
-	ProtocolVersion        int               `mapstructure:"protocol_version" yaml:"protocol_version"`
+	Hosts                   []string              `mapstructure:"hosts" yaml:"hosts"`
+	StatusHosts             []string              `mapstructure:"status_hosts" yaml:"status_hosts"`
+	Keyspace                string                `mapstructure:"keyspace" yaml:"keyspace"`
+	BindKeyspace            bool                  `mapstructure:"-" yaml:"-"`
+	MigrationsDirs          []string              `mapstructure:"migrations_dir" yaml:"migrations_dir"`
+	Username                string                `mapstructure:"username" yaml:"username"`
+	Password                string                `mapstructure:"password" yaml:"password"`
+	SSL                     SSLConfig             `mapstructure:"ssl" yaml:"ssl"`
+	Consistency             string                `mapstructure:"consistency" yaml:"consistency"`
+	ReadConsistency         string                `mapstructure:"read_consistency" yaml:"read_consistency"`
+	Timeout                 time.Duration         `mapstructure:"timeout" yaml:"timeout"`
+	ConnectionTimeout       time.Duration         `mapstructure:"connection_timeout" yaml:"connection_timeout"`
+	LockTimeout             time.Duration         `mapstructure:"lock_timeout" yaml:"lock_timeout"`
+	LockOwnerID             string                `mapstructure:"lock_owner_id" yaml:"lock_owner_id"`
+	SchemaAgreementTimeout  time.Duration         `mapstructure:"schema_agreement_timeout" yaml:"schema_agreement_timeout"`
+	DDLCoordinator          string                `mapstructure:"ddl_coordinator" yaml:"ddl_coordinator"`
+	StatementTimeout        time.Duration         `mapstructure:"statement_timeout" yaml:"statement_timeout"`
+	MetadataKeyspace        string                `mapstructure:"metadata_keyspace" yaml:"metadata_keyspace"`
+	Environment             string                `mapstructure:"environment" yaml:"environment"`
+	ProductionEnvironments  []string              `mapstructure:"production_environments" yaml:"production_environments"`
+	MetadataReplication     ReplicationConfig     `mapstructure:"metadata_replication" yaml:"metadata_replication"`
+	MaxRetries              int                   `mapstructure:"max_retries" yaml:"max_retries"`
+	ProtocolVersion         int                   `mapstructure:"protocol_version" yaml:"protocol_version"`
+	Notify                  NotifyConfig          `mapstructure:"notify" yaml:"notify"`
+	ConfirmDestructive      bool                  `mapstructure:"confirm_destructive" yaml:"confirm_destructive"`
+	SkipExistingObjects     bool                  `mapstructure:"skip_existing_objects" yaml:"skip_existing_objects"`
+	OutOfOrder              string                `mapstructure:"out_of_order" yaml:"out_of_order"`
+	RepeatableMode          string                `mapstructure:"repeatable_mode" yaml:"repeatable_mode"`
+	RequireRollbackReason   bool                  `mapstructure:"require_rollback_reason" yaml:"require_rollback_reason"`
+	SafeRecord              bool                  `mapstructure:"safe_record" yaml:"safe_record"`
+	StoreScriptContent      bool                  `mapstructure:"store_script_content" yaml:"store_script_content"`
+	CrossMigrationPhasing   bool                  `mapstructure:"cross_migration_phasing" yaml:"cross_migration_phasing"`
+	AllowLocalModifications bool                  `mapstructure:"allow_local_modifications" yaml:"allow_local_modifications"`
+	WarnVersionGaps         bool                  `mapstructure:"warn_version_gaps" yaml:"warn_version_gaps"`
+	AllowedStatementTypes   []string              `mapstructure:"allowed_statement_types" yaml:"allowed_statement_types"`
+	ChecksumNormalization   ChecksumNormalization `mapstructure:"checksum_normalization" yaml:"checksum_normalization"`
+	LockStrategy            string                `mapstructure:"lock_strategy" yaml:"lock_strategy"`
+	SpeculativeExecution    SpeculativeConfig     `mapstructure:"speculative_execution" yaml:"speculative_execution"`
+	SessionPreamble         []string              `mapstructure:"session_preamble" yaml:"session_preamble"`
+	SessionEpilogue         []string              `mapstructure:"session_epilogue" yaml:"session_epilogue"`
+	MaxMigrationFileSize    int64                 `mapstructure:"max_migration_file_size" yaml:"max_migration_file_size"`
+	StatementSeparator      string                `mapstructure:"statement_separator" yaml:"statement_separator"`
+	Lint                    LintConfig            `mapstructure:"lint" yaml:"lint"`
+	CircuitBreaker          BreakerConfig         `mapstructure:"circuit_breaker" yaml:"circuit_breaker"`
+	ReadinessQuery          ReadinessConfig       `mapstructure:"readiness_query" yaml:"readiness_query"`
+	Vault                   VaultConfig           `mapstructure:"vault" yaml:"vault"`
+	QueryLog                QueryLogConfig        `mapstructure:"query_log" yaml:"query_log"`
+	TimeDisplay             TimeDisplayConfig     `mapstructure:"time_display" yaml:"time_display"`
+
+	// CredentialSource, when set, is used instead of Vault by
+	// ResolveCredentials. It is only set programmatically.
+	CredentialSource    CredentialSource `mapstructure:"-" yaml:"-"`
+	credentialsResolved bool
+
+	// Observers are gocql driver hooks set by library users; they take
+	// precedence over query_log.
+	Observers DriverObservers `mapstructure:"-" yaml:"-"`
+}
+
+// QueryLogConfig logs every query attempt the driver makes, with its host,
+// attempt number and latency, at debug level. Failed attempts and those
+// slower than SlowThreshold are logged as warnings.
+type QueryLogConfig struct {
+	Enabled       bool          `mapstructure:"enabled" yaml:"enabled"`
+	SlowThreshold time.Duration `mapstructure:"slow_threshold" yaml:"slow_threshold"`
+}
+
+// Time display formats for TimeDisplayConfig.Format.
+const (
+	TimeFormatDateTime = "datetime"
+	TimeFormatRFC3339  = "rfc3339"
+)
+
+// TimeDisplayConfig controls how stored timestamps (applied_at, event
+// times) are shown. Storage is unaffected.
+type TimeDisplayConfig struct {
+	// Timezone is "UTC" (default), "local" or an IANA name such as
+	// "Europe/Berlin"
+	Timezone string `mapstructure:"timezone" yaml:"timezone"`
+	// Format is "datetime" (default, seconds and zone abbreviation) or
+	// "rfc3339" (milliseconds and UTC offset)
+	Format string `mapstructure:"format" yaml:"format"`
+}
+
+func (t TimeDisplayConfig) Location() (*time.Location, error) {
+	switch strings.ToLower(t.Timezone) {
+	case "", "utc":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_display.timezone %q: %w", t.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Layout returns the time layout for Format.
+func (t TimeDisplayConfig) Layout() string {
+	if strings.EqualFold(t.Format, TimeFormatRFC3339) {
+		return "2006-01-02T15:04:05.000Z07:00"
+	}
+	return "2006-01-02 15:04:05 MST"
+}
+
+// DriverObservers are passed to the gocql cluster config as is. Nil fields
+// leave the corresponding hook unset.
+type DriverObservers struct {
+	Query       gocql.QueryObserver
+	Connect     gocql.ConnectObserver
+	FrameHeader gocql.FrameHeaderObserver
 }
 
 type SSLConfig struct {
@@ -37,28 +143,144 @@ type SSLConfig struct {
 	SkipVerify bool   `mapstructure:"skip_verify" yaml:"skip_verify"`
 }
 
+type NotifyConfig struct {
+	WebhookURL string        `mapstructure:"webhook_url" yaml:"webhook_url"`
+	Timeout    time.Duration `mapstructure:"timeout" yaml:"timeout"`
+}
+
+// SpeculativeConfig controls speculative execution for metadata reads.
+// It is never applied to writes, DDL, or LWT queries.
+type SpeculativeConfig struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled"`
+	Attempts int           `mapstructure:"attempts" yaml:"attempts"`
+	Delay    time.Duration `mapstructure:"delay" yaml:"delay"`
+}
+
+// BreakerConfig controls the run-level circuit breaker around statement
+// execution. After FailureThreshold consecutive cluster failures the breaker
+// opens and waits Cooldown before the next attempt; if it is still open after
+// MaxOpen the run is aborted.
+type BreakerConfig struct {
+	Enabled          bool          `mapstructure:"enabled" yaml:"enabled"`
+	FailureThreshold int           `mapstructure:"failure_threshold" yaml:"failure_threshold"`
+	Cooldown         time.Duration `mapstructure:"cooldown" yaml:"cooldown"`
+	MaxOpen          time.Duration `mapstructure:"max_open" yaml:"max_open"`
+}
+
+// ReadinessConfig gates migrate on an application-specific CQL check, run
+// before the lock is acquired. The cluster is ready when Query returns a row
+// and, if Expect is set, the first column of that row equals Expect. Failed
+// checks are retried every Interval until Timeout.
+type ReadinessConfig struct {
+	Query    string        `mapstructure:"query" yaml:"query"`
+	Expect   string        `mapstructure:"expect" yaml:"expect"`
+	Timeout  time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval"`
+}
+
+type LintConfig struct {
+	Names                 NameRules        `mapstructure:"names" yaml:"names"`
+	RepeatableIdempotency IdempotencyRules `mapstructure:"repeatable_idempotency" yaml:"repeatable_idempotency"`
+	Keyspaces             KeyspaceRules    `mapstructure:"keyspaces" yaml:"keyspaces"`
+}
+
+// KeyspaceRules lists the keyspaces migrations may reference besides
+// keyspace, metadata_keyspace and the system keyspaces.
+type KeyspaceRules struct {
+	Allowed []string `mapstructure:"allowed" yaml:"allowed"`
+}
+
+// IdempotencyRules selects the repeatable idempotency checks to run; an
+// empty list runs all of them.
+type IdempotencyRules struct {
+	Rules []string `mapstructure:"rules" yaml:"rules"`
+}
+
+// NameRules constrain the description part of migration filenames, checked
+// in humanized form (underscores shown as spaces).
+type NameRules struct {
+	Pattern          string   `mapstructure:"pattern" yaml:"pattern"`
+	MaxLength        int      `mapstructure:"max_length" yaml:"max_length"`
+	RequiredPrefixes []string `mapstructure:"required_prefixes" yaml:"required_prefixes"`
+}
+
+// DefaultNameRules match what "create" generates: lowercase words made of
+// letters and digits, separated by single spaces.
+func DefaultNameRules() NameRules {
+	return NameRules{
+		Pattern:   `^[a-z0-9]+( [a-z0-9]+)*$`,
+		MaxLength: 80,
+	}
+}
+
 type ReplicationConfig struct {
 	Class             string         `mapstructure:"class" yaml:"class"`
 	ReplicationFactor int            `mapstructure:"replication_factor" yaml:"replication_factor"`
 	Datacenters       map[string]int `mapstructure:"datacenters" yaml:"datacenters"`
 }
 
+// ChecksumNormalization controls how migration content is normalized before
+// it is hashed. Changing it requires a one-time 'repair --normalize-checksums'.
+type ChecksumNormalization struct {
+	NormalizeLineEndings   bool `mapstructure:"normalize_line_endings" yaml:"normalize_line_endings"`
+	TrimTrailingWhitespace bool `mapstructure:"trim_trailing_whitespace" yaml:"trim_trailing_whitespace"`
+	CollapseBlankLines     bool `mapstructure:"collapse_blank_lines" yaml:"collapse_blank_lines"`
+}
+
+// DefaultChecksumNormalization only treats CRLF as LF, which is how
+// checksums have always been computed.
+func DefaultChecksumNormalization() ChecksumNormalization {
+	return ChecksumNormalization{NormalizeLineEndings: true}
+}
+
 func Load() (*Config, error) {
 	cfg := &Config{
 		Hosts:                  []string{"localhost:9042"},
-		MigrationsDir:          "./migrations",
+		MigrationsDirs:         []string{"./migrations"},
 		Consistency:            "quorum",
 		Timeout:                30 * time.Second,
 		ConnectionTimeout:      10 * time.Second,
 		LockTimeout:            60 * time.Second,
 		SchemaAgreementTimeout: 30 * time.Second,
 		MetadataKeyspace:       "scylla_migrate",
+		ProductionEnvironments: []string{"prod", "production"},
 		MetadataReplication: ReplicationConfig{
 			Class:             "SimpleStrategy",
 			ReplicationFactor: 1,
 		},
-		MaxRetries:      3,
-		ProtocolVersion: 4,
+		MaxRetries:         3,
+		ProtocolVersion:    4,
+		OutOfOrder:         "fail",
+		RepeatableMode:     "checksum",
+		LockStrategy:       "lwt",
+		StatementSeparator: ";",
+		Notify: NotifyConfig{
+			Timeout: 5 * time.Second,
+		},
+		SpeculativeExecution: SpeculativeConfig{
+			Attempts: 2,
+			Delay:    100 * time.Millisecond,
+		},
+		Lint: LintConfig{
+			Names: DefaultNameRules(),
+		},
+		ChecksumNormalization: DefaultChecksumNormalization(),
+		CircuitBreaker: BreakerConfig{
+			FailureThreshold: 5,
+			Cooldown:         5 * time.Second,
+			MaxOpen:          time.Minute,
+		},
+		ReadinessQuery: ReadinessConfig{
+			Timeout:  5 * time.Minute,
+			Interval: 10 * time.Second,
+		},
+		QueryLog: QueryLogConfig{
+			SlowThreshold: time.Second,
+		},
+		TimeDisplay: TimeDisplayConfig{
+			Timezone: "UTC",
+			Format:   TimeFormatDateTime,
+		},
 	}
 
 	if err := viper.Unmarshal(cfg); err != nil {
@@ -69,11 +291,14 @@ func Load() (*Config, error) {
 	if hosts := viper.GetStringSlice("hosts"); len(hosts) > 0 {
 		cfg.Hosts = hosts
 	}
+	if hosts := viper.GetStringSlice("status_hosts"); len(hosts) > 0 {
+		cfg.StatusHosts = hosts
+	}
 	if ks := viper.GetString("keyspace"); ks != "" {
 		cfg.Keyspace = ks
 	}
-	if dir := viper.GetString("migrations_dir"); dir != "" {
-		cfg.MigrationsDir = dir
+	if dirs := stringOrSlice("migrations_dir"); len(dirs) > 0 {
+		cfg.MigrationsDirs = dirs
 	}
 	if u := viper.GetString("username"); u != "" {
 		cfg.Username = u
@@ -81,14 +306,112 @@ func Load() (*Config, error) {
 	if p := viper.GetString("password"); p != "" {
 		cfg.Password = p
 	}
+	if env := viper.GetString("environment"); env != "" {
+		cfg.Environment = env
+	}
+
+	cfg.ApplyEnvironment()
 
 	return cfg, nil
 }
 
+// ApplyEnvironment suffixes the metadata keyspace with the environment, so
+// environments sharing a cluster keep separate histories. It is a no-op
+// without an environment or when the suffix is already present.
+func (c *Config) ApplyEnvironment() {
+	if c.Environment == "" {
+		return
+	}
+	suffix := "_" + strings.ToLower(c.Environment)
+	if !strings.HasSuffix(c.MetadataKeyspace, suffix) {
+		c.MetadataKeyspace += suffix
+	}
+}
+
+// IsProduction reports whether the configured environment is listed in
+// production_environments.
+func (c *Config) IsProduction() bool {
+	for _, env := range c.ProductionEnvironments {
+		if c.Environment != "" && strings.EqualFold(env, c.Environment) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateHosts checks each entry is a host, optionally with a port: a
+// hostname, an IP address, host:port or [ipv6]:port.
+func validateHosts(key string, hosts []string) error {
+	for _, h := range hosts {
+		if err := validateHost(strings.TrimSpace(h)); err != nil {
+			return fmt.Errorf("%s entry %q: %w", key, h, err)
+		}
+	}
+	return nil
+}
+
+func validateHost(h string) error {
+	if h == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	host, port, err := net.SplitHostPort(h)
+	if err != nil {
+		// No port: a bare IPv6 address has several colons, anything else none
+		if strings.Count(h, ":") == 1 || strings.ContainsAny(h, "[]") {
+			return fmt.Errorf("invalid host: %w", err)
+		}
+		return nil
+	}
+	if host == "" {
+		return fmt.Errorf("missing host before the port")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("port %q must be a number between 1 and 65535", port)
+	}
+	return nil
+}
+
+// rejectUse refuses USE statements in session hooks: gocql rejects them, and
+// they would only affect one pooled connection anyway.
+func rejectUse(key string, stmts []string) error {
+	for i, stmt := range stmts {
+		if fields := strings.Fields(stmt); len(fields) > 0 && strings.EqualFold(fields[0], "USE") {
+			return fmt.Errorf("%s statement %d: USE is not supported — qualify table names with the keyspace instead", key, i+1)
+		}
+	}
+	return nil
+}
+
+// ForReadOnly returns the config read-only commands connect with: the same
+// settings, with status_hosts in place of hosts when set, so monitoring can
+// read from other nodes than the ones migrate writes through.
+func (c *Config) ForReadOnly() *Config {
+	if len(c.StatusHosts) == 0 {
+		return c
+	}
+	ro := *c
+	ro.Hosts = c.StatusHosts
+	return &ro
+}
+
 func (c *Config) Validate() error {
+	if err := c.validateCluster(); err != nil {
+		return err
+	}
+	return c.ValidateOffline()
+}
+
+// validateCluster checks the settings needed to connect: hosts and keyspace.
+func (c *Config) validateCluster() error {
 	if len(c.Hosts) == 0 {
 		return fmt.Errorf("at least one host must be specified")
 	}
+	if err := validateHosts("hosts", c.Hosts); err != nil {
+		return err
+	}
+	if err := validateHosts("status_hosts", c.StatusHosts); err != nil {
+		return err
+	}
 
 	if c.Keyspace == "" {
 		return fmt.Errorf("keyspace must be specified")
@@ -96,17 +419,42 @@ func (c *Config) Validate() error {
 	if !validIdentifier.MatchString(c.Keyspace) {
 		return fmt.Errorf("keyspace name %q contains invalid characters (must be alphanumeric/underscore, starting with a letter)", c.Keyspace)
 	}
+	return nil
+}
 
-	if c.MigrationsDir == "" {
+// ValidateOffline is Validate without the cluster connection settings, for
+// commands that only read the migration files.
+func (c *Config) ValidateOffline() error {
+	if len(c.MigrationsDirs) == 0 {
 		return fmt.Errorf("migrations_dir must be specified")
 	}
+	for _, dir := range c.MigrationsDirs {
+		if dir == "" {
+			return fmt.Errorf("migrations_dir entries must not be empty")
+		}
+	}
 
 	if c.MetadataKeyspace == "" {
 		return fmt.Errorf("metadata_keyspace must be specified")
 	}
+	if c.Environment != "" && !validEnvironment.MatchString(c.Environment) {
+		return fmt.Errorf("environment %q contains invalid characters (must be alphanumeric/underscore)", c.Environment)
+	}
+	if c.AllowLocalModifications {
+		// Fail closed: a cluster that never set environment may be production
+		if c.Environment == "" {
+			return fmt.Errorf("allow_local_modifications requires an explicit non-production environment")
+		}
+		if c.IsProduction() {
+			return fmt.Errorf("allow_local_modifications cannot be enabled in production environment %q", c.Environment)
+		}
+	}
 	if !validIdentifier.MatchString(c.MetadataKeyspace) {
 		return fmt.Errorf("metadata_keyspace name %q contains invalid characters", c.MetadataKeyspace)
 	}
+	if len(c.MetadataKeyspace) > maxKeyspaceNameLength {
+		return fmt.Errorf("metadata_keyspace name %q is longer than %d characters", c.MetadataKeyspace, maxKeyspaceNameLength)
+	}
 
 	if c.Timeout <= 0 {
 		return fmt.Errorf("timeout must be positive")
@@ -128,6 +476,114 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if _, err := c.GetReadConsistency(); err != nil {
+		return fmt.Errorf("read_consistency: %w", err)
+	}
+
+	if c.StatementSeparator != "" {
+		if strings.ContainsAny(c.StatementSeparator, "'\" \t\r\n") ||
+			strings.Contains(c.StatementSeparator, "--") || strings.Contains(c.StatementSeparator, "/*") {
+			return fmt.Errorf("statement_separator %q must not contain quotes, whitespace or comment markers", c.StatementSeparator)
+		}
+	}
+
+	if c.Notify.WebhookURL != "" {
+		u, err := url.Parse(c.Notify.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notify.webhook_url must be a valid http(s) URL")
+		}
+		if c.Notify.Timeout <= 0 {
+			return fmt.Errorf("notify.timeout must be positive")
+		}
+	}
+
+	if c.SpeculativeExecution.Enabled {
+		if c.SpeculativeExecution.Attempts < 1 {
+			return fmt.Errorf("speculative_execution.attempts must be at least 1")
+		}
+		if c.SpeculativeExecution.Delay <= 0 {
+			return fmt.Errorf("speculative_execution.delay must be positive")
+		}
+	}
+
+	if c.StatementTimeout < 0 {
+		return fmt.Errorf("statement_timeout must not be negative")
+	}
+
+	switch c.LockStrategy {
+	case "", "lwt", "advisory", "none":
+	default:
+		return fmt.Errorf("lock_strategy must be one of lwt, advisory, none (got %q)", c.LockStrategy)
+	}
+
+	switch c.OutOfOrder {
+	case "", "fail", "warn-and-apply", "ignore":
+	default:
+		return fmt.Errorf("out_of_order must be one of fail, warn-and-apply, ignore (got %q)", c.OutOfOrder)
+	}
+
+	for _, t := range c.AllowedStatementTypes {
+		switch strings.ToLower(t) {
+		case "ddl", "dml":
+		default:
+			return fmt.Errorf("allowed_statement_types entries must be ddl or dml (got %q)", t)
+		}
+	}
+
+	switch c.RepeatableMode {
+	case "", "checksum", "once":
+	default:
+		return fmt.Errorf("repeatable_mode must be one of checksum, once (got %q)", c.RepeatableMode)
+	}
+
+	if c.MaxMigrationFileSize < 0 {
+		return fmt.Errorf("max_migration_file_size must not be negative")
+	}
+
+	if c.CircuitBreaker.Enabled {
+		if c.CircuitBreaker.FailureThreshold < 1 {
+			return fmt.Errorf("circuit_breaker.failure_threshold must be at least 1")
+		}
+		if c.CircuitBreaker.Cooldown <= 0 {
+			return fmt.Errorf("circuit_breaker.cooldown must be positive")
+		}
+		if c.CircuitBreaker.MaxOpen < c.CircuitBreaker.Cooldown {
+			return fmt.Errorf("circuit_breaker.max_open must be at least circuit_breaker.cooldown")
+		}
+	}
+
+	switch strings.ToLower(c.TimeDisplay.Format) {
+	case "", TimeFormatDateTime, TimeFormatRFC3339:
+	default:
+		return fmt.Errorf("invalid time_display.format %q (use %s or %s)", c.TimeDisplay.Format, TimeFormatDateTime, TimeFormatRFC3339)
+	}
+	if _, err := c.TimeDisplay.Location(); err != nil {
+		return err
+	}
+
+	if c.QueryLog.SlowThreshold < 0 {
+		return fmt.Errorf("query_log.slow_threshold must not be negative")
+	}
+
+	if err := rejectUse("session_preamble", c.SessionPreamble); err != nil {
+		return err
+	}
+	if err := rejectUse("session_epilogue", c.SessionEpilogue); err != nil {
+		return err
+	}
+
+	if rq := c.ReadinessQuery; rq.Query != "" {
+		if fields := strings.Fields(rq.Query); len(fields) == 0 || !strings.EqualFold(fields[0], "SELECT") {
+			return fmt.Errorf("readiness_query.query must be a SELECT statement")
+		}
+		if rq.Timeout <= 0 {
+			return fmt.Errorf("readiness_query.timeout must be positive")
+		}
+		if rq.Interval <= 0 {
+			return fmt.Errorf("readiness_query.interval must be positive")
+		}
+	}
+
 	if c.SSL.Enabled {
 		if c.SSL.CACert == "" {
 			return fmt.Errorf("ssl.ca_cert must be specified when SSL is enabled")
@@ -141,8 +597,47 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// PrimaryMigrationsDir is the directory new migration files are written to
+// when several migrations directories are configured.
+func (c *Config) PrimaryMigrationsDir() string {
+	if len(c.MigrationsDirs) == 0 {
+		return ""
+	}
+	return c.MigrationsDirs[0]
+}
+
+// stringOrSlice reads a key that may be set either as a single string or as
+// a list. A plain string is kept whole rather than split on whitespace.
+func stringOrSlice(key string) []string {
+	switch v := viper.Get(key).(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	default:
+		return viper.GetStringSlice(key)
+	}
+}
+
 func (c *Config) GetConsistency() (gocql.Consistency, error) {
-	switch c.Consistency {
+	return parseConsistency(c.Consistency)
+}
+
+// GetReadConsistency returns the consistency used by read-only commands
+// (status, validate) when reading migration metadata. It falls back to the
+// global consistency when read_consistency is not set.
+func (c *Config) GetReadConsistency() (gocql.Consistency, error) {
+	if c.ReadConsistency == "" {
+		return c.GetConsistency()
+	}
+	return parseConsistency(c.ReadConsistency)
+}
+
+func parseConsistency(level string) (gocql.Consistency, error) {
+	switch level {
 	case "any":
 		return gocql.Any, nil
 	case "one":
@@ -162,8 +657,26 @@ func (c *Config) GetConsistency() (gocql.Consistency, error) {
 	case "local_one":
 		return gocql.LocalOne, nil
 	default:
-		return 0, fmt.Errorf("unsupported consistency level: %s", c.Consistency)
+		return 0, fmt.Errorf("unsupported consistency level: %s", level)
+	}
+}
+
+// ReplicationOptions returns the metadata keyspace replication as the
+// option map stored in system_schema.keyspaces, matching ReplicationCQL.
+func (c *Config) ReplicationOptions() map[string]string {
+	if c.MetadataReplication.Class == "NetworkTopologyStrategy" && len(c.MetadataReplication.Datacenters) > 0 {
+		opts := map[string]string{"class": "NetworkTopologyStrategy"}
+		for dc, rf := range c.MetadataReplication.Datacenters {
+			opts[dc] = strconv.Itoa(rf)
+		}
+		return opts
+	}
+
+	rf := c.MetadataReplication.ReplicationFactor
+	if rf <= 0 {
+		rf = 1
 	}
+	return map[string]string{"class": "SimpleStrategy", "replication_factor": strconv.Itoa(rf)}
 }
 
 func (c *Config) ReplicationCQL() string {
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,7 +15,7 @@ func validTestConfig() *Config {
 	return &Config{
 		Hosts:                  []string{"localhost:9042"},
 		Keyspace:               "test_ks",
-		MigrationsDir:          "./migrations",
+		MigrationsDirs:         []string{"./migrations"},
 		Consistency:            "quorum",
 		Timeout:                30_000_000_000,
 		LockTimeout:            60_000_000_000,
@@ -35,6 +39,63 @@ func TestConfig_Validate_MissingHosts(t *testing.T) {
 	assert.Contains(t, err.Error(), "host")
 }
 
+func TestConfig_Validate_StatusHosts(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.StatusHosts = []string{"replica1:9042", " "}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status_hosts")
+
+	cfg.StatusHosts = []string{"replica1:9042"}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_HostFormat(t *testing.T) {
+	tests := []struct {
+		host  string
+		valid bool
+	}{
+		{"localhost", true},
+		{"localhost:9042", true},
+		{"10.0.0.1:19042", true},
+		{"::1", true},
+		{"[::1]:9042", true},
+		{"localhost:", false},
+		{"localhost:cql", false},
+		{"localhost:70000", false},
+		{":9042", false},
+		{"[::1", false},
+	}
+	for _, tt := range tests {
+		// hosts and status_hosts go through the same check
+		for _, key := range []string{"hosts", "status_hosts"} {
+			cfg := validTestConfig()
+			if key == "hosts" {
+				cfg.Hosts = []string{tt.host}
+			} else {
+				cfg.StatusHosts = []string{tt.host}
+			}
+			err := cfg.Validate()
+			if tt.valid {
+				assert.NoError(t, err, "%s %s", key, tt.host)
+			} else {
+				assert.ErrorContains(t, err, key, tt.host)
+			}
+		}
+	}
+}
+
+func TestConfig_ForReadOnly(t *testing.T) {
+	cfg := validTestConfig()
+	assert.Same(t, cfg, cfg.ForReadOnly())
+
+	cfg.StatusHosts = []string{"replica1:9042"}
+	ro := cfg.ForReadOnly()
+	assert.Equal(t, []string{"replica1:9042"}, ro.Hosts)
+	assert.Equal(t, cfg.Keyspace, ro.Keyspace)
+	assert.Equal(t, []string{"localhost:9042"}, cfg.Hosts)
+}
+
 func TestConfig_Validate_MissingKeyspace(t *testing.T) {
 	cfg := validTestConfig()
 	cfg.Keyspace = ""
@@ -83,6 +144,30 @@ func TestConfig_Validate_SSLClientCertKeyPairing(t *testing.T) {
 	assert.Contains(t, err.Error(), "ssl.client_cert")
 }
 
+func TestConfig_Validate_NotifyWebhookURL(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.Notify.WebhookURL = "not a url"
+	cfg.Notify.Timeout = 5_000_000_000
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "notify.webhook_url")
+
+	cfg.Notify.WebhookURL = "https://hooks.example.com/services/abc"
+	require.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_SpeculativeExecution(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.SpeculativeExecution.Enabled = true
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "speculative_execution")
+
+	cfg.SpeculativeExecution.Attempts = 2
+	cfg.SpeculativeExecution.Delay = 100_000_000
+	require.NoError(t, cfg.Validate())
+}
+
 func TestConfig_GetConsistency(t *testing.T) {
 	tests := []struct {
 		level   string
@@ -113,6 +198,25 @@ func TestConfig_GetConsistency(t *testing.T) {
 	}
 }
 
+func TestConfig_GetReadConsistency(t *testing.T) {
+	cfg := validTestConfig()
+
+	// Falls back to the global consistency
+	cl, err := cfg.GetReadConsistency()
+	require.NoError(t, err)
+	assert.Equal(t, gocql.Quorum, cl)
+
+	cfg.ReadConsistency = "local_one"
+	cl, err = cfg.GetReadConsistency()
+	require.NoError(t, err)
+	assert.Equal(t, gocql.LocalOne, cl)
+
+	cfg.ReadConsistency = "bogus"
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "read_consistency")
+}
+
 func TestConfig_ReplicationCQL_SimpleStrategy(t *testing.T) {
 	cfg := &Config{
 		MetadataReplication: ReplicationConfig{
@@ -139,3 +243,188 @@ func TestConfig_ReplicationCQL_NetworkTopologyStrategy(t *testing.T) {
 	assert.Contains(t, cql, "dc1")
 	assert.Contains(t, cql, "3")
 }
+
+func TestConfig_Validate_CircuitBreaker(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.CircuitBreaker.Enabled = true
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "circuit_breaker")
+
+	cfg.CircuitBreaker.FailureThreshold = 5
+	cfg.CircuitBreaker.Cooldown = 5 * time.Second
+	cfg.CircuitBreaker.MaxOpen = time.Minute
+	require.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_LockStrategy(t *testing.T) {
+	cfg := validTestConfig()
+	for _, s := range []string{"", "lwt", "advisory", "none"} {
+		cfg.LockStrategy = s
+		assert.NoError(t, cfg.Validate(), s)
+	}
+
+	cfg.LockStrategy = "zookeeper"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lock_strategy")
+}
+
+func TestConfig_ApplyEnvironment(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.ApplyEnvironment()
+	assert.Equal(t, "scylla_migrate", cfg.MetadataKeyspace)
+
+	cfg.Environment = "Staging"
+	cfg.ApplyEnvironment()
+	assert.Equal(t, "scylla_migrate_staging", cfg.MetadataKeyspace)
+
+	// Applying twice must not suffix twice
+	cfg.ApplyEnvironment()
+	assert.Equal(t, "scylla_migrate_staging", cfg.MetadataKeyspace)
+	require.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_Environment(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.Environment = "stag-ing"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "environment")
+
+	cfg = validTestConfig()
+	cfg.Environment = strings.Repeat("e", 40)
+	cfg.ApplyEnvironment()
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "longer than 48")
+}
+
+func TestConfig_Validate_StatementSeparator(t *testing.T) {
+	cfg := validTestConfig()
+	for _, sep := range []string{"", ";", ";;", "GO", "$$"} {
+		cfg.StatementSeparator = sep
+		assert.NoError(t, cfg.Validate(), sep)
+	}
+
+	for _, sep := range []string{"'", "; ;", "--", "/*"} {
+		cfg.StatementSeparator = sep
+		err := cfg.Validate()
+		require.Error(t, err, sep)
+		assert.Contains(t, err.Error(), "statement_separator")
+	}
+}
+
+func TestConfig_Validate_ReadinessQuery(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.ReadinessQuery.Query = "DELETE FROM ops.repairs WHERE id = 1"
+	cfg.ReadinessQuery.Timeout = time.Minute
+	cfg.ReadinessQuery.Interval = time.Second
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SELECT")
+
+	cfg.ReadinessQuery.Query = "select count(*) from ops.repairs where active = true allow filtering"
+	require.NoError(t, cfg.Validate())
+
+	cfg.ReadinessQuery.Interval = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "readiness_query.interval")
+}
+
+func TestConfig_Validate_AllowedStatementTypes(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.AllowedStatementTypes = []string{"dml", "DDL"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.AllowedStatementTypes = []string{"select"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "allowed_statement_types")
+}
+
+func TestConfig_Validate_RepeatableMode(t *testing.T) {
+	cfg := validTestConfig()
+	for _, m := range []string{"", "checksum", "once"} {
+		cfg.RepeatableMode = m
+		assert.NoError(t, cfg.Validate(), m)
+	}
+
+	cfg.RepeatableMode = "never"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "repeatable_mode")
+}
+
+func TestConfig_Validate_AllowLocalModifications(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.ProductionEnvironments = []string{"prod", "production"}
+	cfg.AllowLocalModifications = true
+	err := cfg.Validate()
+	require.Error(t, err, "no environment set")
+	assert.Contains(t, err.Error(), "non-production environment")
+
+	cfg.Environment = "dev"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Environment = "Prod"
+	assert.True(t, cfg.IsProduction())
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "allow_local_modifications")
+
+	cfg.AllowLocalModifications = false
+	require.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_TimeDisplay(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.TimeDisplay = TimeDisplayConfig{Timezone: "local", Format: "RFC3339"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.TimeDisplay = TimeDisplayConfig{Timezone: "Mars/Olympus"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "time_display.timezone")
+
+	cfg.TimeDisplay = TimeDisplayConfig{Format: "unix"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "time_display.format")
+}
+
+func TestTimeDisplayConfig_Layout(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 123_000_000, time.UTC)
+
+	loc, err := TimeDisplayConfig{}.Location()
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01 12:00:00 UTC", ts.In(loc).Format(TimeDisplayConfig{}.Layout()))
+	assert.Equal(t, "2024-05-01T12:00:00.123Z", ts.Format(TimeDisplayConfig{Format: TimeFormatRFC3339}.Layout()))
+}
+
+func TestConfig_Validate_SessionPreambleRejectsUse(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.SessionPreamble = []string{"INSERT INTO ks.log (id) VALUES (uuid())"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.SessionPreamble = append(cfg.SessionPreamble, "  use my_application")
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session_preamble statement 2")
+
+	cfg.SessionPreamble = nil
+	cfg.SessionEpilogue = []string{"USE other"}
+	assert.ErrorContains(t, cfg.Validate(), "session_epilogue")
+}
+
+func TestConfig_ValidateOffline_IgnoresClusterSettings(t *testing.T) {
+	cfg := validTestConfig()
+	cfg.Hosts = nil
+	cfg.Keyspace = ""
+	require.NoError(t, cfg.ValidateOffline())
+	assert.Error(t, cfg.Validate())
+
+	cfg.MigrationsDirs = nil
+	assert.Error(t, cfg.ValidateOffline())
+}
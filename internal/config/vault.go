@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// CredentialSource supplies the username and password when they are not
+// configured directly. VaultSource is the built-in implementation; library
+// users can plug in their own secrets store.
+type CredentialSource interface {
+	Credentials(ctx context.Context) (username, password string, err error)
+}
+
+// VaultConfig reads credentials from a HashiCorp Vault KV secret (v1 or v2)
+// over the HTTP API. It authenticates with a token, or with the Kubernetes
+// auth method when Role is set.
+type VaultConfig struct {
+	Address       string        `mapstructure:"address" yaml:"address"`
+	Token         string        `mapstructure:"token" yaml:"token"`
+	Role          string        `mapstructure:"role" yaml:"role"`
+	AuthPath      string        `mapstructure:"auth_path" yaml:"auth_path"`
+	JWTPath       string        `mapstructure:"jwt_path" yaml:"jwt_path"`
+	Path          string        `mapstructure:"path" yaml:"path"`
+	UsernameField string        `mapstructure:"username_field" yaml:"username_field"`
+	PasswordField string        `mapstructure:"password_field" yaml:"password_field"`
+	Timeout       time.Duration `mapstructure:"timeout" yaml:"timeout"`
+}
+
+const defaultKubernetesJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultSource is a CredentialSource backed by Vault.
+type VaultSource struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultSource fills unset fields from VAULT_ADDR / VAULT_TOKEN and the
+// defaults (Kubernetes auth mount "kubernetes", fields "username" and
+// "password", 10s timeout).
+func NewVaultSource(cfg VaultConfig) *VaultSource {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" && cfg.Role == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.AuthPath == "" {
+		cfg.AuthPath = "kubernetes"
+	}
+	if cfg.JWTPath == "" {
+		cfg.JWTPath = defaultKubernetesJWTPath
+	}
+	if cfg.UsernameField == "" {
+		cfg.UsernameField = "username"
+	}
+	if cfg.PasswordField == "" {
+		cfg.PasswordField = "password"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &VaultSource{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (v *VaultSource) Credentials(ctx context.Context) (string, string, error) {
+	if v.cfg.Address == "" {
+		return "", "", fmt.Errorf("vault.address (or VAULT_ADDR) must be set")
+	}
+
+	token := v.cfg.Token
+	if token == "" {
+		if v.cfg.Role == "" {
+			return "", "", fmt.Errorf("vault.token (or VAULT_TOKEN) or vault.role must be set")
+		}
+		var err error
+		if token, err = v.loginKubernetes(ctx); err != nil {
+			return "", "", err
+		}
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodGet, v.cfg.Path, token, nil, &secret); err != nil {
+		return "", "", fmt.Errorf("failed to read vault secret %s: %w", v.cfg.Path, err)
+	}
+
+	// KV v2 nests the fields under data.data, next to data.metadata
+	fields := secret.Data
+	if inner, ok := fields["data"].(map[string]interface{}); ok {
+		if _, v2 := fields["metadata"]; v2 {
+			fields = inner
+		}
+	}
+
+	username, _ := fields[v.cfg.UsernameField].(string)
+	password, _ := fields[v.cfg.PasswordField].(string)
+	if username == "" || password == "" {
+		return "", "", fmt.Errorf("vault secret %s has no %q and %q string fields", v.cfg.Path, v.cfg.UsernameField, v.cfg.PasswordField)
+	}
+	return username, password, nil
+}
+
+func (v *VaultSource) loginKubernetes(ctx context.Context) (string, error) {
+	jwt, err := os.ReadFile(v.cfg.JWTPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account token for vault login: %w", err)
+	}
+
+	body := map[string]string{"role": v.cfg.Role, "jwt": strings.TrimSpace(string(jwt))}
+	var login struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := v.do(ctx, http.MethodPost, "auth/"+strings.Trim(v.cfg.AuthPath, "/")+"/login", "", body, &login); err != nil {
+		return "", fmt.Errorf("vault login with role %q failed: %w", v.cfg.Role, err)
+	}
+	if login.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login with role %q returned no token", v.cfg.Role)
+	}
+	return login.Auth.ClientToken, nil
+}
+
+// do calls the Vault API at /v1/<path> and decodes the JSON response.
+func (v *VaultSource) do(ctx context.Context, method, path, token string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	url := strings.TrimRight(v.cfg.Address, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Vault error bodies list messages but never echo secrets
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vault returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ResolveCredentials fills an empty username or password from the
+// credential source: CredentialSource if set, else Vault when vault.path is
+// configured. Values set directly are never overridden. driver.NewSession
+// calls it; once resolved, later calls make no request.
+func (c *Config) ResolveCredentials() error {
+	src := c.CredentialSource
+	if src == nil && c.Vault.Path != "" {
+		src = NewVaultSource(c.Vault)
+	}
+	if src == nil || (c.Username != "" && c.Password != "") {
+		return nil
+	}
+
+	username, password, err := src.Credentials(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+	if c.Username == "" {
+		c.Username = username
+	}
+	if c.Password == "" {
+		c.Password = password
+	}
+	c.credentialsResolved = true
+	return nil
+}
+
+// CredentialsResolved reports whether ResolveCredentials fetched the
+// username or password from a credential source.
+func (c *Config) CredentialsResolved() bool {
+	return c.credentialsResolved
+}
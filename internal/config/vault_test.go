@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/auth/kubernetes/login" && r.Method == http.MethodPost:
+			// require would call FailNow outside the test goroutine
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["role"] != "migrator" || body["jwt"] != "sa-token" {
+				http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"k8s-token"}}`))
+		case r.Header.Get("X-Vault-Token") != "root" && r.Header.Get("X-Vault-Token") != "k8s-token":
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		case r.URL.Path == "/v1/secret/data/scylla":
+			_, _ = w.Write([]byte(`{"data":{"data":{"user":"app","pass":"s3cret"},"metadata":{"version":3}}}`))
+		case r.URL.Path == "/v1/kv/scylla":
+			_, _ = w.Write([]byte(`{"data":{"username":"legacy","password":"old"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestVaultSource_TokenKVv2(t *testing.T) {
+	srv := vaultServer(t)
+	defer srv.Close()
+
+	src := NewVaultSource(VaultConfig{
+		Address: srv.URL, Token: "root", Path: "secret/data/scylla",
+		UsernameField: "user", PasswordField: "pass",
+	})
+	user, pass, err := src.Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "app", user)
+	assert.Equal(t, "s3cret", pass)
+}
+
+func TestVaultSource_KVv1(t *testing.T) {
+	srv := vaultServer(t)
+	defer srv.Close()
+
+	user, pass, err := NewVaultSource(VaultConfig{Address: srv.URL, Token: "root", Path: "kv/scylla"}).Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", user)
+	assert.Equal(t, "old", pass)
+}
+
+func TestVaultSource_KubernetesLogin(t *testing.T) {
+	srv := vaultServer(t)
+	defer srv.Close()
+
+	jwt := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwt, []byte("sa-token\n"), 0o600))
+
+	src := NewVaultSource(VaultConfig{
+		Address: srv.URL, Role: "migrator", JWTPath: jwt, Path: "secret/data/scylla",
+		UsernameField: "user", PasswordField: "pass",
+	})
+	user, _, err := src.Credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "app", user)
+
+	src = NewVaultSource(VaultConfig{Address: srv.URL, Role: "other", JWTPath: jwt, Path: "secret/data/scylla"})
+	_, _, err = src.Credentials(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
+
+func TestVaultSource_MissingFields(t *testing.T) {
+	srv := vaultServer(t)
+	defer srv.Close()
+
+	// The v2 secret has user/pass, not the default username/password
+	_, _, err := NewVaultSource(VaultConfig{Address: srv.URL, Token: "root", Path: "secret/data/scylla"}).Credentials(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"username"`)
+}
+
+func TestConfig_ResolveCredentials(t *testing.T) {
+	srv := vaultServer(t)
+	defer srv.Close()
+
+	cfg := validTestConfig()
+	require.NoError(t, cfg.ResolveCredentials(), "no source configured")
+	assert.False(t, cfg.CredentialsResolved())
+
+	cfg.Vault = VaultConfig{Address: srv.URL, Token: "root", Path: "kv/scylla"}
+	cfg.Username = "explicit"
+	require.NoError(t, cfg.ResolveCredentials())
+	assert.Equal(t, "explicit", cfg.Username, "configured values are never overridden")
+	assert.Equal(t, "old", cfg.Password)
+	assert.True(t, cfg.CredentialsResolved())
+}
@@ -0,0 +1,96 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+)
+
+const testContract = `
+-- Desired end state of the app keyspace
+CREATE KEYSPACE IF NOT EXISTS app WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1};
+
+CREATE TYPE app.address (street text, city varchar);
+
+CREATE TABLE app.events (
+    tenant_id uuid,
+    day date,
+    created_at timestamp,
+    owner text STATIC,
+    tags map<text, int>,
+    home frozen<address>,
+    PRIMARY KEY ((tenant_id, day), created_at)
+) WITH CLUSTERING ORDER BY (created_at DESC);
+
+CREATE TABLE users (id uuid PRIMARY KEY, email text);
+
+CREATE INDEX ON users (email);
+CREATE INDEX events_tags_idx ON app.events (KEYS(tags));
+`
+
+func TestParse(t *testing.T) {
+	dump, err := Parse(testContract, "app")
+	require.NoError(t, err)
+
+	events := dump.Tables["events"]
+	require.NotNil(t, events)
+	assert.Equal(t, []string{"tenant_id", "day"}, events.KeyColumns(driver.KindPartitionKey))
+	assert.Equal(t, []string{"created_at"}, events.KeyColumns(driver.KindClustering))
+	assert.Equal(t, "desc", events.Columns["created_at"].ClusteringOrder)
+	assert.Equal(t, driver.KindStatic, events.Columns["owner"].Kind)
+	assert.Equal(t, "map<text,int>", events.Columns["tags"].Type)
+
+	users := dump.Tables["users"]
+	require.NotNil(t, users)
+	assert.Equal(t, []string{"id"}, users.KeyColumns(driver.KindPartitionKey))
+
+	require.NotNil(t, dump.Types["address"])
+	assert.Equal(t, []driver.FieldDump{{Name: "street", Type: "text"}, {Name: "city", Type: "text"}}, dump.Types["address"].Fields)
+
+	assert.Equal(t, &driver.IndexDump{Name: "users_email_idx", Table: "users", Target: "email"}, dump.Indexes["users_email_idx"])
+	assert.Equal(t, "keys(tags)", dump.Indexes["events_tags_idx"].Target)
+}
+
+func TestParse_Rejects(t *testing.T) {
+	_, err := Parse("ALTER TABLE users ADD x int;", "app")
+	assert.ErrorContains(t, err, "unsupported statement")
+
+	_, err = Parse("CREATE TABLE other.users (id int PRIMARY KEY);", "app")
+	assert.ErrorContains(t, err, "keyspace other")
+
+	_, err = Parse("CREATE TABLE users (id int, PRIMARY KEY (uid));", "app")
+	assert.ErrorContains(t, err, "not defined")
+}
+
+func TestDiff(t *testing.T) {
+	expected, err := Parse(testContract, "app")
+	require.NoError(t, err)
+
+	actual, err := Parse(testContract, "app")
+	require.NoError(t, err)
+	// Live schema spells types the way system_schema does
+	c := actual.Tables["events"].Columns["tags"]
+	c.Type = "map<text, int>"
+	actual.Tables["events"].Columns["tags"] = c
+	actual.Indexes["events_tags_idx"].Target = "keys(tags)"
+	assert.Empty(t, Diff(expected, actual))
+
+	delete(actual.Tables["users"].Columns, "email")
+	actual.Tables["audit"] = &driver.TableDump{Name: "audit", Columns: map[string]driver.ColumnDump{}}
+	c = actual.Tables["events"].Columns["created_at"]
+	c.ClusteringOrder = "ASC"
+	actual.Tables["events"].Columns["created_at"] = c
+	actual.Types["address"].Fields = actual.Types["address"].Fields[:1]
+	delete(actual.Indexes, "users_email_idx")
+
+	assert.Equal(t, []string{
+		"index users_email_idx: missing",
+		"table audit: not in contract",
+		"table events: column created_at: clustering order asc, contract expects desc",
+		"table users: column email: missing",
+		"type address: fields (street text), contract expects (street text, city text)",
+	}, Diff(expected, actual))
+}
@@ -0,0 +1,126 @@
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+)
+
+// Diff lists the differences between the contract (expected) and the live
+// schema (actual), sorted for stable output. An empty result means the
+// keyspace matches the contract.
+func Diff(expected, actual *driver.KeyspaceDump) []string {
+	var out []string
+	add := func(format string, a ...interface{}) {
+		out = append(out, fmt.Sprintf(format, a...))
+	}
+
+	for name, want := range expected.Tables {
+		got, ok := actual.Tables[name]
+		if !ok {
+			add("table %s: missing", name)
+			continue
+		}
+		diffTable(want, got, add)
+	}
+	for name := range actual.Tables {
+		if _, ok := expected.Tables[name]; !ok {
+			add("table %s: not in contract", name)
+		}
+	}
+
+	for name, want := range expected.Types {
+		got, ok := actual.Types[name]
+		if !ok {
+			add("type %s: missing", name)
+			continue
+		}
+		if w, g := formatFields(want.Fields), formatFields(got.Fields); w != g {
+			add("type %s: fields (%s), contract expects (%s)", name, g, w)
+		}
+	}
+	for name := range actual.Types {
+		if _, ok := expected.Types[name]; !ok {
+			add("type %s: not in contract", name)
+		}
+	}
+
+	for name, want := range expected.Indexes {
+		got, ok := actual.Indexes[name]
+		switch {
+		case !ok:
+			add("index %s: missing", name)
+		case got.Table != want.Table:
+			add("index %s: on table %s, contract expects %s", name, got.Table, want.Table)
+		case NormalizeTarget(got.Target) != want.Target:
+			add("index %s: target %s, contract expects %s", name, got.Target, want.Target)
+		}
+	}
+	for name := range actual.Indexes {
+		if _, ok := expected.Indexes[name]; !ok {
+			add("index %s: not in contract", name)
+		}
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+func diffTable(want, got *driver.TableDump, add func(string, ...interface{})) {
+	name := want.Name
+
+	if w, g := want.KeyColumns(driver.KindPartitionKey), got.KeyColumns(driver.KindPartitionKey); !equal(w, g) {
+		add("table %s: partition key (%s), contract expects (%s)", name, strings.Join(g, ", "), strings.Join(w, ", "))
+	}
+	if w, g := want.KeyColumns(driver.KindClustering), got.KeyColumns(driver.KindClustering); !equal(w, g) {
+		add("table %s: clustering columns (%s), contract expects (%s)", name, strings.Join(g, ", "), strings.Join(w, ", "))
+	}
+
+	for colName, wc := range want.Columns {
+		gc, ok := got.Columns[colName]
+		if !ok {
+			add("table %s: column %s: missing", name, colName)
+			continue
+		}
+		if NormalizeType(gc.Type) != wc.Type {
+			add("table %s: column %s: type %s, contract expects %s", name, colName, gc.Type, wc.Type)
+		}
+		if gc.Kind == driver.KindStatic || wc.Kind == driver.KindStatic {
+			if gc.Kind != wc.Kind {
+				add("table %s: column %s: %s, contract expects %s", name, colName, gc.Kind, wc.Kind)
+			}
+		}
+		if wc.Kind == driver.KindClustering && gc.Kind == driver.KindClustering &&
+			!strings.EqualFold(gc.ClusteringOrder, wc.ClusteringOrder) {
+			add("table %s: column %s: clustering order %s, contract expects %s",
+				name, colName, strings.ToLower(gc.ClusteringOrder), wc.ClusteringOrder)
+		}
+	}
+	for colName := range got.Columns {
+		if _, ok := want.Columns[colName]; !ok {
+			add("table %s: column %s: not in contract", name, colName)
+		}
+	}
+}
+
+func formatFields(fields []driver.FieldDump) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Name + " " + NormalizeType(f.Type)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,332 @@
+// Package contract compares the live schema of a keyspace with a checked-in
+// CQL file describing its intended end state.
+package contract
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+const ident = `(?:"(?:[^"]|"")+"|\w+)`
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE\s+(?:TABLE|COLUMNFAMILY)\s+(?:IF\s+NOT\s+EXISTS\s+)?(` +
+		ident + `(?:\s*\.\s*` + ident + `)?)\s*\(`)
+	createTypePattern = regexp.MustCompile(`(?is)^CREATE\s+TYPE\s+(?:IF\s+NOT\s+EXISTS\s+)?(` +
+		ident + `(?:\s*\.\s*` + ident + `)?)\s*\(`)
+	createIndexPattern = regexp.MustCompile(`(?is)^CREATE\s+(?:CUSTOM\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?(` +
+		ident + `)?\s*\bON\s+(` + ident + `(?:\s*\.\s*` + ident + `)?)\s*\(`)
+	skippedPattern        = regexp.MustCompile(`(?is)^(CREATE\s+KEYSPACE|USE)\b`)
+	primaryKeyPattern     = regexp.MustCompile(`(?is)^PRIMARY\s+KEY\s*\((.*)\)$`)
+	inlinePrimaryKey      = regexp.MustCompile(`(?is)\s+PRIMARY\s+KEY$`)
+	staticSuffix          = regexp.MustCompile(`(?is)\s+STATIC$`)
+	clusteringOrderClause = regexp.MustCompile(`(?is)CLUSTERING\s+ORDER\s+BY\s*\(([^)]*)\)`)
+	leadingIdent          = regexp.MustCompile(`^(` + ident + `)\s+(.+)$`)
+	indexTargetColumn     = regexp.MustCompile(`(?i)^(?:\w+\()?(` + ident + `)\)?$`)
+)
+
+// Parse reads a contract: CREATE TABLE, CREATE TYPE and CREATE INDEX
+// statements for keyspace. CREATE KEYSPACE and USE are ignored; anything else
+// is rejected, since a contract describes an end state, not steps.
+func Parse(content, keyspace string) (*driver.KeyspaceDump, error) {
+	dump := driver.NewKeyspaceDump(keyspace)
+
+	err := migration.StreamStatements(strings.NewReader(content), func(stmt string) error {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case skippedPattern.MatchString(stmt):
+			return nil
+		case createTablePattern.MatchString(stmt):
+			return parseTable(dump, stmt)
+		case createTypePattern.MatchString(stmt):
+			return parseType(dump, stmt)
+		case createIndexPattern.MatchString(stmt):
+			return parseIndex(dump, stmt)
+		default:
+			return fmt.Errorf("unsupported statement in contract: %s", firstLine(stmt))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dump, nil
+}
+
+func parseTable(dump *driver.KeyspaceDump, stmt string) error {
+	m := createTablePattern.FindStringSubmatchIndex(stmt)
+	name, err := objectName(dump.Name, stmt[m[2]:m[3]])
+	if err != nil {
+		return err
+	}
+	body, rest, err := parenthesized(stmt, m[1]-1)
+	if err != nil {
+		return fmt.Errorf("table %s: %w", name, err)
+	}
+
+	table := &driver.TableDump{Name: name, Columns: make(map[string]driver.ColumnDump)}
+	var order []string
+	var partition, clustering []string
+
+	for _, def := range splitTopLevel(body) {
+		if pk := primaryKeyPattern.FindStringSubmatch(def); pk != nil {
+			partition, clustering = parsePrimaryKey(pk[1])
+			continue
+		}
+
+		col := leadingIdent.FindStringSubmatch(def)
+		if col == nil {
+			return fmt.Errorf("table %s: invalid column definition %q", name, def)
+		}
+		c := driver.ColumnDump{Name: migration.UnquoteIdentifier(col[1]), Kind: driver.KindRegular, Position: -1}
+		typ := col[2]
+		if inlinePrimaryKey.MatchString(typ) {
+			typ = inlinePrimaryKey.ReplaceAllString(typ, "")
+			partition = []string{c.Name}
+		}
+		if staticSuffix.MatchString(typ) {
+			typ = staticSuffix.ReplaceAllString(typ, "")
+			c.Kind = driver.KindStatic
+		}
+		c.Type = NormalizeType(typ)
+		table.Columns[c.Name] = c
+		order = append(order, c.Name)
+	}
+
+	if len(partition) == 0 {
+		return fmt.Errorf("table %s: no primary key", name)
+	}
+
+	orders := make(map[string]string)
+	if co := clusteringOrderClause.FindStringSubmatch(rest); co != nil {
+		for _, part := range splitTopLevel(co[1]) {
+			fields := strings.Fields(part)
+			if len(fields) == 2 {
+				orders[migration.UnquoteIdentifier(fields[0])] = strings.ToLower(fields[1])
+			}
+		}
+	}
+
+	for i, colName := range partition {
+		if err := setKey(table, colName, driver.KindPartitionKey, i, ""); err != nil {
+			return err
+		}
+	}
+	for i, colName := range clustering {
+		order := orders[colName]
+		if order == "" {
+			order = "asc"
+		}
+		if err := setKey(table, colName, driver.KindClustering, i, order); err != nil {
+			return err
+		}
+	}
+
+	dump.Tables[name] = table
+	return nil
+}
+
+func setKey(table *driver.TableDump, name, kind string, position int, order string) error {
+	c, ok := table.Columns[name]
+	if !ok {
+		return fmt.Errorf("table %s: primary key column %s is not defined", table.Name, name)
+	}
+	c.Kind, c.Position, c.ClusteringOrder = kind, position, order
+	table.Columns[name] = c
+	return nil
+}
+
+// parsePrimaryKey splits "(a, b), c, d" or "a, c" into partition and
+// clustering columns.
+func parsePrimaryKey(def string) ([]string, []string) {
+	parts := splitTopLevel(def)
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	var partition []string
+	first := strings.TrimSpace(parts[0])
+	if strings.HasPrefix(first, "(") && strings.HasSuffix(first, ")") {
+		for _, p := range splitTopLevel(first[1 : len(first)-1]) {
+			partition = append(partition, migration.UnquoteIdentifier(p))
+		}
+	} else {
+		partition = []string{migration.UnquoteIdentifier(first)}
+	}
+
+	var clustering []string
+	for _, p := range parts[1:] {
+		clustering = append(clustering, migration.UnquoteIdentifier(p))
+	}
+	return partition, clustering
+}
+
+func parseType(dump *driver.KeyspaceDump, stmt string) error {
+	m := createTypePattern.FindStringSubmatchIndex(stmt)
+	name, err := objectName(dump.Name, stmt[m[2]:m[3]])
+	if err != nil {
+		return err
+	}
+	body, _, err := parenthesized(stmt, m[1]-1)
+	if err != nil {
+		return fmt.Errorf("type %s: %w", name, err)
+	}
+
+	t := &driver.TypeDump{Name: name}
+	for _, def := range splitTopLevel(body) {
+		field := leadingIdent.FindStringSubmatch(def)
+		if field == nil {
+			return fmt.Errorf("type %s: invalid field definition %q", name, def)
+		}
+		t.Fields = append(t.Fields, driver.FieldDump{
+			Name: migration.UnquoteIdentifier(field[1]),
+			Type: NormalizeType(field[2]),
+		})
+	}
+
+	dump.Types[name] = t
+	return nil
+}
+
+func parseIndex(dump *driver.KeyspaceDump, stmt string) error {
+	m := createIndexPattern.FindStringSubmatchIndex(stmt)
+	table, err := objectName(dump.Name, stmt[m[4]:m[5]])
+	if err != nil {
+		return err
+	}
+	target, _, err := parenthesized(stmt, m[1]-1)
+	if err != nil {
+		return fmt.Errorf("index on %s: %w", table, err)
+	}
+	target = NormalizeTarget(target)
+
+	name := ""
+	if m[2] >= 0 {
+		name = migration.UnquoteIdentifier(stmt[m[2]:m[3]])
+	} else if col := indexTargetColumn.FindStringSubmatch(target); col != nil {
+		// Default name Scylla gives unnamed indexes
+		name = fmt.Sprintf("%s_%s_idx", table, col[1])
+	} else {
+		return fmt.Errorf("index on %s: unnamed index with target %q must be named in the contract", table, target)
+	}
+
+	dump.Indexes[name] = &driver.IndexDump{Name: name, Table: table, Target: target}
+	return nil
+}
+
+// objectName resolves a possibly keyspace-qualified name, rejecting objects
+// of other keyspaces.
+func objectName(keyspace, qualified string) (string, error) {
+	parts := splitQualified(qualified)
+	if len(parts) == 2 {
+		if ks := migration.UnquoteIdentifier(parts[0]); ks != keyspace {
+			return "", fmt.Errorf("contract object %s belongs to keyspace %s, not %s", qualified, ks, keyspace)
+		}
+		return migration.UnquoteIdentifier(parts[1]), nil
+	}
+	return migration.UnquoteIdentifier(parts[0]), nil
+}
+
+func splitQualified(name string) []string {
+	inQuote := false
+	for i, r := range name {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == '.' && !inQuote:
+			return []string{strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])}
+		}
+	}
+	return []string{strings.TrimSpace(name)}
+}
+
+// parenthesized returns the text between the parenthesis at open and its
+// matching closing parenthesis, and everything after it.
+func parenthesized(s string, open int) (string, string, error) {
+	depth := 0
+	inQuote := rune(0)
+	for i, r := range s[open:] {
+		switch {
+		case inQuote != 0:
+			if r == inQuote {
+				inQuote = 0
+			}
+		case r == '\'' || r == '"':
+			inQuote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				end := open + i
+				return s[open+1 : end], s[end+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unbalanced parentheses")
+}
+
+// splitTopLevel splits on commas outside parentheses, angle brackets and
+// quotes, trimming each part and dropping empty ones.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	inQuote := rune(0)
+	start := 0
+	for i, r := range s {
+		switch {
+		case inQuote != 0:
+			if r == inQuote {
+				inQuote = 0
+			}
+		case r == '\'' || r == '"':
+			inQuote = r
+		case r == '(' || r == '<':
+			depth++
+		case r == ')' || r == '>':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, s[start:])
+
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+var varcharWord = regexp.MustCompile(`\bvarchar\b`)
+
+// NormalizeType makes CQL types comparable: lowercase, no whitespace, and
+// varchar spelled as its alias text.
+func NormalizeType(typ string) string {
+	typ = strings.Join(strings.Fields(strings.ToLower(typ)), "")
+	return varcharWord.ReplaceAllString(typ, "text")
+}
+
+// NormalizeTarget makes index targets comparable. A plain collection index
+// is reported by Scylla as values(col), so that wrapper is dropped.
+func NormalizeTarget(target string) string {
+	target = strings.Join(strings.Fields(target), "")
+	if strings.HasPrefix(strings.ToLower(target), "values(") && strings.HasSuffix(target, ")") {
+		target = target[len("values(") : len(target)-1]
+	}
+	if !strings.Contains(target, `"`) {
+		target = strings.ToLower(target)
+	}
+	return target
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
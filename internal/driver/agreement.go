@@ -0,0 +1,104 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NodeSchemaVersion is the schema version one node reports, as seen from the
+// coordinator's system.local and system.peers.
+type NodeSchemaVersion struct {
+	Host    string
+	Version string
+}
+
+// SchemaDisagreementError names the nodes whose schema version differs from
+// the version most nodes agree on.
+type SchemaDisagreementError struct {
+	Majority  string
+	Agreeing  int
+	Divergent []NodeSchemaVersion
+}
+
+func (e *SchemaDisagreementError) Error() string {
+	nodes := make([]string, len(e.Divergent))
+	for i, n := range e.Divergent {
+		nodes[i] = fmt.Sprintf("%s (%s)", n.Host, n.Version)
+	}
+	return fmt.Sprintf("schema disagreement: %s differ from version %s held by %d node(s)",
+		strings.Join(nodes, ", "), e.Majority, e.Agreeing)
+}
+
+// SchemaVersions lists the schema version of every node. Peers that have not
+// reported a version yet are left out, as gocql's agreement check does.
+// system.peers is read from the node that answered for system.local, since
+// another coordinator would list a different set of peers.
+func (s *Session) SchemaVersions() ([]NodeSchemaVersion, error) {
+	var nodes []NodeSchemaVersion
+
+	var host, version string
+	iter := s.session.Query("SELECT broadcast_address, schema_version FROM system.local WHERE key='local'").Iter()
+	found := iter.Scan(&host, &version)
+	coordinator := iter.Host()
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read local schema version: %w", err)
+	}
+	if !found || coordinator == nil {
+		return nil, fmt.Errorf("failed to read local schema version: no row returned")
+	}
+	nodes = append(nodes, NodeSchemaVersion{Host: host, Version: version})
+
+	iter = s.session.Query("SELECT peer, schema_version FROM system.peers").
+		WithContext(withHost(context.Background(), coordinator)).Iter()
+	for iter.Scan(&host, &version) {
+		if version != "" {
+			nodes = append(nodes, NodeSchemaVersion{Host: host, Version: version})
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read peer schema versions: %w", err)
+	}
+	return nodes, nil
+}
+
+// CheckSchemaAgreement returns a *SchemaDisagreementError when the nodes do
+// not all report the same schema version.
+func (s *Session) CheckSchemaAgreement() error {
+	nodes, err := s.SchemaVersions()
+	if err != nil {
+		return err
+	}
+	if d := schemaDisagreement(nodes); d != nil {
+		return d
+	}
+	return nil
+}
+
+// schemaDisagreement groups nodes by version; nil means they all agree.
+// Ties for the majority go to the lexically smallest version so the report
+// is stable.
+func schemaDisagreement(nodes []NodeSchemaVersion) *SchemaDisagreementError {
+	counts := make(map[string]int)
+	for _, n := range nodes {
+		counts[n.Version]++
+	}
+	if len(counts) <= 1 {
+		return nil
+	}
+
+	d := &SchemaDisagreementError{}
+	for version, count := range counts {
+		if count > d.Agreeing || (count == d.Agreeing && version < d.Majority) {
+			d.Majority, d.Agreeing = version, count
+		}
+	}
+	for _, n := range nodes {
+		if n.Version != d.Majority {
+			d.Divergent = append(d.Divergent, n)
+		}
+	}
+	sort.Slice(d.Divergent, func(i, j int) bool { return d.Divergent[i].Host < d.Divergent[j].Host })
+	return d
+}
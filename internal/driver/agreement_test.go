@@ -0,0 +1,36 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDisagreement(t *testing.T) {
+	assert.Nil(t, schemaDisagreement([]NodeSchemaVersion{
+		{Host: "10.0.0.1", Version: "a"},
+		{Host: "10.0.0.2", Version: "a"},
+	}))
+
+	d := schemaDisagreement([]NodeSchemaVersion{
+		{Host: "10.0.0.1", Version: "a"},
+		{Host: "10.0.0.3", Version: "b"},
+		{Host: "10.0.0.2", Version: "a"},
+	})
+	require.NotNil(t, d)
+	assert.Equal(t, "a", d.Majority)
+	assert.Equal(t, 2, d.Agreeing)
+	assert.Equal(t, []NodeSchemaVersion{{Host: "10.0.0.3", Version: "b"}}, d.Divergent)
+	assert.Equal(t, "schema disagreement: 10.0.0.3 (b) differ from version a held by 2 node(s)", d.Error())
+}
+
+func TestSchemaDisagreementTieIsStable(t *testing.T) {
+	d := schemaDisagreement([]NodeSchemaVersion{
+		{Host: "10.0.0.2", Version: "b"},
+		{Host: "10.0.0.1", Version: "a"},
+	})
+	require.NotNil(t, d)
+	assert.Equal(t, "a", d.Majority)
+	assert.Equal(t, "10.0.0.2", d.Divergent[0].Host)
+}
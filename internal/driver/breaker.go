@@ -0,0 +1,205 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+// ErrCircuitOpen is returned when the breaker has been open longer than
+// circuit_breaker.max_open and the run should be aborted.
+var ErrCircuitOpen = errors.New("circuit breaker open: cluster unavailable")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker counts consecutive cluster failures across statements. It
+// complements gocql's per-query retries: once the cluster looks down, we stop
+// spending the retry budget and wait for a cooldown instead. It is safe for
+// concurrent use.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	cfg      config.BreakerConfig
+	state    breakerState
+	failures int
+	openedAt time.Time
+	logger   zerolog.Logger
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func newCircuitBreaker(cfg config.BreakerConfig, logger zerolog.Logger) *circuitBreaker {
+	return &circuitBreaker{
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// allow blocks until a request may be attempted. It returns ErrCircuitOpen
+// when the breaker has stayed open past max_open.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	if b.state == breakerClosed || b.state == breakerHalfOpen {
+		b.mu.Unlock()
+		return nil
+	}
+
+	openFor := b.now().Sub(b.openedAt)
+	if openFor >= b.cfg.MaxOpen {
+		b.mu.Unlock()
+		return fmt.Errorf("%w for %s", ErrCircuitOpen, openFor.Round(time.Second))
+	}
+	b.mu.Unlock()
+
+	// Sleep unlocked so concurrent callers each wait out the cooldown
+	// instead of queueing behind one another
+	b.logger.Warn().Dur("cooldown", b.cfg.Cooldown).Msg("Circuit breaker open, pausing before retry")
+	b.sleep(b.cfg.Cooldown)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerOpen {
+		b.transition(breakerHalfOpen)
+	}
+	return nil
+}
+
+// do runs fn, retrying it while it fails with cluster failures: after each
+// failure allow pauses once the breaker has opened, and ErrCircuitOpen ends
+// the retries once it has stayed open past max_open. Statement errors are
+// returned as they are, and a cancelled call says nothing about the cluster,
+// so it is not recorded. A failure that may have left the statement applied
+// is only retried when the statement is idempotent: running DDL or an insert
+// twice is worse than failing the migration.
+func (b *circuitBreaker) do(idempotent bool, fn func() error) error {
+	for {
+		if err := b.allow(); err != nil {
+			return err
+		}
+		err := fn()
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		b.record(err)
+		if err == nil || !isClusterFailure(err) {
+			return err
+		}
+		if !idempotent && outcomeUnknown(err) {
+			return err
+		}
+		b.logger.Warn().Err(err).Msg("Statement failed with a cluster error, retrying")
+	}
+}
+
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil || !isClusterFailure(err) {
+		b.failures = 0
+		if b.state != breakerClosed {
+			b.transition(breakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	switch b.state {
+	case breakerHalfOpen:
+		// Trial request failed: reopen, but keep the original openedAt so
+		// max_open bounds the total outage we are willing to wait out
+		b.transition(breakerOpen)
+	case breakerClosed:
+		if b.failures >= b.cfg.FailureThreshold {
+			b.openedAt = b.now()
+			b.transition(breakerOpen)
+		}
+	}
+}
+
+func (b *circuitBreaker) transition(to breakerState) {
+	if b.state == to {
+		return
+	}
+	ev := b.logger.Info()
+	if to == breakerOpen {
+		ev = b.logger.Warn()
+	}
+	ev.Str("from", b.state.String()).
+		Str("to", to.String()).
+		Int("consecutive_failures", b.failures).
+		Msg("Circuit breaker state changed")
+	b.state = to
+}
+
+// isClusterFailure reports whether err means the cluster (rather than the
+// statement) is at fault: the coordinator reported too few replicas, or no
+// connection could carry the request. Every other error, timeouts reported
+// by the coordinator included, proves it is alive and does not count
+// towards opening the breaker.
+func isClusterFailure(err error) bool {
+	var reqErr gocql.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.Code() == gocql.ErrCodeUnavailable
+	}
+	return isConnectionError(err)
+}
+
+func isConnectionError(err error) bool {
+	switch {
+	case errors.Is(err, gocql.ErrNoConnections),
+		errors.Is(err, gocql.ErrUnavailable),
+		errors.Is(err, gocql.ErrNoStreams),
+		errors.Is(err, gocql.ErrConnectionClosed),
+		errors.Is(err, gocql.ErrTimeoutNoResponse),
+		errors.Is(err, gocql.ErrTooManyTimeouts):
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// outcomeUnknown reports whether the statement may have been applied despite
+// err: the request reached a node but no answer came back.
+func outcomeUnknown(err error) bool {
+	var reqErr gocql.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.Code() == gocql.ErrCodeWriteTimeout
+	}
+	switch {
+	case errors.Is(err, gocql.ErrConnectionClosed),
+		errors.Is(err, gocql.ErrTimeoutNoResponse),
+		errors.Is(err, gocql.ErrTooManyTimeouts),
+		errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,189 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+type fakeRequestError struct{ code int }
+
+func (e fakeRequestError) Code() int       { return e.code }
+func (e fakeRequestError) Message() string { return "fake" }
+func (e fakeRequestError) Error() string   { return "fake request error" }
+
+func newTestBreaker() (*circuitBreaker, *time.Time, *[]time.Duration) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	b := newCircuitBreaker(config.BreakerConfig{
+		Enabled:          true,
+		FailureThreshold: 3,
+		Cooldown:         5 * time.Second,
+		MaxOpen:          12 * time.Second,
+	}, zerolog.Nop())
+	b.now = func() time.Time { return clock }
+	b.sleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+		clock = clock.Add(d)
+	}
+	return b, &clock, &sleeps
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	b, _, sleeps := newTestBreaker()
+	down := gocql.ErrNoConnections
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.allow())
+		b.record(down)
+	}
+	assert.Equal(t, breakerClosed, b.state)
+
+	require.NoError(t, b.allow())
+	b.record(down)
+	assert.Equal(t, breakerOpen, b.state)
+
+	// Next attempt waits for the cooldown and is let through half-open
+	require.NoError(t, b.allow())
+	assert.Equal(t, []time.Duration{5 * time.Second}, *sleeps)
+	assert.Equal(t, breakerHalfOpen, b.state)
+
+	b.record(nil)
+	assert.Equal(t, breakerClosed, b.state)
+	assert.Equal(t, 0, b.failures)
+}
+
+func TestCircuitBreaker_AbortsAfterMaxOpen(t *testing.T) {
+	b, _, _ := newTestBreaker()
+	for i := 0; i < 3; i++ {
+		b.record(gocql.ErrTimeoutNoResponse)
+	}
+
+	// Each failed half-open trial costs a 5s cooldown; after three the
+	// breaker has been open for 15s, past max_open
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.allow())
+		b.record(gocql.ErrTimeoutNoResponse)
+		assert.Equal(t, breakerOpen, b.state)
+	}
+
+	err := b.allow()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+}
+
+func TestCircuitBreaker_StatementErrorsDoNotCount(t *testing.T) {
+	b, _, _ := newTestBreaker()
+	for i := 0; i < 5; i++ {
+		b.record(fakeRequestError{code: gocql.ErrCodeSyntax})
+	}
+	assert.Equal(t, breakerClosed, b.state)
+
+	assert.True(t, isClusterFailure(fakeRequestError{code: gocql.ErrCodeUnavailable}))
+	assert.True(t, isClusterFailure(gocql.ErrNoConnections))
+	assert.False(t, isClusterFailure(fakeRequestError{code: gocql.ErrCodeInvalid}))
+	assert.False(t, isClusterFailure(fakeRequestError{code: gocql.ErrCodeWriteTimeout}))
+	assert.False(t, isClusterFailure(fakeRequestError{code: gocql.ErrCodeServer}))
+	assert.False(t, isClusterFailure(errors.New("gocql: something else")))
+}
+
+func TestCircuitBreaker_DoRetriesClusterFailures(t *testing.T) {
+	b, _, sleeps := newTestBreaker()
+
+	// Fails four times, opening the breaker after three, then recovers
+	calls := 0
+	err := b.do(false, func() error {
+		calls++
+		if calls <= 4 {
+			return gocql.ErrNoConnections
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second}, *sleeps)
+	assert.Equal(t, breakerClosed, b.state)
+}
+
+func TestCircuitBreaker_DoAbortsAfterMaxOpen(t *testing.T) {
+	b, _, _ := newTestBreaker()
+	calls := 0
+	err := b.do(false, func() error {
+		calls++
+		return gocql.ErrNoConnections
+	})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	// three to open, then three half-open trials before max_open passes
+	assert.Equal(t, 6, calls)
+}
+
+func TestCircuitBreaker_DoReturnsStatementErrors(t *testing.T) {
+	b, _, _ := newTestBreaker()
+	calls := 0
+	syntax := fakeRequestError{code: gocql.ErrCodeSyntax}
+	err := b.do(false, func() error {
+		calls++
+		return syntax
+	})
+	assert.Equal(t, syntax, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestCircuitBreaker_DoLeavesCancellationUnrecorded(t *testing.T) {
+	b, _, _ := newTestBreaker()
+	for i := 0; i < 2; i++ {
+		b.record(gocql.ErrNoConnections)
+	}
+
+	calls := 0
+	err := b.do(false, func() error {
+		calls++
+		return context.Canceled
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 2, b.failures, "a cancelled call neither counts nor resets the failures")
+}
+
+func TestCircuitBreaker_DoDoesNotRepeatWriteTimeouts(t *testing.T) {
+	b, _, _ := newTestBreaker()
+	calls := 0
+	timeout := fakeRequestError{code: gocql.ErrCodeWriteTimeout}
+	err := b.do(false, func() error {
+		calls++
+		return timeout
+	})
+	assert.Equal(t, timeout, err)
+	assert.Equal(t, 1, calls, "a write that may have applied is not executed again")
+	assert.Equal(t, 0, b.failures)
+}
+
+func TestCircuitBreaker_DoRetriesUnknownOutcomeOnlyWhenIdempotent(t *testing.T) {
+	b, _, _ := newTestBreaker()
+	calls := 0
+	err := b.do(false, func() error {
+		calls++
+		return gocql.ErrTimeoutNoResponse
+	})
+	assert.ErrorIs(t, err, gocql.ErrTimeoutNoResponse)
+	assert.Equal(t, 1, calls)
+
+	b, _, _ = newTestBreaker()
+	calls = 0
+	err = b.do(true, func() error {
+		calls++
+		if calls == 1 {
+			return gocql.ErrTimeoutNoResponse
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,238 @@
+package driver
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+)
+
+// DDLCoordinatorAuto pins DDL to whichever host serves the first DDL
+// statement of the session.
+const DDLCoordinatorAuto = "auto"
+
+type ddlContextKey struct{}
+
+// withDDL marks a query context so ddlPinningPolicy routes it to the pinned
+// coordinator.
+func withDDL(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ddlContextKey{}, true)
+}
+
+func isDDLQuery(q gocql.ExecutableQuery) bool {
+	query, ok := q.(*gocql.Query)
+	if !ok {
+		return false
+	}
+	marked, _ := query.Context().Value(ddlContextKey{}).(bool)
+	return marked
+}
+
+// ddlPinningPolicy sends every DDL statement to one coordinator so schema
+// changes do not bounce between nodes, and leaves all other queries to the
+// wrapped policy. When the pinned host is down, DDL falls back to normal
+// routing rather than failing the run.
+type ddlPinningPolicy struct {
+	gocql.HostSelectionPolicy
+	logger zerolog.Logger
+
+	// want is "auto" or the configured address; port is 0 when any port
+	// matches
+	want string
+	ips  []net.IP
+	port int
+
+	mu     sync.Mutex
+	hosts  map[string]*gocql.HostInfo
+	pinned *gocql.HostInfo
+	warned bool
+}
+
+func newDDLPinningPolicy(coordinator string, fallback gocql.HostSelectionPolicy, logger zerolog.Logger) *ddlPinningPolicy {
+	p := &ddlPinningPolicy{
+		HostSelectionPolicy: fallback,
+		logger:              logger,
+		want:                coordinator,
+		hosts:               make(map[string]*gocql.HostInfo),
+	}
+	if coordinator == DDLCoordinatorAuto {
+		return p
+	}
+
+	host := coordinator
+	if h, port, err := net.SplitHostPort(coordinator); err == nil {
+		host = h
+		p.port, _ = strconv.Atoi(port)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		p.ips = []net.IP{ip}
+	} else if ips, err := net.LookupIP(host); err == nil {
+		p.ips = ips
+	} else {
+		logger.Warn().Err(err).Str("ddl_coordinator", coordinator).Msg("Failed to resolve DDL coordinator")
+	}
+	return p
+}
+
+func (p *ddlPinningPolicy) AddHost(host *gocql.HostInfo) {
+	p.mu.Lock()
+	p.hosts[host.HostID()] = host
+	p.mu.Unlock()
+	p.HostSelectionPolicy.AddHost(host)
+}
+
+func (p *ddlPinningPolicy) RemoveHost(host *gocql.HostInfo) {
+	p.mu.Lock()
+	delete(p.hosts, host.HostID())
+	p.mu.Unlock()
+	p.HostSelectionPolicy.RemoveHost(host)
+}
+
+func (p *ddlPinningPolicy) HostUp(host *gocql.HostInfo) {
+	p.mu.Lock()
+	p.hosts[host.HostID()] = host
+	p.mu.Unlock()
+	p.HostSelectionPolicy.HostUp(host)
+}
+
+func (p *ddlPinningPolicy) Pick(q gocql.ExecutableQuery) gocql.NextHost {
+	next := p.HostSelectionPolicy.Pick(q)
+	if !isDDLQuery(q) {
+		return next
+	}
+
+	if pinned := p.coordinator(); pinned != nil {
+		return prepend(&pinnedHost{info: pinned}, pinned, next)
+	}
+	if p.want != DDLCoordinatorAuto || p.hasPinned() {
+		return next
+	}
+
+	// auto mode pins the first host the wrapped policy picks
+	first := next()
+	if first == nil {
+		return next
+	}
+	return prepend(first, p.pin(first.Info()), next)
+}
+
+func (p *ddlPinningPolicy) hasPinned() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pinned != nil
+}
+
+// coordinator returns the pinned host if it is up, resolving a configured
+// address against the known hosts on first use.
+func (p *ddlPinningPolicy) coordinator() *gocql.HostInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pinned == nil && p.want != DDLCoordinatorAuto {
+		for _, h := range p.hosts {
+			if p.matches(h) {
+				p.pinned = h
+				p.logger.Info().Str("host", h.ConnectAddressAndPort()).Msg("DDL pinned to coordinator")
+				break
+			}
+		}
+	}
+
+	if p.pinned != nil && p.pinned.IsUp() {
+		return p.pinned
+	}
+	if !p.warned && (p.pinned != nil || p.want != DDLCoordinatorAuto) {
+		p.warned = true
+		p.logger.Warn().Str("ddl_coordinator", p.want).Msg("DDL coordinator unavailable, routing DDL normally")
+	}
+	return nil
+}
+
+func (p *ddlPinningPolicy) pin(host *gocql.HostInfo) *gocql.HostInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pinned == nil {
+		p.pinned = host
+		p.logger.Info().Str("host", host.ConnectAddressAndPort()).Msg("DDL pinned to coordinator")
+	}
+	return p.pinned
+}
+
+func (p *ddlPinningPolicy) matches(h *gocql.HostInfo) bool {
+	if p.port != 0 && h.Port() != p.port {
+		return false
+	}
+	for _, ip := range p.ips {
+		if ip.Equal(h.ConnectAddress()) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepend yields first, then the hosts of next other than pinned, so a
+// failed DDL attempt can still be retried elsewhere.
+func prepend(first gocql.SelectedHost, pinned *gocql.HostInfo, next gocql.NextHost) gocql.NextHost {
+	used := false
+	return func() gocql.SelectedHost {
+		if !used {
+			used = true
+			return first
+		}
+		for {
+			h := next()
+			if h == nil || !h.Info().Equal(pinned) {
+				return h
+			}
+		}
+	}
+}
+
+type pinnedHost struct {
+	info *gocql.HostInfo
+}
+
+func (h *pinnedHost) Info() *gocql.HostInfo { return h.info }
+func (h *pinnedHost) Mark(error)            {}
+
+type hostContextKey struct{}
+
+// withHost marks a query context so hostPinningPolicy sends it to host only.
+func withHost(ctx context.Context, host *gocql.HostInfo) context.Context {
+	return context.WithValue(ctx, hostContextKey{}, host)
+}
+
+func queryHost(q gocql.ExecutableQuery) *gocql.HostInfo {
+	query, ok := q.(*gocql.Query)
+	if !ok {
+		return nil
+	}
+	host, _ := query.Context().Value(hostContextKey{}).(*gocql.HostInfo)
+	return host
+}
+
+// hostPinningPolicy sends queries marked with withHost to that host and no
+// other, so reads that must see one node's view (system.local together with
+// system.peers) fail rather than mix coordinators. Other queries go to the
+// wrapped policy.
+type hostPinningPolicy struct {
+	gocql.HostSelectionPolicy
+}
+
+func (p *hostPinningPolicy) Pick(q gocql.ExecutableQuery) gocql.NextHost {
+	host := queryHost(q)
+	if host == nil {
+		return p.HostSelectionPolicy.Pick(q)
+	}
+	used := false
+	return func() gocql.SelectedHost {
+		if used {
+			return nil
+		}
+		used = true
+		return &pinnedHost{info: host}
+	}
+}
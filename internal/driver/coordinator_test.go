@@ -0,0 +1,65 @@
+package driver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDDLQuery(t *testing.T) {
+	q := &gocql.Query{}
+	assert.False(t, isDDLQuery(q))
+	assert.True(t, isDDLQuery(q.WithContext(withDDL(context.Background()))))
+}
+
+func TestDDLPinningPolicyMatchesConfiguredAddress(t *testing.T) {
+	p := newDDLPinningPolicy("10.0.0.2", gocql.RoundRobinHostPolicy(), zerolog.Nop())
+
+	assert.True(t, p.matches((&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("10.0.0.2"))))
+	assert.False(t, p.matches((&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("10.0.0.3"))))
+
+	withPort := newDDLPinningPolicy("10.0.0.2:19042", gocql.RoundRobinHostPolicy(), zerolog.Nop())
+	assert.Equal(t, 19042, withPort.port)
+	// HostInfo without a port never matches a configured port
+	assert.False(t, withPort.matches((&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("10.0.0.2"))))
+}
+
+func TestPrependSkipsPinnedHost(t *testing.T) {
+	pinned := (&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("10.0.0.1"))
+	other := (&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("10.0.0.2"))
+	pinned.SetHostID("a")
+	other.SetHostID("b")
+
+	hosts := []*gocql.HostInfo{pinned, other}
+	next := func() gocql.SelectedHost {
+		if len(hosts) == 0 {
+			return nil
+		}
+		h := hosts[0]
+		hosts = hosts[1:]
+		return &pinnedHost{info: h}
+	}
+
+	it := prepend(&pinnedHost{info: pinned}, pinned, next)
+	assert.Equal(t, "a", it().Info().HostID())
+	assert.Equal(t, "b", it().Info().HostID())
+	assert.Nil(t, it())
+}
+
+func TestHostPinningPolicy(t *testing.T) {
+	p := &hostPinningPolicy{HostSelectionPolicy: gocql.RoundRobinHostPolicy()}
+	host := (&gocql.HostInfo{}).SetConnectAddress(net.ParseIP("10.0.0.1"))
+	host.SetHostID("a")
+
+	q := (&gocql.Query{}).WithContext(withHost(context.Background(), host))
+	assert.Same(t, host, queryHost(q))
+	next := p.Pick(q)
+	assert.Equal(t, "a", next().Info().HostID())
+	assert.Nil(t, next(), "a pinned query never moves to another host")
+
+	assert.Nil(t, queryHost(&gocql.Query{}))
+}
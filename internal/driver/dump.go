@@ -0,0 +1,147 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Column kinds as stored in system_schema.columns.
+const (
+	KindPartitionKey = "partition_key"
+	KindClustering   = "clustering"
+	KindRegular      = "regular"
+	KindStatic       = "static"
+)
+
+type ColumnDump struct {
+	Name string
+	Type string
+	Kind string
+	// Position orders partition key and clustering columns; -1 otherwise.
+	Position int
+	// ClusteringOrder is "asc" or "desc" for clustering columns.
+	ClusteringOrder string
+}
+
+type TableDump struct {
+	Name    string
+	Columns map[string]ColumnDump
+}
+
+// KeyColumns returns the names of the columns of the given kind in key order.
+func (t *TableDump) KeyColumns(kind string) []string {
+	var cols []ColumnDump
+	for _, c := range t.Columns {
+		if c.Kind == kind {
+			cols = append(cols, c)
+		}
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].Position < cols[j].Position })
+
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return names
+}
+
+type FieldDump struct {
+	Name string
+	Type string
+}
+
+type TypeDump struct {
+	Name   string
+	Fields []FieldDump
+}
+
+type IndexDump struct {
+	Name   string
+	Table  string
+	Target string
+}
+
+// KeyspaceDump is the table, user-defined type and secondary index layout of
+// a keyspace. Materialized views are not included.
+type KeyspaceDump struct {
+	Name    string
+	Tables  map[string]*TableDump
+	Types   map[string]*TypeDump
+	Indexes map[string]*IndexDump
+}
+
+func NewKeyspaceDump(name string) *KeyspaceDump {
+	return &KeyspaceDump{
+		Name:    name,
+		Tables:  make(map[string]*TableDump),
+		Types:   make(map[string]*TypeDump),
+		Indexes: make(map[string]*IndexDump),
+	}
+}
+
+// DumpKeyspace reads the layout of a keyspace from system_schema.
+func (s *Session) DumpKeyspace(keyspace string) (*KeyspaceDump, error) {
+	dump := NewKeyspaceDump(keyspace)
+
+	iter := s.session.Query("SELECT table_name FROM system_schema.tables WHERE keyspace_name = ?", keyspace).Iter()
+	var table string
+	for iter.Scan(&table) {
+		dump.Tables[table] = &TableDump{Name: table, Columns: make(map[string]ColumnDump)}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	// system_schema.columns also holds view columns; keep base tables only
+	iter = s.session.Query(
+		"SELECT table_name, column_name, type, kind, position, clustering_order FROM system_schema.columns WHERE keyspace_name = ?",
+		keyspace,
+	).Iter()
+	var c ColumnDump
+	for iter.Scan(&table, &c.Name, &c.Type, &c.Kind, &c.Position, &c.ClusteringOrder) {
+		if t, ok := dump.Tables[table]; ok {
+			if c.Kind != KindClustering {
+				c.ClusteringOrder = ""
+			}
+			t.Columns[c.Name] = c
+		}
+		c = ColumnDump{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+
+	iter = s.session.Query(
+		"SELECT type_name, field_names, field_types FROM system_schema.types WHERE keyspace_name = ?", keyspace,
+	).Iter()
+	var typeName string
+	var fieldNames, fieldTypes []string
+	for iter.Scan(&typeName, &fieldNames, &fieldTypes) {
+		t := &TypeDump{Name: typeName}
+		for i := range fieldNames {
+			if i < len(fieldTypes) {
+				t.Fields = append(t.Fields, FieldDump{Name: fieldNames[i], Type: fieldTypes[i]})
+			}
+		}
+		dump.Types[typeName] = t
+		fieldNames, fieldTypes = nil, nil
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list types: %w", err)
+	}
+
+	iter = s.session.Query(
+		"SELECT index_name, table_name, options FROM system_schema.indexes WHERE keyspace_name = ?", keyspace,
+	).Iter()
+	var indexName string
+	var options map[string]string
+	for iter.Scan(&indexName, &table, &options) {
+		dump.Indexes[indexName] = &IndexDump{Name: indexName, Table: table, Target: options["target"]}
+		options = nil
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	return dump, nil
+}
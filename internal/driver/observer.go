@@ -0,0 +1,88 @@
+package driver
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+// NopObserver implements every gocql observer hook and ignores all events.
+// Embed it to implement only the hooks you need.
+type NopObserver struct{}
+
+func (NopObserver) ObserveQuery(context.Context, gocql.ObservedQuery)             {}
+func (NopObserver) ObserveConnect(gocql.ObservedConnect)                          {}
+func (NopObserver) ObserveFrameHeader(context.Context, gocql.ObservedFrameHeader) {}
+
+// LatencyObserver logs each query attempt and connection the driver makes.
+// It sees what the executor cannot: the host every attempt went to and the
+// retries gocql did on its own. Failures and attempts slower than
+// SlowThreshold (0 = never slow) are warnings; the rest are debug.
+type LatencyObserver struct {
+	NopObserver
+	Logger        zerolog.Logger
+	SlowThreshold time.Duration
+}
+
+func (o *LatencyObserver) ObserveQuery(_ context.Context, q gocql.ObservedQuery) {
+	latency := q.End.Sub(q.Start)
+	ev := o.Logger.Debug()
+	switch {
+	case q.Err != nil:
+		ev = o.Logger.Warn().Err(q.Err)
+	case o.SlowThreshold > 0 && latency >= o.SlowThreshold:
+		ev = o.Logger.Warn().Bool("slow", true)
+	}
+	host := ""
+	if q.Host != nil {
+		host = q.Host.ConnectAddressAndPort()
+	}
+	ev.Str("query", truncate(q.Statement, 200)).
+		Str("host", host).
+		Int("attempt", q.Attempt+1).
+		Int("rows", q.Rows).
+		Dur("latency", latency).
+		Msg("Driver query")
+}
+
+func (o *LatencyObserver) ObserveConnect(c gocql.ObservedConnect) {
+	ev := o.Logger.Debug()
+	if c.Err != nil {
+		ev = o.Logger.Warn().Err(c.Err)
+	}
+	host := ""
+	if c.Host != nil {
+		host = c.Host.ConnectAddressAndPort()
+	}
+	ev.Str("host", host).Dur("latency", c.End.Sub(c.Start)).Msg("Driver connect")
+}
+
+// applyObservers installs the observers set in cfg.Observers, falling back
+// to a LatencyObserver for hooks left unset when query_log is enabled.
+func applyObservers(cluster *gocql.ClusterConfig, cfg *config.Config, logger zerolog.Logger) {
+	var fallback *LatencyObserver
+	if cfg.QueryLog.Enabled {
+		fallback = &LatencyObserver{Logger: logger, SlowThreshold: cfg.QueryLog.SlowThreshold}
+	}
+
+	obs := cfg.Observers
+	switch {
+	case obs.Query != nil:
+		cluster.QueryObserver = obs.Query
+	case fallback != nil:
+		cluster.QueryObserver = fallback
+	}
+	switch {
+	case obs.Connect != nil:
+		cluster.ConnectObserver = obs.Connect
+	case fallback != nil:
+		cluster.ConnectObserver = fallback
+	}
+	if obs.FrameHeader != nil {
+		cluster.FrameHeaderObserver = obs.FrameHeader
+	}
+}
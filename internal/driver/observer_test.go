@@ -0,0 +1,48 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+func TestLatencyObserver_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	o := &LatencyObserver{Logger: zerolog.New(&buf).Level(zerolog.InfoLevel), SlowThreshold: time.Second}
+	start := time.Now()
+
+	o.ObserveQuery(context.Background(), gocql.ObservedQuery{Statement: "SELECT 1", Start: start, End: start.Add(10 * time.Millisecond)})
+	assert.Empty(t, buf.String(), "fast queries are debug only")
+
+	o.ObserveQuery(context.Background(), gocql.ObservedQuery{Statement: "SELECT 2", Start: start, End: start.Add(2 * time.Second), Attempt: 1})
+	assert.Contains(t, buf.String(), `"slow":true`)
+	assert.Contains(t, buf.String(), `"attempt":2`)
+
+	buf.Reset()
+	o.ObserveQuery(context.Background(), gocql.ObservedQuery{Statement: "SELECT 3", Start: start, End: start, Err: errors.New("timeout")})
+	assert.Contains(t, buf.String(), `"error":"timeout"`)
+}
+
+func TestApplyObservers(t *testing.T) {
+	cluster := gocql.NewCluster("localhost")
+	applyObservers(cluster, &config.Config{}, zerolog.Nop())
+	assert.Nil(t, cluster.QueryObserver)
+	assert.Nil(t, cluster.ConnectObserver)
+
+	custom := NopObserver{}
+	cluster = gocql.NewCluster("localhost")
+	applyObservers(cluster, &config.Config{
+		QueryLog:  config.QueryLogConfig{Enabled: true},
+		Observers: config.DriverObservers{Query: custom},
+	}, zerolog.Nop())
+	assert.Equal(t, custom, cluster.QueryObserver)
+	assert.IsType(t, &LatencyObserver{}, cluster.ConnectObserver)
+}
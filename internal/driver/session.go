@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -24,21 +25,39 @@ type ClusterMetadata struct {
 type Session struct {
 	session *gocql.Session
 	config  *config.Config
+	breaker *circuitBreaker
 	Logger  zerolog.Logger
 }
 
 func NewSession(cfg *config.Config, logger zerolog.Logger) (*Session, error) {
+	// Credentials are fetched only once a connection is needed, so offline
+	// commands never depend on Vault
+	if err := cfg.ResolveCredentials(); err != nil {
+		return nil, err
+	}
+
 	cluster := gocql.NewCluster(cfg.Hosts...)
 	cluster.Consistency = mustConsistency(cfg.Consistency)
 	cluster.Timeout = cfg.Timeout
 	cluster.ConnectTimeout = cfg.ConnectionTimeout
 	cluster.ProtoVersion = cfg.ProtocolVersion
+	if cfg.BindKeyspace {
+		cluster.Keyspace = cfg.Keyspace
+	}
 	cluster.RetryPolicy = &gocql.ExponentialBackoffRetryPolicy{
 		NumRetries: cfg.MaxRetries,
 		Min:        500 * time.Millisecond,
 		Max:        5 * time.Second,
 	}
 
+	applyObservers(cluster, cfg, logger)
+
+	var policy gocql.HostSelectionPolicy = gocql.RoundRobinHostPolicy()
+	if cfg.DDLCoordinator != "" {
+		policy = newDDLPinningPolicy(cfg.DDLCoordinator, policy, logger)
+	}
+	cluster.PoolConfig.HostSelectionPolicy = &hostPinningPolicy{HostSelectionPolicy: policy}
+
 	if cfg.Username != "" {
 		cluster.Authenticator = gocql.PasswordAuthenticator{
 			Username: cfg.Username,
@@ -68,29 +87,108 @@ func NewSession(cfg *config.Config, logger zerolog.Logger) (*Session, error) {
 
 	logger.Info().Msg("Connected to cluster")
 
-	return &Session{
+	s := &Session{
 		session: session,
 		config:  cfg,
 		Logger:  logger,
-	}, nil
+	}
+	if cfg.CircuitBreaker.Enabled {
+		s.breaker = newCircuitBreaker(cfg.CircuitBreaker, logger)
+	}
+
+	// Preamble statements prepare the session; a failure must stop us
+	// before any migration runs against a half-configured session
+	if err := runPreamble(s.Execute, cfg.SessionPreamble); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// runPreamble executes the session_preamble statements in order and stops
+// at the first failure. Each runs once, on whichever pooled connection the
+// driver picks, so connection-scoped settings do not carry over to the
+// statements that follow.
+func runPreamble(exec func(string, ...interface{}) error, stmts []string) error {
+	for i, stmt := range stmts {
+		if err := exec(stmt); err != nil {
+			return fmt.Errorf("session preamble statement %d failed: %w", i+1, err)
+		}
+	}
+	return nil
 }
 
 func (s *Session) Close() {
 	if s.session != nil && !s.session.Closed() {
+		for i, stmt := range s.config.SessionEpilogue {
+			if err := s.Execute(stmt); err != nil {
+				s.Logger.Warn().Err(err).Int("statement", i+1).Msg("Session epilogue statement failed")
+			}
+		}
 		s.session.Close()
 		s.Logger.Debug().Msg("Session closed")
 	}
 }
 
 func (s *Session) Execute(query string, args ...interface{}) error {
+	return s.ExecuteContext(context.Background(), query, args...)
+}
+
+// ExecuteContext is Execute, abandoned (and not retried) once ctx is done.
+func (s *Session) ExecuteContext(ctx context.Context, query string, args ...interface{}) error {
+	return s.exec(ctx, query, s.session.Query(query, args...))
+}
+
+// ExecuteDDL runs a schema change. With ddl_coordinator set it goes to the
+// pinned coordinator instead of being spread across the cluster.
+func (s *Session) ExecuteDDL(query string, args ...interface{}) error {
+	return s.ExecuteDDLContext(context.Background(), query, args...)
+}
+
+// ExecuteDDLContext is ExecuteDDL, abandoned (and not retried) once ctx is
+// done.
+func (s *Session) ExecuteDDLContext(ctx context.Context, query string, args ...interface{}) error {
+	if s.config.DDLCoordinator != "" {
+		ctx = withDDL(ctx)
+	}
+	return s.exec(ctx, query, s.session.Query(query, args...))
+}
+
+func (s *Session) exec(ctx context.Context, query string, q *gocql.Query) error {
 	s.Logger.Debug().Str("query", truncate(query, 200)).Msg("Executing query")
-	return s.session.Query(query, args...).Exec()
+	q = q.WithContext(ctx)
+	if s.breaker == nil {
+		return q.Exec()
+	}
+	return s.breaker.do(q.IsIdempotent(), func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return q.Exec()
+	})
 }
 
 func (s *Session) Query(query string, args ...interface{}) *gocql.Query {
 	return s.session.Query(query, args...)
 }
 
+// QueryWithSpeculation builds a read query that may be sent speculatively to
+// additional hosts when speculative_execution is enabled. Use it only for
+// idempotent reads: never for writes, DDL, or LWT, where a duplicate
+// execution would be harmful.
+func (s *Session) QueryWithSpeculation(query string, args ...interface{}) *gocql.Query {
+	q := s.session.Query(query, args...)
+	spec := s.config.SpeculativeExecution
+	if !spec.Enabled {
+		return q
+	}
+	return q.Idempotent(true).SetSpeculativeExecutionPolicy(&gocql.SimpleSpeculativeExecution{
+		NumAttempts:  spec.Attempts,
+		TimeoutDelay: spec.Delay,
+	})
+}
+
 func (s *Session) WaitForSchemaAgreement(timeout time.Duration) error {
 	s.Logger.Debug().Dur("timeout", timeout).Msg("Waiting for schema agreement")
 
@@ -98,6 +196,9 @@ func (s *Session) WaitForSchemaAgreement(timeout time.Duration) error {
 	defer cancel()
 
 	if err := s.session.AwaitSchemaAgreement(ctx); err != nil {
+		if disagreement := s.CheckSchemaAgreement(); disagreement != nil {
+			return fmt.Errorf("schema agreement not reached within %s (%s): %w", timeout, disagreement, err)
+		}
 		return fmt.Errorf("schema agreement not reached within %s: %w", timeout, err)
 	}
 
@@ -140,6 +241,20 @@ func (s *Session) GetClusterMetadata() (*ClusterMetadata, error) {
 	return meta, nil
 }
 
+func (s *Session) ListKeyspaces() ([]string, error) {
+	iter := s.session.Query("SELECT keyspace_name FROM system_schema.keyspaces").Iter()
+	var keyspaces []string
+	var ks string
+	for iter.Scan(&ks) {
+		keyspaces = append(keyspaces, ks)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	sort.Strings(keyspaces)
+	return keyspaces, nil
+}
+
 func (s *Session) KeyspaceExists(keyspace string) (bool, error) {
 	var count int
 	err := s.session.Query(
@@ -152,6 +267,31 @@ func (s *Session) KeyspaceExists(keyspace string) (bool, error) {
 	return count > 0, nil
 }
 
+// ObjectExists reports whether a table, user-defined type or index exists,
+// according to system_schema on the coordinator. table is only used for
+// indexes.
+func (s *Session) ObjectExists(kind, keyspace, name, table string) (bool, error) {
+	var query string
+	args := []interface{}{keyspace, name}
+	switch kind {
+	case "table":
+		query = "SELECT COUNT(*) FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?"
+	case "type":
+		query = "SELECT COUNT(*) FROM system_schema.types WHERE keyspace_name = ? AND type_name = ?"
+	case "index":
+		query = "SELECT COUNT(*) FROM system_schema.indexes WHERE keyspace_name = ? AND table_name = ? AND index_name = ?"
+		args = []interface{}{keyspace, table, name}
+	default:
+		return false, fmt.Errorf("unsupported object kind %q", kind)
+	}
+
+	var count int
+	if err := s.session.Query(query, args...).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func buildTLSConfig(ssl config.SSLConfig) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: ssl.SkipVerify,
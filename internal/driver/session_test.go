@@ -0,0 +1,29 @@
+package driver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPreamble(t *testing.T) {
+	var ran []string
+	exec := func(stmt string, _ ...interface{}) error {
+		ran = append(ran, stmt)
+		if stmt == "bad" {
+			return errors.New("syntax error")
+		}
+		return nil
+	}
+
+	require.NoError(t, runPreamble(exec, []string{"a", "b"}))
+	assert.Equal(t, []string{"a", "b"}, ran)
+
+	ran = nil
+	err := runPreamble(exec, []string{"a", "bad", "c"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session preamble statement 2 failed")
+	assert.Equal(t, []string{"a", "bad"}, ran)
+}
@@ -0,0 +1,109 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// AllowDuplicatesDirective turns off CheckDuplicates for a migration whose
+// repeated statements are intentional:
+//
+//	-- scylla-migrate:allow-duplicate-statements
+const AllowDuplicatesDirective = "allow-duplicate-statements"
+
+// CheckDuplicates warns about statements that repeat an earlier statement of
+// the same migration, usually a copy-paste mistake. Statements are compared
+// with whitespace collapsed outside quoted literals, together with their bind
+// args; comments are already stripped by the parser.
+func CheckDuplicates(migrations []*migration.Migration) []Issue {
+	var issues []Issue
+	for _, mig := range migrations {
+		if _, ok := mig.Directives[AllowDuplicatesDirective]; ok {
+			continue
+		}
+		lines := statementLines(mig)
+		first := make(map[string]int)
+		for i, stmt := range mig.Statements {
+			text := normalizeStatement(stmt)
+			key := text
+			if i < len(mig.Args) && len(mig.Args[i]) > 0 {
+				key += "\x00" + fmt.Sprint(mig.Args[i])
+			}
+			prev, seen := first[key]
+			if !seen {
+				first[key] = i
+				continue
+			}
+			issues = append(issues, Issue{
+				File:     mig.Filename,
+				Line:     lines[i],
+				Rule:     "duplicate-statement",
+				Severity: SeverityWarning,
+				Message: fmt.Sprintf("statement %d duplicates statement %d%s: %s (add -- scylla-migrate:%s if intended)",
+					i+1, prev+1, atLine(lines[prev]), truncate(text, 60), AllowDuplicatesDirective),
+			})
+		}
+	}
+	return issues
+}
+
+// normalizeStatement collapses each run of whitespace outside quoted
+// literals and identifiers ('...', "..." and $$...$$) into one space, so
+// reformatted statements compare equal but different string values do not.
+func normalizeStatement(stmt string) string {
+	var b strings.Builder
+	quote := ""
+	space := false
+	for i := 0; i < len(stmt); {
+		if quote != "" {
+			if strings.HasPrefix(stmt[i:], quote) {
+				b.WriteString(quote)
+				i += len(quote)
+				quote = ""
+				continue
+			}
+			b.WriteByte(stmt[i])
+			i++
+			continue
+		}
+
+		c := stmt[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			space = true
+			i++
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		switch {
+		case strings.HasPrefix(stmt[i:], "$$"):
+			quote = "$$"
+			b.WriteString(quote)
+			i += 2
+			continue
+		case c == '\'' || c == '"':
+			quote = string(c)
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+func atLine(line int) string {
+	if line == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (line %d)", line)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,65 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+func TestCheckDuplicates(t *testing.T) {
+	content := `CREATE INDEX IF NOT EXISTS ON users (email);
+
+CREATE TABLE t (id int PRIMARY KEY);
+
+-- copied by mistake
+CREATE INDEX IF NOT EXISTS
+    ON users (email);
+`
+	mig, err := migration.Parse("V001__indexes.cql", content)
+	require.NoError(t, err)
+
+	issues := CheckDuplicates([]*migration.Migration{mig})
+	require.Len(t, issues, 1)
+	assert.Equal(t, "duplicate-statement", issues[0].Rule)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+	assert.Equal(t, 6, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "statement 3 duplicates statement 1 (line 1)")
+
+	allowed, err := migration.Parse("V002__indexes.cql", "-- scylla-migrate:allow-duplicate-statements\n"+content)
+	require.NoError(t, err)
+	assert.Empty(t, CheckDuplicates([]*migration.Migration{allowed}))
+}
+
+func TestCheckDuplicates_QuotedWhitespace(t *testing.T) {
+	mig, err := migration.Parse("V001__seed.cql", `INSERT INTO settings (k, v) VALUES ('greeting', 'hello  world');
+INSERT INTO   settings (k, v)
+  VALUES ('greeting', 'hello world');
+INSERT INTO settings (k, v) VALUES ('greeting', 'hello world');`)
+	require.NoError(t, err)
+
+	issues := CheckDuplicates([]*migration.Migration{mig})
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, "statement 3 duplicates statement 2")
+}
+
+func TestCheckDuplicates_BindArgs(t *testing.T) {
+	mig, err := migration.Parse("V001__seed.cql", `INSERT INTO settings (k, v) VALUES (?, ?);
+INSERT INTO settings (k, v) VALUES (?, ?);
+INSERT INTO settings (k, v) VALUES (?, ?);`)
+	require.NoError(t, err)
+	mig.Args = [][]interface{}{{"a", 1.0}, {"b", 2.0}, {"a", 1.0}}
+
+	issues := CheckDuplicates([]*migration.Migration{mig})
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, "statement 3 duplicates statement 1")
+}
+
+func TestNormalizeStatement(t *testing.T) {
+	assert.Equal(t, "CREATE TABLE t (id int PRIMARY KEY)", normalizeStatement("CREATE  TABLE\n\tt (id int PRIMARY KEY)"))
+	assert.Equal(t, "SELECT 'a  b', \"My  Col\" FROM t", normalizeStatement("SELECT  'a  b',  \"My  Col\"\nFROM t"))
+	assert.Equal(t, "SELECT 'it''s  ok' FROM t", normalizeStatement("SELECT   'it''s  ok' FROM t"))
+	assert.Equal(t, "CREATE FUNCTION f() AS $$ a  b $$", normalizeStatement("CREATE FUNCTION f()  AS $$ a  b $$"))
+}
@@ -0,0 +1,25 @@
+package lint
+
+import (
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// CheckGaps reports gaps in the versioned sequence, on the file following
+// each gap. Gaps are warnings unless strict is set.
+func CheckGaps(migrations []*migration.Migration, strict bool) []Issue {
+	severity := SeverityWarning
+	if strict {
+		severity = SeverityError
+	}
+
+	var issues []Issue
+	for _, gap := range migration.FindVersionGaps(migrations) {
+		issues = append(issues, Issue{
+			File:     gap.Next,
+			Rule:     "gaps",
+			Severity: severity,
+			Message:  gap.String(),
+		})
+	}
+	return issues
+}
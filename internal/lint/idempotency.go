@@ -0,0 +1,151 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// Idempotency rules checked by CheckRepeatableIdempotency. Each flags a
+// statement that fails or changes data again when a repeatable re-runs.
+const (
+	RuleCreateIfNotExists  = "create-if-not-exists"
+	RuleDropIfExists       = "drop-if-exists"
+	RuleAlterSchema        = "alter-schema"
+	RuleInsertGeneratedKey = "insert-generated-key"
+	RuleIncrementalUpdate  = "incremental-update"
+)
+
+// IdempotencyRules lists every rule, in the order they are documented.
+var IdempotencyRules = []string{
+	RuleCreateIfNotExists,
+	RuleDropIfExists,
+	RuleAlterSchema,
+	RuleInsertGeneratedKey,
+	RuleIncrementalUpdate,
+}
+
+// generatedValueFunctions return a new value on every call, so a row keyed
+// by one of them is inserted again on each run.
+var generatedValueFunctions = map[string]bool{
+	"UUID": true, "NOW": true, "CURRENTTIMEUUID": true,
+}
+
+// CheckRepeatableIdempotency warns about statements in repeatable migrations
+// that are not safe to run twice. It is a heuristic over the statement text;
+// rules selects which checks run (nil means all of them).
+func CheckRepeatableIdempotency(migrations []*migration.Migration, rules []string) ([]Issue, error) {
+	enabled := make(map[string]bool)
+	if rules == nil {
+		rules = IdempotencyRules
+	}
+	for _, r := range rules {
+		if !isIdempotencyRule(r) {
+			return nil, fmt.Errorf("unknown lint.repeatable_idempotency rule %q (valid: %s)", r, strings.Join(IdempotencyRules, ", "))
+		}
+		enabled[r] = true
+	}
+
+	var issues []Issue
+	for _, mig := range migrations {
+		if mig.Type != migration.TypeRepeatable {
+			continue
+		}
+		lines := statementLines(mig)
+		for i, stmt := range mig.Statements {
+			rule, msg := nonIdempotent(tokenize(stmt))
+			if rule == "" || !enabled[rule] {
+				continue
+			}
+			issues = append(issues, Issue{
+				File:     mig.Filename,
+				Line:     lines[i],
+				Rule:     rule,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("statement %d: %s; it fails or duplicates data when the repeatable re-runs", i+1, msg),
+			})
+		}
+	}
+	return issues, nil
+}
+
+func isIdempotencyRule(name string) bool {
+	for _, r := range IdempotencyRules {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nonIdempotent returns the rule a statement breaks and why, or "" when it
+// is safe to repeat.
+func nonIdempotent(tokens []token) (string, string) {
+	if len(tokens) < 2 {
+		return "", ""
+	}
+	verb := tokens[0]
+
+	switch {
+	case verb.is("CREATE"):
+		object := tokens[1]
+		if object.is("OR") {
+			// CREATE OR REPLACE FUNCTION/AGGREGATE
+			return "", ""
+		}
+		kind := strings.ToUpper(object.text)
+		if object.is("MATERIALIZED") || object.is("CUSTOM") {
+			if len(tokens) < 3 {
+				return "", ""
+			}
+			kind = strings.ToUpper(object.text + " " + tokens[2].text)
+		}
+		if !hasSequence(tokens, "IF", "NOT", "EXISTS") {
+			return RuleCreateIfNotExists, fmt.Sprintf("CREATE %s without IF NOT EXISTS", kind)
+		}
+	case verb.is("DROP"):
+		if !hasSequence(tokens, "IF", "EXISTS") {
+			return RuleDropIfExists, fmt.Sprintf("DROP %s without IF EXISTS", strings.ToUpper(tokens[1].text))
+		}
+	case verb.is("ALTER") && (tokens[1].is("TABLE") || tokens[1].is("TYPE")):
+		for i, t := range tokens {
+			ifClause := i+1 < len(tokens) && tokens[i+1].is("IF")
+			if (t.is("ADD") || t.is("DROP") || t.is("RENAME")) && !ifClause {
+				return RuleAlterSchema, fmt.Sprintf("ALTER %s ... %s", strings.ToUpper(tokens[1].text), strings.ToUpper(t.text))
+			}
+		}
+	case verb.is("INSERT"):
+		for i, t := range tokens[:len(tokens)-1] {
+			if !t.quoted && generatedValueFunctions[strings.ToUpper(t.text)] && tokens[i+1].text == "(" {
+				return RuleInsertGeneratedKey, fmt.Sprintf("INSERT using %s(), which inserts a new row on every run", strings.ToLower(t.text))
+			}
+		}
+	case verb.is("UPDATE"):
+		// SET x = x + ... increments a counter or appends to a collection
+		for i := 0; i+3 < len(tokens); i++ {
+			if tokens[i+1].text == "=" && strings.EqualFold(tokens[i+2].text, tokens[i].text) && !tokens[i].quoted &&
+				(tokens[i+3].text == "+" || tokens[i+3].text == "-") {
+				return RuleIncrementalUpdate, fmt.Sprintf("UPDATE of %s relative to its current value", tokens[i].text)
+			}
+		}
+	}
+	return "", ""
+}
+
+// hasSequence reports whether the words appear consecutively in tokens.
+func hasSequence(tokens []token, words ...string) bool {
+	for i := 0; i+len(words) <= len(tokens); i++ {
+		match := true
+		for j, w := range words {
+			if !tokens[i+j].is(w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
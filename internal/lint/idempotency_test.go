@@ -0,0 +1,60 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+func TestCheckRepeatableIdempotency(t *testing.T) {
+	content := `-- Reporting objects
+CREATE TABLE app.report (id int PRIMARY KEY, hits counter);
+CREATE TABLE IF NOT EXISTS app.daily (day date PRIMARY KEY, tags list<text>);
+CREATE INDEX ON app.daily (tags);
+CREATE OR REPLACE FUNCTION app.f (x int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE lua AS 'return x';
+DROP TABLE app.old_report;
+ALTER TABLE app.daily ADD note text;
+ALTER TABLE app.daily ADD IF NOT EXISTS extra text;
+ALTER TABLE app.daily WITH comment = 'daily rollup';
+INSERT INTO app.events (id, kind) VALUES (uuid(), 'seed');
+INSERT INTO app.daily (day) VALUES ('2024-01-01');
+UPDATE app.report SET hits = hits + 1 WHERE id = 1;
+UPDATE app.daily SET tags = ['a'] WHERE day = '2024-01-01';
+`
+	rep, err := migration.Parse("R__reporting.cql", content)
+	require.NoError(t, err)
+	versioned, err := migration.Parse("V001__init.cql", "CREATE TABLE app.t (id int PRIMARY KEY);")
+	require.NoError(t, err)
+
+	issues, err := CheckRepeatableIdempotency([]*migration.Migration{rep, versioned}, nil)
+	require.NoError(t, err)
+
+	var got []string
+	for _, i := range issues {
+		assert.Equal(t, "R__reporting.cql", i.File)
+		assert.Equal(t, SeverityWarning, i.Severity)
+		got = append(got, i.Rule)
+	}
+	assert.Equal(t, []string{
+		RuleCreateIfNotExists, // CREATE TABLE app.report
+		RuleCreateIfNotExists, // CREATE INDEX
+		RuleDropIfExists,
+		RuleAlterSchema,
+		RuleInsertGeneratedKey,
+		RuleIncrementalUpdate,
+	}, got)
+	assert.Equal(t, 2, issues[0].Line)
+	assert.Equal(t, 12, issues[5].Line)
+
+	// Only the selected rules run
+	issues, err = CheckRepeatableIdempotency([]*migration.Migration{rep}, []string{RuleDropIfExists})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, RuleDropIfExists, issues[0].Rule)
+
+	_, err = CheckRepeatableIdempotency([]*migration.Migration{rep}, []string{"no-such-rule"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// SystemKeyspaces may always be referenced, e.g. to read system.local.
+var SystemKeyspaces = []string{
+	"system", "system_schema", "system_auth", "system_distributed", "system_traces",
+}
+
+// CheckKeyspaces warns about statements naming a keyspace outside allowed,
+// typically a hardcoded prefix copied from another environment. Both
+// keyspace-qualified names (other.users) and the keyspace of USE and
+// CREATE/ALTER/DROP KEYSPACE are checked. Unquoted names match allowed
+// case-insensitively, as CQL folds them to lower case.
+func CheckKeyspaces(migrations []*migration.Migration, allowed []string) []Issue {
+	allowed = append(append([]string(nil), allowed...), SystemKeyspaces...)
+
+	var issues []Issue
+	for _, mig := range migrations {
+		lines := statementLines(mig)
+		for i, stmt := range mig.Statements {
+			for _, ks := range referencedKeyspaces(tokenize(stmt)) {
+				if keyspaceAllowed(ks, allowed) {
+					continue
+				}
+				line := 0
+				if lines[i] > 0 {
+					line = lines[i] + ks.line
+				}
+				issues = append(issues, Issue{
+					File:     mig.Filename,
+					Line:     line,
+					Rule:     "keyspace",
+					Severity: SeverityWarning,
+					Message: fmt.Sprintf("statement %d: references keyspace %s, expected one of: %s",
+						i+1, ks.text, strings.Join(allowed, ", ")),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// nameKeywords precede the name of a schema object, which may be qualified
+// with its keyspace. Other dotted names (UDT fields) are not keyspaces.
+var nameKeywords = []string{
+	"TABLE", "COLUMNFAMILY", "TYPE", "VIEW", "INDEX", "FUNCTION", "AGGREGATE",
+	"INTO", "FROM", "UPDATE", "TRUNCATE", "ON",
+}
+
+// referencedKeyspaces returns the keyspace tokens of a statement, each
+// keyspace once.
+func referencedKeyspaces(tokens []token) []token {
+	var found []token
+	seen := make(map[string]bool)
+	add := func(t token) {
+		if !isIdentifier(t) {
+			return
+		}
+		key := t.text
+		if !t.quoted {
+			key = strings.ToLower(key)
+		}
+		if !seen[key] {
+			seen[key] = true
+			found = append(found, t)
+		}
+	}
+
+	for i, t := range tokens {
+		pos := i + 1
+		if pos+2 < len(tokens) && tokens[pos].is("IF") && tokens[pos+1].is("NOT") && tokens[pos+2].is("EXISTS") {
+			pos += 3
+		} else if pos+1 < len(tokens) && tokens[pos].is("IF") && tokens[pos+1].is("EXISTS") {
+			pos += 2
+		}
+		if pos >= len(tokens) {
+			break
+		}
+
+		switch {
+		case t.is("KEYSPACE") || (i == 0 && t.is("USE")):
+			add(tokens[pos])
+		case isNameKeyword(t):
+			if pos+1 < len(tokens) && tokens[pos+1].text == "." && !tokens[pos+1].quoted {
+				add(tokens[pos])
+			}
+		}
+	}
+	return found
+}
+
+func isNameKeyword(t token) bool {
+	for _, kw := range nameKeywords {
+		if t.is(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentifier(t token) bool {
+	if t.quoted {
+		return true
+	}
+	return t.text != "" && isWordRune(rune(t.text[0])) && (t.text[0] < '0' || t.text[0] > '9')
+}
+
+func keyspaceAllowed(ks token, allowed []string) bool {
+	for _, a := range allowed {
+		if ks.text == a || (!ks.quoted && strings.EqualFold(ks.text, a)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+func TestCheckKeyspaces(t *testing.T) {
+	content := `CREATE TABLE IF NOT EXISTS app.users (id UUID PRIMARY KEY, addr frozen<address>);
+
+CREATE TABLE app_staging.orders (id UUID PRIMARY KEY);
+
+CREATE INDEX IF NOT EXISTS ON "App_Staging".orders (id);
+
+UPDATE app.users SET addr.city = 'x.y' WHERE id = 1.5;
+
+INSERT INTO APP.users (id) VALUES (uuid());
+
+SELECT peer FROM system.peers;
+
+ALTER KEYSPACE analytics WITH durable_writes = true;
+
+USE app_staging;
+`
+	mig, err := migration.Parse("V001__users.cql", content)
+	require.NoError(t, err)
+
+	issues := CheckKeyspaces([]*migration.Migration{mig}, []string{"app", "scylla_migrate"})
+	require.Len(t, issues, 4)
+
+	assert.Equal(t, 3, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "statement 2: references keyspace app_staging")
+	assert.Equal(t, "keyspace", issues[0].Rule)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+	assert.Contains(t, issues[1].Message, "keyspace App_Staging")
+	assert.Contains(t, issues[2].Message, "statement 7: references keyspace analytics")
+	assert.Contains(t, issues[3].Message, "statement 8: references keyspace app_staging")
+
+	issues = CheckKeyspaces([]*migration.Migration{mig}, []string{"app", "app_staging", "App_Staging", "analytics"})
+	assert.Empty(t, issues)
+}
@@ -0,0 +1,54 @@
+// Package lint implements offline checks over migration files. Checks never
+// connect to the cluster; they only inspect filenames and parsed statements.
+package lint
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+type Issue struct {
+	File     string
+	Line     int
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+func (i Issue) String() string {
+	loc := i.File
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return fmt.Sprintf("%s: %s [%s] %s", loc, i.Severity, i.Rule, i.Message)
+}
+
+// Sort orders issues by file, then line, then rule.
+func Sort(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Rule < issues[j].Rule
+	})
+}
+
+func CountErrors(issues []Issue) int {
+	n := 0
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// CheckNames validates the description part of every migration filename
+// against the configured naming rules. Descriptions are checked in their
+// humanized form ("create users table"), as shown by status.
+func CheckNames(migrations []*migration.Migration, rules config.NameRules) ([]Issue, error) {
+	var pattern *regexp.Regexp
+	if rules.Pattern != "" {
+		var err error
+		pattern, err = regexp.Compile(rules.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lint.names.pattern: %w", err)
+		}
+	}
+
+	var issues []Issue
+	for _, mig := range migrations {
+		desc := mig.Description
+		report := func(msg string) {
+			issues = append(issues, Issue{
+				File:     mig.Filename,
+				Rule:     "names",
+				Severity: SeverityError,
+				Message:  msg,
+			})
+		}
+
+		if pattern != nil && !pattern.MatchString(desc) {
+			report(fmt.Sprintf("description %q does not match pattern %s", desc, rules.Pattern))
+		}
+
+		if rules.MaxLength > 0 && len(desc) > rules.MaxLength {
+			report(fmt.Sprintf("description is %d characters long (max %d)", len(desc), rules.MaxLength))
+		}
+
+		if len(rules.RequiredPrefixes) > 0 && !hasAnyPrefix(desc, rules.RequiredPrefixes) {
+			report(fmt.Sprintf("description %q must start with one of: %s", desc, strings.Join(rules.RequiredPrefixes, ", ")))
+		}
+	}
+
+	return issues, nil
+}
+
+func hasAnyPrefix(desc string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if desc == p || strings.HasPrefix(desc, p+" ") {
+			return true
+		}
+	}
+	return false
+}
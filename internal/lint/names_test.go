@@ -0,0 +1,49 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+func TestCheckNames_Defaults(t *testing.T) {
+	migrations := []*migration.Migration{
+		{Filename: "V001__create_users.cql", Description: "create users"},
+		{Filename: "V002__AddIndex.cql", Description: "AddIndex"},
+	}
+
+	issues, err := CheckNames(migrations, config.DefaultNameRules())
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "V002__AddIndex.cql", issues[0].File)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+}
+
+func TestCheckNames_MaxLengthAndPrefix(t *testing.T) {
+	migrations := []*migration.Migration{
+		{Filename: "V001__create_users.cql", Description: "create users"},
+		{Filename: "V002__users_index.cql", Description: "users index"},
+		{Filename: "V003__add_a_rather_long_description.cql", Description: "add a rather long description"},
+	}
+	rules := config.NameRules{
+		MaxLength:        20,
+		RequiredPrefixes: []string{"create", "add", "drop"},
+	}
+
+	issues, err := CheckNames(migrations, rules)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "V002__users_index.cql", issues[0].File)
+	assert.Contains(t, issues[0].Message, "must start with")
+	assert.Equal(t, "V003__add_a_rather_long_description.cql", issues[1].File)
+	assert.Contains(t, issues[1].Message, "max 20")
+}
+
+func TestCheckNames_InvalidPattern(t *testing.T) {
+	_, err := CheckNames(nil, config.NameRules{Pattern: "("})
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// ReservedKeywords are the CQL keywords that cannot be used as unquoted
+// identifiers. The list lives in migration so the create scaffolding can
+// reject them too.
+var ReservedKeywords = migration.ReservedKeywords
+
+// CheckReservedKeywords warns about unquoted identifiers that are reserved
+// CQL keywords. It is a heuristic: only names in CREATE TABLE/TYPE/INDEX/
+// KEYSPACE and ALTER TABLE/TYPE statements are inspected, which is where new
+// identifiers are introduced.
+func CheckReservedKeywords(migrations []*migration.Migration) []Issue {
+	var issues []Issue
+	for _, mig := range migrations {
+		lines := statementLines(mig)
+		for i, stmt := range mig.Statements {
+			for _, id := range definedIdentifiers(tokenize(stmt)) {
+				line := 0
+				if lines[i] > 0 {
+					line = lines[i] + id.line
+				}
+				issues = append(issues, Issue{
+					File:     mig.Filename,
+					Line:     line,
+					Rule:     "reserved-keyword",
+					Severity: SeverityWarning,
+					Message: fmt.Sprintf("statement %d: identifier %s is a reserved CQL keyword; quote it as %q",
+						i+1, id.text, strings.ToLower(id.text)),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+type token struct {
+	text   string
+	quoted bool // double-quoted identifier
+	line   int  // 0-based line within the statement
+}
+
+func (t token) is(word string) bool {
+	return !t.quoted && strings.EqualFold(t.text, word)
+}
+
+// tokenize splits a statement into words, double-quoted identifiers and
+// single punctuation characters. String literals and comments are dropped.
+func tokenize(stmt string) []token {
+	var tokens []token
+	line := 0
+	runes := []rune(stmt)
+
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == '\n':
+			line++
+		case ch == ' ' || ch == '\t' || ch == '\r':
+		case ch == '\'':
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\n' {
+					line++
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case ch == '"':
+			start := i + 1
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+			}
+			tokens = append(tokens, token{text: string(runes[start:min(i, len(runes))]), quoted: true, line: line})
+		case isWordRune(ch):
+			start := i
+			for i+1 < len(runes) && isWordRune(runes[i+1]) {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start : i+1]), line: line})
+		default:
+			tokens = append(tokens, token{text: string(ch), line: line})
+		}
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// definedIdentifiers returns the reserved, unquoted identifiers a statement
+// introduces or references by name.
+func definedIdentifiers(tokens []token) []token {
+	if len(tokens) < 2 {
+		return nil
+	}
+
+	var names []token
+	pos := 0
+	next := func() (token, bool) {
+		if pos >= len(tokens) {
+			return token{}, false
+		}
+		pos++
+		return tokens[pos-1], true
+	}
+	skipIfNotExists := func() {
+		if pos+2 < len(tokens) && tokens[pos].is("IF") && tokens[pos+1].is("NOT") && tokens[pos+2].is("EXISTS") {
+			pos += 3
+		} else if pos+1 < len(tokens) && tokens[pos].is("IF") && tokens[pos+1].is("EXISTS") {
+			pos += 2
+		}
+	}
+	qualifiedName := func() {
+		for {
+			t, ok := next()
+			if !ok {
+				return
+			}
+			names = append(names, t)
+			if pos >= len(tokens) || tokens[pos].text != "." {
+				return
+			}
+			pos++
+		}
+	}
+
+	verb, _ := next()
+	object, _ := next()
+	switch {
+	case verb.is("CREATE") && (object.is("TABLE") || object.is("COLUMNFAMILY") || object.is("TYPE")):
+		skipIfNotExists()
+		qualifiedName()
+		names = append(names, columnDefinitions(tokens[pos:])...)
+	case verb.is("CREATE") && object.is("KEYSPACE"):
+		skipIfNotExists()
+		qualifiedName()
+	case verb.is("CREATE") && object.is("INDEX"):
+		skipIfNotExists()
+		if pos < len(tokens) && !tokens[pos].is("ON") {
+			qualifiedName()
+		}
+	case verb.is("ALTER") && (object.is("TABLE") || object.is("TYPE")):
+		qualifiedName()
+		if t, ok := next(); ok && (t.is("ADD") || t.is("RENAME")) {
+			skipIfNotExists()
+			if name, ok := next(); ok && name.text != "(" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var reserved []token
+	for _, n := range names {
+		if !n.quoted && migration.IsReservedKeyword(n.text) {
+			reserved = append(reserved, n)
+		}
+	}
+	return reserved
+}
+
+// columnDefinitions returns the first token of every top-level element of the
+// parenthesized definition list, skipping PRIMARY KEY clauses.
+func columnDefinitions(tokens []token) []token {
+	var names []token
+	depth := 0
+	expectName := false
+	for _, t := range tokens {
+		switch {
+		case t.text == "(" && !t.quoted:
+			depth++
+			if depth == 1 {
+				expectName = true
+				continue
+			}
+		case t.text == ")" && !t.quoted:
+			depth--
+			if depth == 0 {
+				return names
+			}
+		case t.text == "," && !t.quoted && depth == 1:
+			expectName = true
+			continue
+		}
+		if expectName && depth == 1 {
+			if !t.is("PRIMARY") {
+				names = append(names, t)
+			}
+			expectName = false
+		}
+	}
+	return names
+}
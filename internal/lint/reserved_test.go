@@ -0,0 +1,50 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+func TestCheckReservedKeywords(t *testing.T) {
+	content := `-- Orders table
+CREATE TABLE IF NOT EXISTS shop.orders (
+    id UUID,
+    order INT,
+    "from" TEXT,
+    key TEXT,
+    created_at map<text, frozen<list<int>>>,
+    PRIMARY KEY (id, order)
+);
+
+ALTER TABLE shop.orders ADD token TEXT;
+
+INSERT INTO shop.orders (id, note) VALUES (uuid(), 'select from table');
+`
+	mig, err := migration.Parse("V001__orders.cql", content)
+	require.NoError(t, err)
+
+	issues := CheckReservedKeywords([]*migration.Migration{mig})
+	require.Len(t, issues, 2)
+
+	assert.Equal(t, 4, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "order")
+	assert.Contains(t, issues[0].Message, `"order"`)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+
+	assert.Equal(t, 11, issues[1].Line)
+	assert.Contains(t, issues[1].Message, "token")
+}
+
+func TestCheckReservedKeywords_TableName(t *testing.T) {
+	mig, err := migration.Parse("V001__t.cql", "CREATE TABLE ks.table (id int PRIMARY KEY);")
+	require.NoError(t, err)
+
+	issues := CheckReservedKeywords([]*migration.Migration{mig})
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, "table")
+	assert.Equal(t, 1, issues[0].Line)
+}
@@ -0,0 +1,30 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/migration"
+)
+
+// statementLines returns the 1-based line in the migration file where each
+// parsed statement starts. Statements are stored with comments stripped, so
+// the position is found by searching for the statement's first line; it is
+// 0 when the statement can't be located.
+func statementLines(mig *migration.Migration) []int {
+	content := strings.ReplaceAll(mig.RawContent, "\r\n", "\n")
+	lines := make([]int, len(mig.Statements))
+
+	cursor := 0
+	for i, stmt := range mig.Statements {
+		first, _, _ := strings.Cut(stmt, "\n")
+		idx := strings.Index(content[cursor:], first)
+		if idx < 0 {
+			continue
+		}
+		pos := cursor + idx
+		lines[i] = strings.Count(content[:pos], "\n") + 1
+		cursor = pos + len(first)
+	}
+
+	return lines
+}
@@ -15,6 +15,25 @@ import (
 
 const MigrationLockID = "migration_lock"
 
+// Strategy selects how the migration lock is taken (lock_strategy).
+type Strategy string
+
+const (
+	// StrategyLWT uses lightweight transactions: mutual exclusion is
+	// guaranteed by Paxos.
+	StrategyLWT Strategy = "lwt"
+	// StrategyAdvisory writes a TTL'd row without LWT and confirms ownership
+	// by reading it back. Two runners starting within the confirmation window
+	// can still both proceed.
+	StrategyAdvisory Strategy = "advisory"
+	// StrategyNone takes no lock at all.
+	StrategyNone Strategy = "none"
+)
+
+// advisoryConfirmDelay is how long an advisory writer waits before reading
+// the lock back, giving a concurrent writer's row time to win.
+const advisoryConfirmDelay = 500 * time.Millisecond
+
 type Lock struct {
 	ID        string
 	LockedBy  string
@@ -23,35 +42,68 @@ type Lock struct {
 }
 
 type LockManager struct {
-	session  *driver.Session
-	keyspace string
-	lockID   string
-	owner    string
-	Logger   zerolog.Logger
+	session     *driver.Session
+	keyspace    string
+	lockID      string
+	owner       string
+	stableOwner bool
+	strategy    Strategy
+	Logger      zerolog.Logger
 }
 
-func NewLockManager(session *driver.Session, keyspace string, logger zerolog.Logger) *LockManager {
-	hostname, err := os.Hostname()
-	if err != nil {
-		hostname = "unknown"
+// NewLockManager creates a lock manager. With an empty ownerID the owner is
+// the hostname plus a random suffix, unique to this process. A non-empty
+// ownerID is used as-is, so a restarted process configured with the same id
+// can reclaim a lock its predecessor still holds. The id must then be unique
+// per migration runner (e.g. a StatefulSet pod name). An empty strategy
+// means StrategyLWT.
+func NewLockManager(session *driver.Session, keyspace, ownerID string, strategy Strategy, logger zerolog.Logger) *LockManager {
+	owner := ownerID
+	if owner == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "unknown"
+		}
+		owner = fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
 	}
-	owner := fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
 
 	return &LockManager{
-		session:  session,
-		keyspace: keyspace,
-		lockID:   MigrationLockID,
-		owner:    owner,
-		Logger:   logger,
+		session:     session,
+		keyspace:    keyspace,
+		lockID:      MigrationLockID,
+		owner:       owner,
+		stableOwner: ownerID != "",
+		strategy:    strategy,
+		Logger:      logger,
 	}
 }
 
+// SetLockID changes the lock row this manager contends for, e.g. to test
+// locking without blocking real migrations.
+func (lm *LockManager) SetLockID(id string) {
+	lm.lockID = id
+}
+
+// Owner returns the identity written to locked_by.
+func (lm *LockManager) Owner() string {
+	return lm.owner
+}
+
 func (lm *LockManager) Acquire(timeout time.Duration) error {
 	lm.Logger.Debug().
 		Str("owner", lm.owner).
+		Str("strategy", string(lm.strategy)).
 		Dur("timeout", timeout).
 		Msg("Attempting to acquire migration lock")
 
+	switch lm.strategy {
+	case StrategyNone:
+		lm.Logger.Warn().Msg("Migration locking is disabled (lock_strategy: none)")
+		return nil
+	case StrategyAdvisory:
+		return lm.acquireAdvisory(timeout)
+	}
+
 	deadline := time.Now().Add(timeout)
 	ttl := int(timeout.Seconds()) + 60 // extra buffer for TTL
 	backoff := 1 * time.Second
@@ -82,6 +134,19 @@ func (lm *LockManager) Acquire(timeout time.Duration) error {
 				lm.Logger.Warn().Err(err).Msg("Failed to check current lock, retrying")
 			}
 			// Lock row doesn't exist or error — retry acquire
+		} else if lm.stableOwner && lock.LockedBy == lm.owner {
+			reclaimed, err := lm.reclaim(timeout, ttl)
+			if err != nil {
+				return fmt.Errorf("failed to reclaim lock: %w", err)
+			}
+			if reclaimed {
+				lm.Logger.Warn().
+					Str("owner", lm.owner).
+					Time("locked_at", lock.LockedAt).
+					Msg("Reclaimed migration lock left by a previous process with the same owner id")
+				return nil
+			}
+			continue
 		} else if time.Now().After(lock.ExpiresAt) {
 			lm.Logger.Warn().
 				Str("held_by", lock.LockedBy).
@@ -111,6 +176,13 @@ func (lm *LockManager) Acquire(timeout time.Duration) error {
 func (lm *LockManager) Release() error {
 	lm.Logger.Debug().Str("owner", lm.owner).Msg("Releasing migration lock")
 
+	switch lm.strategy {
+	case StrategyNone:
+		return nil
+	case StrategyAdvisory:
+		return lm.releaseAdvisory()
+	}
+
 	query := fmt.Sprintf(
 		`DELETE FROM %s.schema_lock WHERE lock_id = ? IF locked_by = ?`,
 		lm.keyspace,
@@ -136,6 +208,91 @@ func (lm *LockManager) Release() error {
 	return nil
 }
 
+// acquireAdvisory takes the lock without LWT: if the row is free, expired or
+// already ours, write it, wait, and read it back. Whoever's write survives
+// (last write wins) owns the lock; everyone else keeps waiting.
+func (lm *LockManager) acquireAdvisory(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	ttl := int(timeout.Seconds()) + 60
+	backoff := 1 * time.Second
+
+	for time.Now().Before(deadline) {
+		lock, err := lm.GetCurrentLock()
+		free := errors.Is(err, gocql.ErrNotFound)
+		if err != nil && !free {
+			lm.Logger.Warn().Err(err).Msg("Failed to check current lock, retrying")
+		}
+
+		if free || (err == nil && (lock.LockedBy == lm.owner || time.Now().After(lock.ExpiresAt))) {
+			query := fmt.Sprintf(
+				`INSERT INTO %s.schema_lock (lock_id, locked_by, locked_at, expires_at)
+				 VALUES (?, ?, ?, ?)
+				 USING TTL %d`,
+				lm.keyspace, ttl,
+			)
+			if err := lm.session.Execute(query, lm.lockID, lm.owner, time.Now(), time.Now().Add(timeout)); err != nil {
+				return fmt.Errorf("failed to execute lock query: %w", err)
+			}
+
+			time.Sleep(advisoryConfirmDelay)
+			lock, err = lm.GetCurrentLock()
+			if err == nil && lock.LockedBy == lm.owner {
+				lm.Logger.Info().Str("owner", lm.owner).Msg("Advisory migration lock acquired")
+				return nil
+			}
+		} else if err == nil {
+			lm.Logger.Debug().
+				Str("held_by", lock.LockedBy).
+				Time("expires_at", lock.ExpiresAt).
+				Msg("Lock held by another process, waiting")
+		}
+
+		time.Sleep(backoff)
+		if backoff < 10*time.Second {
+			backoff = backoff * 2
+		}
+	}
+
+	return fmt.Errorf("failed to acquire migration lock within %s — another migration may be in progress", timeout)
+}
+
+// releaseAdvisory deletes the lock row if it is still ours. The check and
+// the delete are not atomic; at worst a lock taken in between is dropped.
+func (lm *LockManager) releaseAdvisory() error {
+	lock, err := lm.GetCurrentLock()
+	if errors.Is(err, gocql.ErrNotFound) {
+		lm.Logger.Warn().Msg("Lock was not released — it had already expired")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read lock before release: %w", err)
+	}
+	if lock.LockedBy != lm.owner {
+		lm.Logger.Warn().Str("held_by", lock.LockedBy).Msg("Lock was not released — it is held by another process")
+		return nil
+	}
+
+	if err := lm.forceRelease(); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	lm.Logger.Info().Msg("Migration lock released")
+	return nil
+}
+
+// reclaim refreshes a lock row already owned by lm.owner. The condition
+// guards against the lock having been released or stolen in the meantime.
+func (lm *LockManager) reclaim(timeout time.Duration, ttl int) (bool, error) {
+	query := fmt.Sprintf(
+		`UPDATE %s.schema_lock USING TTL %d
+		 SET locked_by = ?, locked_at = ?, expires_at = ?
+		 WHERE lock_id = ?
+		 IF locked_by = ?`,
+		lm.keyspace, ttl,
+	)
+	// locked_by is rewritten too so every cell gets the new TTL
+	return lm.executeLWT(query, lm.owner, time.Now(), time.Now().Add(timeout), lm.lockID, lm.owner)
+}
+
 func (lm *LockManager) GetCurrentLock() (*Lock, error) {
 	query := fmt.Sprintf(
 		`SELECT lock_id, locked_by, locked_at, expires_at FROM %s.schema_lock WHERE lock_id = ?`,
@@ -0,0 +1,130 @@
+// Package metrics writes the Prometheus metrics of 'migrate --metrics-file'
+// in the text exposition format read by node-exporter's textfile collector.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Run is the state after a migrate run.
+type Run struct {
+	Keyspace string
+	Success  bool
+	Duration time.Duration
+	// Applied is the number of migrations this run applied
+	Applied int
+	// Pending is the number of migrations still pending, or -1 if unknown
+	Pending int
+	// Records are the schema_migrations records, in version order
+	Records []Record
+}
+
+// Record is one schema_migrations record.
+type Record struct {
+	Version   string
+	Type      string
+	Success   bool
+	AppliedAt time.Time
+}
+
+type metric struct {
+	name, help string
+	samples    []sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// WriteTextfile writes the metrics of run to w.
+func WriteTextfile(w io.Writer, run Run) error {
+	ks := [2]string{"keyspace", run.Keyspace}
+
+	applied := 0
+	var lastSuccess time.Time
+	var versions []sample
+	for _, r := range run.Records {
+		value := 0.0
+		if r.Success {
+			value = 1
+			applied++
+			if r.AppliedAt.After(lastSuccess) {
+				lastSuccess = r.AppliedAt
+			}
+		}
+		versions = append(versions, sample{
+			labels: [][2]string{ks, {"version", r.Version}, {"type", r.Type}},
+			value:  value,
+		})
+	}
+
+	metrics := []metric{
+		{"scylla_migrate_applied_total", "Migrations recorded as successfully applied.",
+			[]sample{{[][2]string{ks}, float64(applied)}}},
+	}
+	if run.Pending >= 0 {
+		metrics = append(metrics, metric{"scylla_migrate_pending", "Migrations not applied yet.",
+			[]sample{{[][2]string{ks}, float64(run.Pending)}}})
+	}
+	if !lastSuccess.IsZero() {
+		metrics = append(metrics, metric{"scylla_migrate_last_success_timestamp",
+			"Unix time the most recent successfully applied migration was recorded.",
+			[]sample{{[][2]string{ks}, float64(lastSuccess.Unix())}}})
+	}
+	success := 0.0
+	if run.Success {
+		success = 1
+	}
+	metrics = append(metrics,
+		metric{"scylla_migrate_duration_seconds", "Duration of the last migrate run.",
+			[]sample{{[][2]string{ks}, run.Duration.Seconds()}}},
+		metric{"scylla_migrate_last_run_success", "Whether the last migrate run succeeded (1) or failed (0).",
+			[]sample{{[][2]string{ks}, success}}},
+		metric{"scylla_migrate_last_run_applied", "Migrations applied by the last migrate run.",
+			[]sample{{[][2]string{ks}, float64(run.Applied)}}},
+	)
+	if len(versions) > 0 {
+		metrics = append(metrics, metric{"scylla_migrate_migration_applied",
+			"Per recorded migration: 1 if applied successfully, 0 if its last attempt failed.", versions})
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind())
+		for _, s := range m.samples {
+			b.WriteString(m.name)
+			b.WriteByte('{')
+			for i, l := range s.labels {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, "%s=\"%s\"", l[0], escapeLabel(l[1]))
+			}
+			b.WriteString("} ")
+			b.WriteString(strconv.FormatFloat(s.value, 'f', -1, 64))
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// kind is the metric type: counters carry the _total suffix, the rest are
+// gauges.
+func (m metric) kind() string {
+	if strings.HasSuffix(m.name, "_total") {
+		return "counter"
+	}
+	return "gauge"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
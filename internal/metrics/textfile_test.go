@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTextfile(t *testing.T) {
+	at := time.Unix(1767225600, 0)
+	run := Run{
+		Keyspace: "app",
+		Success:  true,
+		Duration: 1500 * time.Millisecond,
+		Applied:  1,
+		Pending:  2,
+		Records: []Record{
+			{Version: "001", Type: "versioned", Success: true, AppliedAt: at},
+			{Version: "002", Type: "versioned", Success: false, AppliedAt: at.Add(time.Hour)},
+			{Version: "R_views", Type: "repeatable", Success: true, AppliedAt: at.Add(time.Minute)},
+		},
+	}
+
+	var b strings.Builder
+	require.NoError(t, WriteTextfile(&b, run))
+	out := b.String()
+
+	assert.Contains(t, out, "# TYPE scylla_migrate_applied_total counter\nscylla_migrate_applied_total{keyspace=\"app\"} 2\n")
+	assert.Contains(t, out, "# TYPE scylla_migrate_pending gauge\n")
+	assert.Contains(t, out, "scylla_migrate_pending{keyspace=\"app\"} 2\n")
+	assert.Contains(t, out, "scylla_migrate_last_success_timestamp{keyspace=\"app\"} 1767225660\n")
+	assert.Contains(t, out, "scylla_migrate_duration_seconds{keyspace=\"app\"} 1.5\n")
+	assert.Contains(t, out, "scylla_migrate_last_run_success{keyspace=\"app\"} 1\n")
+	assert.Contains(t, out, "scylla_migrate_last_run_applied{keyspace=\"app\"} 1\n")
+	assert.Contains(t, out, "scylla_migrate_migration_applied{keyspace=\"app\",version=\"001\",type=\"versioned\"} 1\n")
+	assert.Contains(t, out, "scylla_migrate_migration_applied{keyspace=\"app\",version=\"002\",type=\"versioned\"} 0\n")
+}
+
+func TestWriteTextfile_Unknowns(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, WriteTextfile(&b, Run{Keyspace: `a"b`, Pending: -1}))
+	out := b.String()
+
+	assert.NotContains(t, out, "scylla_migrate_pending")
+	assert.NotContains(t, out, "scylla_migrate_last_success_timestamp")
+	assert.NotContains(t, out, "scylla_migrate_migration_applied")
+	assert.Contains(t, out, `scylla_migrate_last_run_success{keyspace="a\"b"} 0`)
+}
@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+)
+
+// DeferAgreementDirective lets a migration's DDL skip the schema agreement
+// wait after each statement: "-- scylla-migrate:defer-agreement". Only use
+// it for DDL that does not depend on the statements before it.
+const DeferAgreementDirective = "defer-agreement"
+
+// DefersAgreement reports whether the migration has the defer-agreement
+// directive.
+func (m *Migration) DefersAgreement() bool {
+	_, ok := m.Directives[DeferAgreementDirective]
+	return ok
+}
+
+// CheckSchemaAgreement refuses to start a run on a cluster whose nodes
+// already disagree on the schema, since more DDL only makes it worse. With
+// force the disagreement is logged and the run goes ahead. Failing to read
+// the versions is not fatal; the agreement wait after each DDL still applies.
+func CheckSchemaAgreement(ctx *ExecutionContext, force bool) error {
+	err := ctx.Session.CheckSchemaAgreement()
+	if err == nil {
+		return nil
+	}
+
+	var disagreement *driver.SchemaDisagreementError
+	if !errors.As(err, &disagreement) {
+		ctx.Logger.Warn().Err(err).Msg("Failed to check schema agreement before starting")
+		return nil
+	}
+
+	for _, n := range disagreement.Divergent {
+		ctx.Logger.Warn().
+			Str("host", n.Host).
+			Str("schema_version", n.Version).
+			Str("expected", disagreement.Majority).
+			Msg("Node disagrees on schema version")
+	}
+	if force {
+		ctx.Logger.Warn().Msg("Cluster is in schema disagreement, starting anyway (--force)")
+		return nil
+	}
+	return fmt.Errorf("%w — resolve it before migrating (see nodetool describecluster) or re-run with --force", err)
+}
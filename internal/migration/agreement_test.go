@@ -0,0 +1,18 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigration_DefersAgreement(t *testing.T) {
+	mig, err := Parse("V001__bootstrap.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE a (id int PRIMARY KEY);\nCREATE TABLE b (id int PRIMARY KEY);")
+	require.NoError(t, err)
+	assert.True(t, mig.DefersAgreement())
+
+	mig, err = Parse("V002__plain.cql", "CREATE TABLE c (id int PRIMARY KEY);")
+	require.NoError(t, err)
+	assert.False(t, mig.DefersAgreement())
+}
@@ -0,0 +1,106 @@
+package migration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArgsFileSuffix names the companion file holding bind values for a
+// migration: V003__seed.cql -> V003__seed.args.json.
+const ArgsFileSuffix = ".args.json"
+
+// ArgsFilePath returns the companion args file path for a migration file.
+func ArgsFilePath(migrationPath string) string {
+	return strings.TrimSuffix(migrationPath, filepath.Ext(migrationPath)) + ArgsFileSuffix
+}
+
+// BindArgs returns the positional bind values for statement i, or nil.
+func (m *Migration) BindArgs(i int) []interface{} {
+	if i < 0 || i >= len(m.Args) {
+		return nil
+	}
+	return m.Args[i]
+}
+
+// loadBindArgs reads the companion args file, if any. The file is a JSON
+// array with one entry per statement: an array of values for its "?"
+// placeholders, or null for statements without placeholders. The checksum
+// is extended to cover the args so changing a value is detected like
+// changing the CQL.
+func loadBindArgs(mig *Migration) error {
+	path := ArgsFilePath(mig.FilePath)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			mig.Args = nil
+			return nil
+		}
+		return fmt.Errorf("failed to read args file %s: %w", path, err)
+	}
+
+	args, err := parseBindArgs(content)
+	if err != nil {
+		return fmt.Errorf("invalid args file %s: %w", path, err)
+	}
+
+	if !mig.Streamed && len(args) != len(mig.Statements) {
+		return fmt.Errorf("args file %s has %d entries but %s has %d statements",
+			path, len(args), mig.Filename, len(mig.Statements))
+	}
+
+	checksum, err := checksumWith(strings.NewReader(mig.Checksum+"\n"+string(content)), mig.parseOptions().ChecksumNormalization)
+	if err != nil {
+		return fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+
+	mig.Args = args
+	mig.Checksum = checksum
+	return nil
+}
+
+func parseBindArgs(content []byte) ([][]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+
+	var raw [][]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	for _, values := range raw {
+		for j, v := range values {
+			values[j] = bindValue(v)
+		}
+	}
+	return raw, nil
+}
+
+// bindValue converts JSON numbers to Go types gocql can marshal: integers
+// to int64, anything with a fraction or exponent to float64.
+func bindValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case []interface{}:
+		for i := range val {
+			val[i] = bindValue(val[i])
+		}
+		return val
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = bindValue(val[k])
+		}
+		return val
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMigrationFile_BindArgs(t *testing.T) {
+	dir := t.TempDir()
+	cql := "INSERT INTO plans (id, name, price) VALUES (?, ?, ?);\nUPDATE plans SET active = true WHERE id = 1;"
+	createTestMigration(t, dir, "V003__seed_plans.cql", cql)
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	require.Len(t, scanned, 1)
+	mig := scanned[0]
+
+	require.NoError(t, ParseMigrationFile(mig))
+	plainChecksum := mig.Checksum
+	assert.Nil(t, mig.BindArgs(0))
+
+	argsPath := filepath.Join(dir, "V003__seed_plans.args.json")
+	require.NoError(t, os.WriteFile(argsPath, []byte(`[[1, "it's basic", 9.5], null]`), 0644))
+
+	require.NoError(t, ParseMigrationFile(mig))
+	assert.Equal(t, []interface{}{int64(1), "it's basic", 9.5}, mig.BindArgs(0))
+	assert.Nil(t, mig.BindArgs(1))
+	assert.NotEqual(t, plainChecksum, mig.Checksum)
+	argsChecksum := mig.Checksum
+
+	// Changing a value changes the checksum
+	require.NoError(t, os.WriteFile(argsPath, []byte(`[[1, "it's basic", 10.5], null]`), 0644))
+	require.NoError(t, ParseMigrationFile(mig))
+	assert.NotEqual(t, argsChecksum, mig.Checksum)
+
+	// The args file must have one entry per statement
+	require.NoError(t, os.WriteFile(argsPath, []byte(`[[1, "basic", 9.5]]`), 0644))
+	err = ParseMigrationFile(mig)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 entries")
+}
+
+func TestArgsFilePath(t *testing.T) {
+	assert.Equal(t, "m/V001__a.args.json", ArgsFilePath("m/V001__a.cql"))
+	assert.Equal(t, "m/R__b.args.json", ArgsFilePath("m/R__b.sql"))
+}
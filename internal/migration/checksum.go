@@ -1,10 +1,15 @@
 package migration
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
 )
 
 func CalculateChecksum(filePath string) (string, error) {
@@ -16,10 +21,111 @@ func CalculateChecksum(filePath string) (string, error) {
 	return CalculateChecksumFromContent(content)
 }
 
+// CalculateChecksumFromContent hashes content under the default
+// checksum_normalization.
 func CalculateChecksumFromContent(content []byte) (string, error) {
-	// Normalize CRLF to LF for consistent checksums across platforms
-	normalized := strings.ReplaceAll(string(content), "\r\n", "\n")
+	return checksumWith(bytes.NewReader(content), config.DefaultChecksumNormalization())
+}
+
+func checksumWith(r io.Reader, policy config.ChecksumNormalization) (string, error) {
+	h := sha256.New()
+	if err := writeNormalized(h, r, policy); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// writeNormalized copies r to w line by line, applying policy. Only the
+// current line is held in memory, so it also serves streamed migrations.
+func writeNormalized(w io.Writer, r io.Reader, policy config.ChecksumNormalization) error {
+	br := bufio.NewReader(r)
+	prevBlank := false
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			body, hasNewline := strings.CutSuffix(line, "\n")
+			if policy.NormalizeLineEndings && hasNewline {
+				body = strings.TrimSuffix(body, "\r")
+			}
+			if policy.TrimTrailingWhitespace {
+				body = strings.TrimRight(body, " \t")
+			}
+
+			blank := strings.TrimSpace(body) == ""
+			if !(policy.CollapseBlankLines && blank && prevBlank) {
+				if hasNewline {
+					body += "\n"
+				}
+				if _, werr := io.WriteString(w, body); werr != nil {
+					return werr
+				}
+			}
+			prevBlank = blank
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ChecksumNormalizations returns every combination of the normalization
+// options, to recognize checksums recorded under an earlier policy.
+func ChecksumNormalizations() []config.ChecksumNormalization {
+	var all []config.ChecksumNormalization
+	for i := 0; i < 8; i++ {
+		all = append(all, config.ChecksumNormalization{
+			NormalizeLineEndings:   i&1 != 0,
+			TrimTrailingWhitespace: i&2 != 0,
+			CollapseBlankLines:     i&4 != 0,
+		})
+	}
+	return all
+}
 
-	hash := sha256.Sum256([]byte(normalized))
-	return fmt.Sprintf("%x", hash), nil
+// ChecksumWith returns the checksum ParseMigrationFile would compute for mig
+// under policy, including its args file.
+func ChecksumWith(mig *Migration, policy config.ChecksumNormalization) (string, error) {
+	f, err := os.Open(mig.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read migration file %s: %w", mig.FilePath, err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if bom, _ := br.Peek(3); string(bom) == "\xef\xbb\xbf" {
+		_, _ = br.Discard(3)
+	}
+	sum, err := checksumWith(br, policy)
+	if err != nil {
+		return "", fmt.Errorf("failed to read migration file %s: %w", mig.FilePath, err)
+	}
+
+	args, err := os.ReadFile(ArgsFilePath(mig.FilePath))
+	if os.IsNotExist(err) {
+		return sum, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return checksumWith(strings.NewReader(sum+"\n"+string(args)), policy)
+}
+
+// MatchesAnyNormalization reports whether checksum is mig's checksum under
+// some normalization policy, i.e. it was recorded from the same file under a
+// different checksum_normalization.
+func MatchesAnyNormalization(mig *Migration, checksum string) (bool, error) {
+	for _, policy := range ChecksumNormalizations() {
+		sum, err := ChecksumWith(mig, policy)
+		if err != nil {
+			return false, err
+		}
+		if sum == checksum {
+			return true, nil
+		}
+	}
+	return false, nil
 }
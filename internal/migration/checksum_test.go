@@ -3,10 +3,13 @@ package migration
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
 )
 
 func TestCalculateChecksum(t *testing.T) {
@@ -57,3 +60,43 @@ func TestCalculateChecksum_Deterministic(t *testing.T) {
 
 	assert.Equal(t, c1, c2)
 }
+
+func TestChecksumWith_Normalization(t *testing.T) {
+	messy := "CREATE TABLE foo (id int PRIMARY KEY);  \r\n\r\n\r\n\r\nCREATE TABLE bar (id int PRIMARY KEY);\r\n"
+	clean := "CREATE TABLE foo (id int PRIMARY KEY);\n\nCREATE TABLE bar (id int PRIMARY KEY);\n"
+
+	sum := func(content string, policy config.ChecksumNormalization) string {
+		s, err := checksumWith(strings.NewReader(content), policy)
+		require.NoError(t, err)
+		return s
+	}
+
+	// The default only normalizes line endings, as checksums always have
+	def := config.DefaultChecksumNormalization()
+	assert.Equal(t, sum(strings.ReplaceAll(messy, "\r\n", "\n"), config.ChecksumNormalization{}), sum(messy, def))
+	assert.NotEqual(t, sum(clean, def), sum(messy, def))
+
+	all := config.ChecksumNormalization{NormalizeLineEndings: true, TrimTrailingWhitespace: true, CollapseBlankLines: true}
+	assert.Equal(t, sum(clean, all), sum(messy, all))
+
+	assert.NotEqual(t, sum(messy, config.ChecksumNormalization{}), sum(messy, def))
+	assert.Len(t, ChecksumNormalizations(), 8)
+}
+
+func TestChecksumWith_MatchesParse(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "V001__seed.cql")
+	require.NoError(t, os.WriteFile(path, []byte("\xef\xbb\xbfINSERT INTO t (id) VALUES (?);  \r\n"), 0644))
+	require.NoError(t, os.WriteFile(ArgsFilePath(path), []byte(`[[1]]`), 0644))
+
+	mig := &Migration{Version: "001", Filename: "V001__seed.cql", FilePath: path, Type: TypeVersioned}
+	require.NoError(t, ParseMigrationFile(mig))
+
+	sum, err := ChecksumWith(mig, config.DefaultChecksumNormalization())
+	require.NoError(t, err)
+	assert.Equal(t, mig.Checksum, sum)
+
+	trimmed, err := ChecksumWith(mig, config.ChecksumNormalization{NormalizeLineEndings: true, TrimTrailingWhitespace: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, mig.Checksum, trimmed)
+}
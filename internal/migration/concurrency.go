@@ -0,0 +1,140 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConcurrencyDirective runs the non-DDL statements of a migration through a
+// bounded worker pool, e.g. "-- scylla-migrate:concurrency 8". DDL still runs
+// alone, once every statement before it has finished, and waits for schema
+// agreement as usual. Only use it when the statements between DDL do not
+// depend on each other's order.
+const ConcurrencyDirective = "concurrency"
+
+// Concurrency returns how many non-DDL statements of the migration may run
+// at once: the concurrency directive if present, otherwise 1.
+func (m *Migration) Concurrency() (int, error) {
+	value, ok := m.Directives[ConcurrencyDirective]
+	if !ok {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s directive %q in %s: must be a positive integer", ConcurrencyDirective, value, m.Filename)
+	}
+	return n, nil
+}
+
+// statementPool runs functions with a bounded number in flight and keeps the
+// first error. Once a function has failed no further ones are started, and
+// the context of those already running is cancelled.
+type statementPool struct {
+	slots  chan struct{}
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	err      error
+	panicked interface{}
+}
+
+// newStatementPool returns a pool whose functions run with a context derived
+// from parent. Call Close once done with it.
+func newStatementPool(parent context.Context, workers int) *statementPool {
+	ctx, cancel := context.WithCancel(parent)
+	return &statementPool{slots: make(chan struct{}, workers), ctx: ctx, cancel: cancel}
+}
+
+// Go starts fn once a worker is free. It returns the first error of an
+// earlier function instead of starting fn.
+func (p *statementPool) Go(fn func(ctx context.Context) error) error {
+	p.slots <- struct{}{}
+	if err := p.failed(); err != nil {
+		<-p.slots
+		return err
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.fail(fmt.Errorf("statement worker panicked: %v", r), r)
+			}
+			<-p.slots
+			p.wg.Done()
+		}()
+		if err := fn(p.ctx); err != nil {
+			p.fail(err, nil)
+		}
+	}()
+	return nil
+}
+
+func (p *statementPool) fail(err error, panicValue interface{}) {
+	p.mu.Lock()
+	if p.err == nil {
+		p.err = err
+	}
+	if panicValue != nil && p.panicked == nil {
+		p.panicked = panicValue
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// Wait blocks until every started function has returned and reports the
+// first error. A panic in a function is raised again here, on the caller's
+// goroutine, so the caller's recovery sees it.
+func (p *statementPool) Wait() error {
+	p.wg.Wait()
+	p.mu.Lock()
+	panicked := p.panicked
+	p.mu.Unlock()
+	if panicked != nil {
+		panic(panicked)
+	}
+	return p.failed()
+}
+
+// Close releases the pool's context.
+func (p *statementPool) Close() {
+	p.cancel()
+}
+
+func (p *statementPool) failed() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// executeConcurrently is the statement loop of a migration with the
+// concurrency directive.
+func (e *Executor) executeConcurrently(mig *Migration, workers int, timeout time.Duration) error {
+	pool := newStatementPool(context.Background(), workers)
+	defer pool.Close()
+	err := mig.EachStatement(func(i int, stmt string) error {
+		if IsDDL(stmt) {
+			if err := pool.Wait(); err != nil {
+				return err
+			}
+			return e.executeStatement(pool.ctx, mig, i, stmt, timeout)
+		}
+		// Settle a deferred agreement wait here, while no worker is running,
+		// so the workers never touch it
+		if err := e.flushAgreement(); err != nil {
+			return err
+		}
+		return pool.Go(func(ctx context.Context) error {
+			return e.executeStatement(ctx, mig, i, stmt, timeout)
+		})
+	})
+	if waitErr := pool.Wait(); err == nil {
+		err = waitErr
+	}
+	return err
+}
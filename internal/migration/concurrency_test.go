@@ -0,0 +1,95 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigration_Concurrency(t *testing.T) {
+	mig := &Migration{Filename: "V001__x.cql"}
+	n, err := mig.Concurrency()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	mig.Directives = map[string]string{ConcurrencyDirective: "8"}
+	n, err = mig.Concurrency()
+	require.NoError(t, err)
+	assert.Equal(t, 8, n)
+
+	for _, bad := range []string{"", "0", "-2", "many"} {
+		mig.Directives = map[string]string{ConcurrencyDirective: bad}
+		_, err = mig.Concurrency()
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestStatementPool_Bounded(t *testing.T) {
+	pool := newStatementPool(context.Background(), 3)
+	var active, maxActive, ran atomic.Int32
+	for i := 0; i < 20; i++ {
+		require.NoError(t, pool.Go(func(context.Context) error {
+			now := active.Add(1)
+			for {
+				prev := maxActive.Load()
+				if now <= prev || maxActive.CompareAndSwap(prev, now) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+			ran.Add(1)
+			return nil
+		}))
+	}
+	require.NoError(t, pool.Wait())
+	assert.Equal(t, int32(20), ran.Load())
+	assert.LessOrEqual(t, maxActive.Load(), int32(3))
+}
+
+func TestStatementPool_StopsAfterFailure(t *testing.T) {
+	pool := newStatementPool(context.Background(), 1)
+	boom := errors.New("boom")
+	require.NoError(t, pool.Go(func(context.Context) error { return boom }))
+
+	started := false
+	// the single slot is only freed once the failing function has returned
+	err := pool.Go(func(context.Context) error { started = true; return nil })
+	assert.ErrorIs(t, err, boom)
+	assert.ErrorIs(t, pool.Wait(), boom)
+	assert.False(t, started)
+}
+
+func TestStatementPool_CancelsRunningAfterFailure(t *testing.T) {
+	pool := newStatementPool(context.Background(), 2)
+	defer pool.Close()
+	boom := errors.New("boom")
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+	require.NoError(t, pool.Go(func(ctx context.Context) error {
+		close(started)
+		select {
+		case <-ctx.Done():
+			cancelled.Store(true)
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	}))
+	<-started
+	require.NoError(t, pool.Go(func(context.Context) error { return boom }))
+	assert.ErrorIs(t, pool.Wait(), boom)
+	assert.True(t, cancelled.Load())
+}
+
+func TestStatementPool_RaisesWorkerPanicFromWait(t *testing.T) {
+	pool := newStatementPool(context.Background(), 2)
+	defer pool.Close()
+	require.NoError(t, pool.Go(func(context.Context) error { panic("worker") }))
+	assert.PanicsWithValue(t, "worker", func() { _ = pool.Wait() })
+}
@@ -0,0 +1,37 @@
+package migration
+
+import "strings"
+
+// EnvironmentsDirective limits a migration to the listed environments, e.g.
+// "-- scylla-migrate:environments dev,staging". Migrations without it run
+// everywhere.
+const EnvironmentsDirective = "environments"
+
+// Environments returns the lowercased environments named by the directive,
+// or nil when the migration runs in every environment.
+func (m *Migration) Environments() []string {
+	value, ok := m.Directives[EnvironmentsDirective]
+	if !ok {
+		return nil
+	}
+	var envs []string
+	for _, env := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		envs = append(envs, strings.ToLower(env))
+	}
+	return envs
+}
+
+// RunsIn reports whether the migration applies to env. A migration with the
+// directive never runs when no environment is configured.
+func (m *Migration) RunsIn(env string) bool {
+	if _, ok := m.Directives[EnvironmentsDirective]; !ok {
+		return true
+	}
+	env = strings.ToLower(env)
+	for _, allowed := range m.Environments() {
+		if allowed == env && env != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+func TestMigration_RunsIn(t *testing.T) {
+	mig, err := Parse("V001__seed.cql", "-- scylla-migrate:environments dev, Staging\nINSERT INTO t (id) VALUES (1);")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dev", "staging"}, mig.Environments())
+	assert.True(t, mig.RunsIn("dev"))
+	assert.True(t, mig.RunsIn("STAGING"))
+	assert.False(t, mig.RunsIn("prod"))
+	assert.False(t, mig.RunsIn(""))
+
+	plain, err := Parse("V002__table.cql", "CREATE TABLE t (id int PRIMARY KEY);")
+	require.NoError(t, err)
+	assert.Nil(t, plain.Environments())
+	assert.True(t, plain.RunsIn(""))
+	assert.True(t, plain.RunsIn("prod"))
+}
+
+func TestResolver_GetPendingMigrations_Environment(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__create.cql", "CREATE TABLE t (id int PRIMARY KEY);")
+	createTestMigration(t, dir, "V002__seed.cql", "-- scylla-migrate:environments dev\nINSERT INTO t (id) VALUES (1);")
+	createTestMigration(t, dir, "V003__alter.cql", "ALTER TABLE t ADD name text;")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+
+	resolver := NewResolver(scanned)
+	resolver.SetOutOfOrderPolicy(OutOfOrderFail)
+	resolver.SetEnvironment("prod")
+
+	// The skipped V002 must not count as out of order once V003 is applied
+	applied := []schema.AppliedMigration{
+		{Version: "001", Success: true, Type: "versioned"},
+		{Version: "003", Success: true, Type: "versioned"},
+	}
+	pending, err := resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	require.Len(t, resolver.Skipped(), 1)
+	assert.Equal(t, "002", resolver.Skipped()[0].Version)
+
+	resolver.SetEnvironment("dev")
+	resolver.SetOutOfOrderPolicy(OutOfOrderWarnAndApply)
+	pending, err = resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "002", pending[0].Version)
+	assert.Empty(t, resolver.Skipped())
+}
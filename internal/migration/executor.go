@@ -1,8 +1,12 @@
 package migration
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/user"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -21,21 +25,70 @@ type ExecutionContext struct {
 	Logger          zerolog.Logger
 	DryRun          bool
 	hostname        string
+	// operator identifies who runs the command in the event log (user@host)
+	operator string
+
+	// store and cluster are what the executor reads and writes through:
+	// MetadataManager and Session, or fakes in tests
+	store   migrationStore
+	cluster statementSession
+}
+
+// migrationStore is the metadata access the executor needs.
+type migrationStore interface {
+	RecordMigration(rec schema.MigrationRecord, executionTime time.Duration, success bool, hostname string) error
+	RecordEvent(e schema.Event) error
+	ClaimRepeatable(name, checksum, hostname string) error
+	CompleteRepeatable(name, checksum string, executionTime time.Duration, success bool) error
+}
+
+// statementSession is the cluster access the executor needs.
+type statementSession interface {
+	ExecuteContext(ctx context.Context, query string, args ...interface{}) error
+	ExecuteDDLContext(ctx context.Context, query string, args ...interface{}) error
+	WaitForSchemaAgreement(timeout time.Duration) error
+	ObjectExists(kind, keyspace, name, table string) (bool, error)
 }
 
+// openSession and initializeMetadata are replaced in tests.
+var (
+	openSession        = driver.NewSession
+	initializeMetadata = schema.InitializeMetadata
+)
+
 func NewExecutionContext(cfg *config.Config, logger zerolog.Logger) (*ExecutionContext, error) {
-	session, err := driver.NewSession(cfg, logger)
+	session, err := openSession(cfg, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	if err := schema.InitializeMetadata(session, cfg, logger); err != nil {
+	if err := initializeMetadata(session, cfg, logger); err != nil {
 		session.Close()
 		return nil, fmt.Errorf("failed to initialize metadata: %w", err)
 	}
 
+	return newExecutionContext(session, cfg, logger), nil
+}
+
+// NewReadOnlyContext connects without creating or upgrading the metadata
+// keyspace, so read-only commands send no DDL and work with credentials
+// that may only read. Reads fail if the metadata tables do not exist yet.
+func NewReadOnlyContext(cfg *config.Config, logger zerolog.Logger) (*ExecutionContext, error) {
+	session, err := openSession(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return newExecutionContext(session, cfg, logger), nil
+}
+
+func newExecutionContext(session *driver.Session, cfg *config.Config, logger zerolog.Logger) *ExecutionContext {
 	metadataManager := schema.NewMetadataManager(session, cfg.MetadataKeyspace, logger)
-	lockManager := lock.NewLockManager(session, cfg.MetadataKeyspace, logger)
+	metadataManager.SetSafeRecord(cfg.SafeRecord)
+	metadataManager.SetStoreScriptContent(cfg.StoreScriptContent)
+	lockManager := lock.NewLockManager(session, cfg.MetadataKeyspace, cfg.LockOwnerID, lock.Strategy(cfg.LockStrategy), logger)
+	if cfg.Environment != "" {
+		lockManager.SetLockID(lock.MigrationLockID + "_" + strings.ToLower(cfg.Environment))
+	}
 
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -49,30 +102,129 @@ func NewExecutionContext(cfg *config.Config, logger zerolog.Logger) (*ExecutionC
 		LockManager:     lockManager,
 		Logger:          logger,
 		hostname:        hostname,
-	}, nil
+		operator:        operatorName(hostname),
+		store:           metadataManager,
+		cluster:         session,
+	}
+}
+
+func operatorName(hostname string) string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username + "@" + hostname
+	}
+	return hostname
+}
+
+// Operator identifies who runs the command, as user@hostname.
+func (ctx *ExecutionContext) Operator() string {
+	return ctx.operator
 }
 
 func (ctx *ExecutionContext) Close() {
 	ctx.Session.Close()
 }
 
+// RecordEvent writes an entry to the event log on a best-effort basis; a
+// failure is logged but never interrupts the operation being recorded.
+func (ctx *ExecutionContext) RecordEvent(eventType, version, description, message string) {
+	err := ctx.store.RecordEvent(schema.Event{
+		Type:        eventType,
+		Version:     version,
+		Description: description,
+		Actor:       ctx.operator,
+		Message:     message,
+	})
+	if err != nil {
+		ctx.Logger.Warn().Err(err).Str("event", eventType).Msg("Failed to record migration event")
+	}
+}
+
 type Executor struct {
 	ctx *ExecutionContext
+
+	// BeforeEach, when set, is called before each migration in Run.
+	// Returning false skips the migration; returning an error stops the run.
+	BeforeEach func(mig *Migration) (bool, error)
+
+	warnings []string
+	// agreementPending is set after DDL whose agreement wait was deferred
+	agreementPending bool
+	// keepDeferred leaves the deferred agreement wait of the current
+	// migration to the next one, which defers too
+	keepDeferred bool
+	// held are the migrations that ran but wait for the deferred agreement
+	// before they are recorded as applied
+	held []heldRecord
+	// confirmed are the migrations recorded as applied since Run last
+	// collected them
+	confirmed []MigrationResult
+}
+
+// heldRecord is a migration whose statements all ran, recorded by
+// flushAgreement once the schema has settled.
+type heldRecord struct {
+	mig     *Migration
+	rec     schema.MigrationRecord
+	elapsed time.Duration
+}
+
+// errDeferredAgreement marks a failed wait for a deferred schema agreement.
+var errDeferredAgreement = errors.New("schema agreement timeout after deferred DDL")
+
+// StatementError reports the statement a migration failed on.
+type StatementError struct {
+	Version   string
+	Filename  string
+	Index     int // 0-based
+	Statement string
+	Err       error
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("failed to execute statement %d in %s: %v", e.Index+1, e.Filename, e.Err)
+}
+
+func (e *StatementError) Unwrap() error {
+	return e.Err
 }
 
 func NewExecutor(ctx *ExecutionContext) *Executor {
 	return &Executor{ctx: ctx}
 }
 
-func (e *Executor) Execute(mig *Migration) (retErr error) {
+// Execute applies a single migration, including any schema agreement wait
+// it deferred.
+func (e *Executor) Execute(mig *Migration) error {
+	e.keepDeferred = false
+	err := e.execute(mig)
+	e.confirmed = nil
+	if errors.Is(err, errRepeatableTaken) {
+		err = nil
+	}
+	if flushErr := e.flushAgreement(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+func (e *Executor) execute(mig *Migration) (retErr error) {
 	start := time.Now()
 	rec := toRecord(mig)
 
+	timeout, err := mig.StatementTimeout(e.ctx.Config.StatementTimeout)
+	if err != nil {
+		return err
+	}
+	concurrency, err := mig.Concurrency()
+	if err != nil {
+		return err
+	}
+
 	// Panic recovery — record failure and re-panic
 	if !e.ctx.DryRun {
 		defer func() {
 			if r := recover(); r != nil {
-				_ = e.ctx.MetadataManager.RecordMigration(rec, time.Since(start), false, e.ctx.hostname)
+				_ = e.ctx.store.RecordMigration(rec, time.Since(start), false, e.ctx.hostname)
 				panic(r) // re-panic after recording failure
 			}
 		}()
@@ -84,18 +236,21 @@ func (e *Executor) Execute(mig *Migration) (retErr error) {
 			Str("description", mig.Description).
 			Str("type", string(mig.Type)).
 			Int("statements", len(mig.Statements)).
+			Int("concurrency", concurrency).
 			Msg("[DRY RUN] Would apply migration")
 
-		for i, stmt := range mig.Statements {
+		return mig.EachStatement(func(i int, stmt string) error {
+			stmt = ApplyUsingTimeout(stmt, timeout)
 			e.ctx.Logger.Info().
 				Int("statement", i+1).
 				Str("cql", truncateStr(stmt, 120)).
+				Int("bind_args", len(mig.BindArgs(i))).
 				Msg("[DRY RUN] Would execute")
-		}
-		return nil
+			return nil
+		})
 	}
 
-	if len(mig.Statements) == 0 {
+	if len(mig.Statements) == 0 && !mig.Streamed {
 		e.ctx.Logger.Warn().
 			Str("version", mig.Version).
 			Str("file", mig.Filename).
@@ -106,56 +261,332 @@ func (e *Executor) Execute(mig *Migration) (retErr error) {
 		Str("version", mig.Version).
 		Str("description", mig.Description).
 		Int("statements", len(mig.Statements)).
+		Int("concurrency", concurrency).
+		Bool("streamed", mig.Streamed).
 		Msg("Applying migration")
 
-	for i, stmt := range mig.Statements {
-		e.ctx.Logger.Debug().
-			Int("statement", i+1).
-			Int("total", len(mig.Statements)).
-			Msg("Executing statement")
-
-		if err := e.ctx.Session.Execute(stmt); err != nil {
-			_ = e.ctx.MetadataManager.RecordMigration(rec, time.Since(start), false, e.ctx.hostname)
-			return fmt.Errorf("failed to execute statement %d in %s: %w", i+1, mig.Filename, err)
+	// set last, so a panic does not count as success
+	applied, held := false, false
+	if mig.Type == TypeRepeatable {
+		if err := e.claimRepeatable(mig, rec); err != nil {
+			return err
 		}
-
-		if IsDDL(stmt) {
-			e.ctx.Logger.Debug().Msg("Waiting for schema agreement after DDL")
-			if err := e.ctx.Session.WaitForSchemaAgreement(e.ctx.Config.SchemaAgreementTimeout); err != nil {
-				_ = e.ctx.MetadataManager.RecordMigration(rec, time.Since(start), false, e.ctx.hostname)
-				return fmt.Errorf("schema agreement timeout after statement %d in %s: %w", i+1, mig.Filename, err)
+		defer func() {
+			if !held {
+				e.completeRepeatable(mig, rec, time.Since(start), applied)
 			}
+		}()
+	}
+
+	e.ctx.RecordEvent(schema.EventMigrationStarted, rec.Version, mig.Description, "")
+	defer func() {
+		if retErr != nil {
+			e.ctx.RecordEvent(schema.EventMigrationFailed, rec.Version, mig.Description, retErr.Error())
 		}
+	}()
+
+	if concurrency > 1 {
+		err = e.executeConcurrently(mig, concurrency, timeout)
+	} else {
+		err = mig.EachStatement(func(i int, stmt string) error {
+			return e.executeStatement(context.Background(), mig, i, stmt, timeout)
+		})
+	}
+	if err == nil && !e.keepDeferred {
+		// Not applied until its deferred agreement wait is over
+		err = e.flushAgreement()
+	}
+	if err != nil {
+		_ = e.ctx.store.RecordMigration(rec, time.Since(start), false, e.ctx.hostname)
+		return err
 	}
 
 	executionTime := time.Since(start)
-	if err := e.ctx.MetadataManager.RecordMigration(rec, executionTime, true, e.ctx.hostname); err != nil {
-		return fmt.Errorf("migration executed successfully but failed to record metadata: %w", err)
+	if e.agreementPending {
+		// The next migration defers too; recorded once the schema settles
+		e.held = append(e.held, heldRecord{mig: mig, rec: rec, elapsed: executionTime})
+		held = true
+		return nil
+	}
+	if err := e.recordApplied(mig, rec, executionTime); err != nil {
+		return err
+	}
+	applied = true
+	return nil
+}
+
+// recordApplied records mig as successfully applied.
+func (e *Executor) recordApplied(mig *Migration, rec schema.MigrationRecord, executionTime time.Duration) error {
+	if err := e.ctx.store.RecordMigration(rec, executionTime, true, e.ctx.hostname); err != nil {
+		var recorded *schema.AlreadyRecordedError
+		if !errors.As(err, &recorded) {
+			return fmt.Errorf("migration %s executed successfully but failed to record metadata: %w", mig.Filename, err)
+		}
+		// safe_record kept the existing record; the statements ran twice
+		e.warnings = append(e.warnings, fmt.Sprintf("V%s: %s", mig.Version, recorded.Error()))
+		e.ctx.Logger.Warn().
+			Str("version", mig.Version).
+			Str("applied_by", recorded.AppliedBy).
+			Time("applied_at", recorded.AppliedAt).
+			Msg("Migration was already recorded as applied — it may have been applied twice; existing record kept (safe_record)")
 	}
 
+	e.ctx.RecordEvent(schema.EventMigrationApplied, rec.Version, mig.Description,
+		fmt.Sprintf("applied in %s", executionTime.Round(time.Millisecond)))
+
 	e.ctx.Logger.Info().
 		Str("version", mig.Version).
 		Str("description", mig.Description).
 		Dur("duration", executionTime).
 		Msg("Migration applied successfully")
 
+	e.confirmed = append(e.confirmed, MigrationResult{
+		Version:     mig.Version,
+		Description: mig.Description,
+		Type:        mig.Type,
+		Statements:  len(mig.Statements),
+		Duration:    executionTime,
+	})
 	return nil
 }
 
-func (e *Executor) ExecuteAll(migrations []*Migration) (int, error) {
-	total := len(migrations)
+// errRepeatableTaken reports a repeatable migration that another run applied
+// or is applying; the caller skips it.
+var errRepeatableTaken = errors.New("repeatable migration taken by another run")
+
+// claimRepeatable reserves the content of a repeatable migration in the
+// schema_repeatables index, so concurrent runs never both execute it.
+func (e *Executor) claimRepeatable(mig *Migration, rec schema.MigrationRecord) error {
+	err := e.ctx.store.ClaimRepeatable(rec.Version, mig.Checksum, e.ctx.hostname)
+	var taken *schema.RepeatableTakenError
+	if errors.As(err, &taken) {
+		e.ctx.Logger.Warn().
+			Str("description", mig.Description).
+			Str("status", taken.Run.Status).
+			Str("applied_by", taken.Run.AppliedBy).
+			Time("applied_at", taken.Run.AppliedAt).
+			Msg("Repeatable migration already handled by another run, skipping")
+		return errRepeatableTaken
+	}
+	return err
+}
+
+func (e *Executor) completeRepeatable(mig *Migration, rec schema.MigrationRecord, elapsed time.Duration, success bool) {
+	if err := e.ctx.store.CompleteRepeatable(rec.Version, mig.Checksum, elapsed, success); err != nil {
+		e.ctx.Logger.Warn().Err(err).Str("description", mig.Description).Msg("Failed to update repeatable index")
+	}
+}
+
+// executeStatement runs statement i of mig, waiting for schema agreement
+// after DDL. DDL of a migration with the defer-agreement directive skips the
+// wait; it happens once before the next other statement instead. The
+// statement is abandoned once ctx is done.
+func (e *Executor) executeStatement(ctx context.Context, mig *Migration, i int, stmt string, timeout time.Duration) error {
+	deferred := IsDDL(stmt) && mig.DefersAgreement()
+	if !deferred {
+		if err := e.flushAgreement(); err != nil {
+			return err
+		}
+	}
+
+	stmt = ApplyUsingTimeout(stmt, timeout)
+	e.ctx.Logger.Debug().
+		Int("statement", i+1).
+		Int("total", len(mig.Statements)).
+		Msg("Executing statement")
+
+	if e.ctx.Config.SkipExistingObjects && e.objectExists(stmt) {
+		e.ctx.Logger.Info().
+			Str("version", mig.Version).
+			Int("statement", i+1).
+			Str("cql", truncateStr(stmt, 120)).
+			Msg("Object already exists, skipping statement (skip_existing_objects)")
+		return nil
+	}
+
+	exec := e.ctx.cluster.ExecuteContext
+	if IsDDL(stmt) {
+		exec = e.ctx.cluster.ExecuteDDLContext
+	}
+	if err := exec(ctx, stmt, mig.BindArgs(i)...); err != nil {
+		return &StatementError{Version: mig.Version, Filename: mig.Filename, Index: i, Statement: stmt, Err: err}
+	}
+
+	if deferred {
+		e.agreementPending = true
+		return nil
+	}
+	if IsDDL(stmt) {
+		e.ctx.Logger.Debug().Msg("Waiting for schema agreement after DDL")
+		if err := e.ctx.cluster.WaitForSchemaAgreement(e.ctx.Config.SchemaAgreementTimeout); err != nil {
+			return fmt.Errorf("schema agreement timeout after statement %d in %s: %w", i+1, mig.Filename, err)
+		}
+	}
+	return nil
+}
+
+// flushAgreement waits for the schema agreement deferred by defer-agreement
+// migrations, if any, then records the held migrations: as applied once the
+// schema has settled, as failed if it has not.
+func (e *Executor) flushAgreement() error {
+	if !e.agreementPending {
+		return nil
+	}
+	e.agreementPending = false
+	held := e.held
+	e.held = nil
+
+	e.ctx.Logger.Debug().Msg("Waiting for schema agreement after deferred DDL")
+	if err := e.ctx.cluster.WaitForSchemaAgreement(e.ctx.Config.SchemaAgreementTimeout); err != nil {
+		err = fmt.Errorf("%w: %v", errDeferredAgreement, err)
+		for _, h := range held {
+			_ = e.ctx.store.RecordMigration(h.rec, h.elapsed, false, e.ctx.hostname)
+			e.ctx.RecordEvent(schema.EventMigrationFailed, h.rec.Version, h.mig.Description, err.Error())
+			if h.mig.Type == TypeRepeatable {
+				e.completeRepeatable(h.mig, h.rec, h.elapsed, false)
+			}
+		}
+		return err
+	}
+
+	var firstErr error
+	for _, h := range held {
+		err := e.recordApplied(h.mig, h.rec, h.elapsed)
+		if h.mig.Type == TypeRepeatable {
+			e.completeRepeatable(h.mig, h.rec, h.elapsed, err == nil)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// objectExists reports whether stmt creates a table, type or index that is
+// already present. Lookup errors are logged and treated as "missing", so the
+// statement runs and fails (or succeeds) on its own merits.
+func (e *Executor) objectExists(stmt string) bool {
+	target, ok := ParseCreateTarget(stmt)
+	if !ok {
+		return false
+	}
+	if target.Keyspace == "" {
+		target.Keyspace = e.ctx.Config.Keyspace
+	}
+
+	exists, err := e.ctx.cluster.ObjectExists(target.Kind, target.Keyspace, target.Name, target.Table)
+	if err != nil {
+		e.ctx.Logger.Warn().Err(err).Str("object", target.Keyspace+"."+target.Name).Msg("Failed to check whether object exists")
+		return false
+	}
+	return exists
+}
+
+// MigrationResult describes a single migration applied during a run.
+type MigrationResult struct {
+	Version     string
+	Description string
+	Type        MigrationType
+	Statements  int
+	Duration    time.Duration
+}
+
+// RunResult summarizes a batch execution. Err is set when the run stopped
+// early; Applied then holds the migrations that completed before the failure.
+type RunResult struct {
+	Applied  []MigrationResult
+	Skipped  int
+	Total    int
+	Duration time.Duration
+	Err      error
+	// Warnings raised while executing, e.g. a record kept by safe_record
+	Warnings []string
+}
+
+func (r *RunResult) AppliedVersions() []string {
+	versions := make([]string, 0, len(r.Applied))
+	for _, a := range r.Applied {
+		if a.Type == TypeRepeatable {
+			versions = append(versions, "R__"+a.Description)
+			continue
+		}
+		versions = append(versions, a.Version)
+	}
+	return versions
+}
+
+func (e *Executor) Run(migrations []*Migration) *RunResult {
+	if e.ctx.Config.CrossMigrationPhasing {
+		return e.runPhased(migrations)
+	}
+
+	start := time.Now()
+	result := &RunResult{Total: len(migrations)}
+	e.warnings = nil
+	e.confirmed = nil
+
 	for i, mig := range migrations {
 		e.ctx.Logger.Info().
 			Int("current", i+1).
-			Int("total", total).
+			Int("total", result.Total).
 			Str("version", mig.Version).
 			Msg("Processing migration")
 
-		if err := e.Execute(mig); err != nil {
-			return i, err
+		if e.BeforeEach != nil && !e.ctx.DryRun {
+			proceed, err := e.BeforeEach(mig)
+			if err != nil {
+				result.Err = err
+				break
+			}
+			if !proceed {
+				e.ctx.Logger.Warn().
+					Str("version", mig.Version).
+					Str("description", mig.Description).
+					Msg("Migration skipped")
+				result.Skipped++
+				continue
+			}
+		}
+
+		e.keepDeferred = i+1 < len(migrations) && migrations[i+1].DefersAgreement()
+		err := e.execute(mig)
+		result.Applied = append(result.Applied, e.confirmed...)
+		e.confirmed = nil
+		if errors.Is(err, errRepeatableTaken) {
+			result.Skipped++
+			continue
+		} else if err != nil {
+			result.Err = err
+			break
 		}
 	}
-	return total, nil
+
+	e.finishAgreement(result)
+	result.Applied = append(result.Applied, e.confirmed...)
+	e.confirmed = nil
+	result.Duration = time.Since(start)
+	result.Warnings = e.warnings
+	return result
+}
+
+// finishAgreement runs the final deferred agreement wait of a run. After a
+// failure the wait still happens, but its error only adds a warning.
+func (e *Executor) finishAgreement(result *RunResult) {
+	err := e.flushAgreement()
+	if err == nil {
+		return
+	}
+	if result.Err == nil {
+		result.Err = err
+		return
+	}
+	e.warnings = append(e.warnings, err.Error())
+	e.ctx.Logger.Warn().Err(err).Msg("Schema agreement not reached after deferred DDL")
+}
+
+func (e *Executor) ExecuteAll(migrations []*Migration) (int, error) {
+	result := e.Run(migrations)
+	return len(result.Applied), result.Err
 }
 
 func toRecord(mig *Migration) schema.MigrationRecord {
@@ -169,6 +600,7 @@ func toRecord(mig *Migration) schema.MigrationRecord {
 		Type:        string(mig.Type),
 		Filename:    mig.Filename,
 		Checksum:    mig.Checksum,
+		Content:     mig.RawContent,
 	}
 }
 
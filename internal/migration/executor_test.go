@@ -0,0 +1,220 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+type recordedMigration struct {
+	Version string
+	Success bool
+}
+
+type completedRepeatable struct {
+	Name    string
+	Success bool
+}
+
+// fakeStore stands in for the MetadataManager.
+type fakeStore struct {
+	mu         sync.Mutex
+	records    []recordedMigration
+	events     []schema.Event
+	completed  []completedRepeatable
+	taken      map[string]bool  // repeatables another run holds
+	recordErrs map[string]error // RecordMigration errors by version
+}
+
+func (s *fakeStore) RecordMigration(rec schema.MigrationRecord, _ time.Duration, success bool, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.recordErrs[rec.Version]; err != nil && success {
+		return err
+	}
+	s.records = append(s.records, recordedMigration{Version: rec.Version, Success: success})
+	return nil
+}
+
+func (s *fakeStore) RecordEvent(e schema.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *fakeStore) ClaimRepeatable(name, _, _ string) error {
+	if s.taken[name] {
+		return &schema.RepeatableTakenError{Run: schema.RepeatableRun{Name: name, Status: schema.RepeatableApplied}}
+	}
+	return nil
+}
+
+func (s *fakeStore) CompleteRepeatable(name, _ string, _ time.Duration, success bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = append(s.completed, completedRepeatable{Name: name, Success: success})
+	return nil
+}
+
+// eventTypes returns the type of each event recorded for version.
+func (s *fakeStore) eventTypes(version string) []string {
+	var types []string
+	for _, e := range s.events {
+		if e.Version == version {
+			types = append(types, e.Type)
+		}
+	}
+	return types
+}
+
+// fakeCluster stands in for the Session. Statements containing failOn fail,
+// those containing panicOn panic, and every agreement wait fails when
+// agreementErr is set.
+type fakeCluster struct {
+	mu           sync.Mutex
+	executed     []string
+	agreements   int
+	failOn       string
+	panicOn      string
+	agreementErr error
+}
+
+func (c *fakeCluster) ExecuteContext(ctx context.Context, query string, _ ...interface{}) error {
+	if c.panicOn != "" && strings.Contains(query, c.panicOn) {
+		panic("statement panicked")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failOn != "" && strings.Contains(query, c.failOn) {
+		return errors.New("statement failed")
+	}
+	c.executed = append(c.executed, query)
+	return nil
+}
+
+func (c *fakeCluster) ExecuteDDLContext(ctx context.Context, query string, args ...interface{}) error {
+	return c.ExecuteContext(ctx, query, args...)
+}
+
+func (c *fakeCluster) WaitForSchemaAgreement(time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.agreements++
+	return c.agreementErr
+}
+
+func (c *fakeCluster) ObjectExists(_, _, _, _ string) (bool, error) {
+	return false, nil
+}
+
+func newTestExecutor(store *fakeStore, cluster *fakeCluster) *Executor {
+	return NewExecutor(&ExecutionContext{
+		Config:   &config.Config{Keyspace: "app"},
+		Logger:   zerolog.Nop(),
+		hostname: "test-host",
+		store:    store,
+		cluster:  cluster,
+	})
+}
+
+func TestRun_DeferredAgreementBeforeRecord(t *testing.T) {
+	migs := parsePhased(t,
+		"V001__a.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE a (id int PRIMARY KEY);",
+		"V002__b.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE b (id int PRIMARY KEY);",
+		"V003__c.cql", "CREATE TABLE c (id int PRIMARY KEY);",
+	)
+
+	store, cluster := &fakeStore{}, &fakeCluster{}
+	result := newTestExecutor(store, cluster).Run(migs)
+	require.NoError(t, result.Err)
+	// One wait for both deferring migrations, one after V003's DDL
+	assert.Equal(t, 2, cluster.agreements)
+	assert.Equal(t, []recordedMigration{{"001", true}, {"002", true}, {"003", true}}, store.records)
+
+	// Deferring migrations are held until the wait: none is applied when
+	// it fails, as in a phased run
+	store, cluster = &fakeStore{}, &fakeCluster{agreementErr: errors.New("timeout")}
+	result = newTestExecutor(store, cluster).Run(migs)
+	require.ErrorIs(t, result.Err, errDeferredAgreement)
+	assert.Equal(t, []recordedMigration{{"001", false}, {"002", false}}, store.records)
+	assert.Equal(t, []string{"migration_started", "migration_failed"}, store.eventTypes("001"))
+	assert.NotContains(t, cluster.executed, "CREATE TABLE c (id int PRIMARY KEY)")
+	assert.Empty(t, result.Applied)
+}
+
+func TestRun_HeldRepeatableCompletedAfterAgreement(t *testing.T) {
+	migs := parsePhased(t,
+		"R__a.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE a (id int PRIMARY KEY);",
+		"R__b.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE b (id int PRIMARY KEY);",
+	)
+
+	store, cluster := &fakeStore{}, &fakeCluster{}
+	result := newTestExecutor(store, cluster).Run(migs)
+	require.NoError(t, result.Err)
+	assert.Equal(t, []completedRepeatable{{"R_a", true}, {"R_b", true}}, store.completed)
+	assert.Len(t, result.Applied, 2)
+
+	store, cluster = &fakeStore{}, &fakeCluster{agreementErr: errors.New("timeout")}
+	result = newTestExecutor(store, cluster).Run(migs)
+	require.ErrorIs(t, result.Err, errDeferredAgreement)
+	assert.Equal(t, []completedRepeatable{{"R_a", false}, {"R_b", false}}, store.completed)
+	assert.Empty(t, result.Applied)
+}
+
+func TestExecute_DeferredAgreementBeforeRecord(t *testing.T) {
+	migs := parsePhased(t, "V001__a.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE a (id int PRIMARY KEY);")
+
+	store, cluster := &fakeStore{}, &fakeCluster{agreementErr: errors.New("timeout")}
+	require.ErrorIs(t, newTestExecutor(store, cluster).Execute(migs[0]), errDeferredAgreement)
+	assert.Equal(t, []recordedMigration{{"001", false}}, store.records)
+}
+
+func TestExecute_ConcurrentPanicRecordsFailure(t *testing.T) {
+	migs := parsePhased(t,
+		"V001__a.cql", "-- scylla-migrate:concurrency 2\nINSERT INTO a (id) VALUES (1);\nINSERT INTO a (id) VALUES (2);",
+	)
+	store, cluster := &fakeStore{}, &fakeCluster{panicOn: "VALUES (2)"}
+	// The worker's panic reaches Execute, which records the failure and
+	// panics again
+	assert.PanicsWithValue(t, "statement panicked", func() {
+		_ = newTestExecutor(store, cluster).Execute(migs[0])
+	})
+	assert.Equal(t, []recordedMigration{{Version: "001", Success: false}}, store.records)
+}
+
+func TestNewReadOnlyContext_SendsNoDDL(t *testing.T) {
+	cluster := &fakeCluster{}
+	origOpen, origInit := openSession, initializeMetadata
+	t.Cleanup(func() { openSession, initializeMetadata = origOpen, origInit })
+	openSession = func(*config.Config, zerolog.Logger) (*driver.Session, error) {
+		return &driver.Session{}, nil
+	}
+	initializeMetadata = func(_ *driver.Session, cfg *config.Config, _ zerolog.Logger) error {
+		return cluster.ExecuteDDLContext(context.Background(), "CREATE KEYSPACE IF NOT EXISTS "+cfg.MetadataKeyspace)
+	}
+	cfg := &config.Config{Keyspace: "app", MetadataKeyspace: "app_meta"}
+
+	ctx, err := NewReadOnlyContext(cfg, zerolog.Nop())
+	require.NoError(t, err)
+	assert.NotNil(t, ctx.MetadataManager)
+	assert.Empty(t, cluster.executed)
+
+	_, err = NewExecutionContext(cfg, zerolog.Nop())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CREATE KEYSPACE IF NOT EXISTS app_meta"}, cluster.executed)
+}
@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"regexp"
+	"strings"
+)
+
+const cqlIdent = `("(?:[^"]|"")+"|\w+)`
+
+var (
+	createObjectPattern = regexp.MustCompile(`(?is)^CREATE\s+(TABLE|COLUMNFAMILY|TYPE)\s+(IF\s+NOT\s+EXISTS\s+)?` +
+		cqlIdent + `(?:\s*\.\s*` + cqlIdent + `)?`)
+	createIndexPattern = regexp.MustCompile(`(?is)^CREATE\s+(?:CUSTOM\s+)?INDEX\s+(IF\s+NOT\s+EXISTS\s+)?` +
+		cqlIdent + `?\s*\bON\s+` + cqlIdent + `(?:\s*\.\s*` + cqlIdent + `)?`)
+)
+
+// CreateTarget is the schema object a CREATE TABLE/TYPE/INDEX statement
+// would create. Keyspace is empty when the statement does not qualify the
+// name. Table is only set for indexes.
+type CreateTarget struct {
+	Kind     string // "table", "type" or "index"
+	Keyspace string
+	Name     string
+	Table    string
+}
+
+// ParseCreateTarget extracts the object created by stmt. It returns false
+// for other statements, for statements that already use IF NOT EXISTS, and
+// for unnamed indexes, none of which can be checked for existence.
+func ParseCreateTarget(stmt string) (CreateTarget, bool) {
+	if m := createObjectPattern.FindStringSubmatch(stmt); m != nil {
+		if m[2] != "" {
+			return CreateTarget{}, false
+		}
+		kind := strings.ToLower(m[1])
+		if kind == "columnfamily" {
+			kind = "table"
+		}
+		ks, name := splitQualified(m[3], m[4])
+		return CreateTarget{Kind: kind, Keyspace: ks, Name: name}, true
+	}
+
+	if m := createIndexPattern.FindStringSubmatch(stmt); m != nil {
+		if m[1] != "" || m[2] == "" {
+			return CreateTarget{}, false
+		}
+		ks, table := splitQualified(m[3], m[4])
+		return CreateTarget{Kind: "index", Keyspace: ks, Name: UnquoteIdentifier(m[2]), Table: table}, true
+	}
+
+	return CreateTarget{}, false
+}
+
+// splitQualified turns "a" / "a.b" matches into keyspace and object name.
+func splitQualified(first, second string) (string, string) {
+	if second == "" {
+		return "", UnquoteIdentifier(first)
+	}
+	return UnquoteIdentifier(first), UnquoteIdentifier(second)
+}
+
+// UnquoteIdentifier returns the name as stored in system_schema: unquoted
+// identifiers are case-insensitive and stored lowercase; quoted ones are kept
+// verbatim.
+func UnquoteIdentifier(ident string) string {
+	if strings.HasPrefix(ident, `"`) && strings.HasSuffix(ident, `"`) && len(ident) >= 2 {
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+	return strings.ToLower(ident)
+}
@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCreateTarget(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want CreateTarget
+		ok   bool
+	}{
+		{"CREATE TABLE app.Users (id int PRIMARY KEY)", CreateTarget{Kind: "table", Keyspace: "app", Name: "users"}, true},
+		{"create table \"MyTable\" (id int PRIMARY KEY)", CreateTarget{Kind: "table", Name: "MyTable"}, true},
+		{"CREATE COLUMNFAMILY app.t (id int PRIMARY KEY)", CreateTarget{Kind: "table", Keyspace: "app", Name: "t"}, true},
+		{"CREATE TYPE app.address (street text)", CreateTarget{Kind: "type", Keyspace: "app", Name: "address"}, true},
+		{"CREATE INDEX users_email_idx ON app.users (email)", CreateTarget{Kind: "index", Keyspace: "app", Name: "users_email_idx", Table: "users"}, true},
+		{"CREATE CUSTOM INDEX idx ON t (c) USING 'x'", CreateTarget{Kind: "index", Name: "idx", Table: "t"}, true},
+		{"CREATE TABLE IF NOT EXISTS app.users (id int PRIMARY KEY)", CreateTarget{}, false},
+		{"CREATE INDEX IF NOT EXISTS idx ON t (c)", CreateTarget{}, false},
+		{"CREATE INDEX ON app.users (email)", CreateTarget{}, false},
+		{"ALTER TABLE app.users ADD x int", CreateTarget{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseCreateTarget(tt.stmt)
+		assert.Equal(t, tt.ok, ok, tt.stmt)
+		assert.Equal(t, tt.want, got, tt.stmt)
+	}
+}
@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// timestampVersionDigits is the length from which versions are taken to be
+// timestamps (20240101 and longer), which are not expected to be contiguous.
+const timestampVersionDigits = 8
+
+// VersionGap is a run of missing versions between two versioned migrations.
+type VersionGap struct {
+	// After and Before are the versions on either side of the gap
+	After, Before string
+	// Next is the file of version Before
+	Next string
+	// Missing is the number of versions in the gap
+	Missing int
+}
+
+func (g VersionGap) String() string {
+	if g.Missing == 1 {
+		return fmt.Sprintf("version between V%s and V%s is missing", g.After, g.Before)
+	}
+	return fmt.Sprintf("%d versions between V%s and V%s are missing", g.Missing, g.After, g.Before)
+}
+
+// FindVersionGaps reports gaps in the numeric sequence of versioned
+// migrations, which usually mean a lost or misnamed file. Timestamp
+// versions are skipped, and so is everything when any version is one.
+func FindVersionGaps(migrations []*Migration) []VersionGap {
+	type entry struct {
+		num int
+		mig *Migration
+	}
+	var versions []entry
+	for _, mig := range migrations {
+		if mig.Type != TypeVersioned {
+			continue
+		}
+		if len(mig.Version) >= timestampVersionDigits {
+			return nil
+		}
+		n, err := strconv.Atoi(mig.Version)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, entry{n, mig})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].num < versions[j].num })
+
+	var gaps []VersionGap
+	for i := 1; i < len(versions); i++ {
+		prev, cur := versions[i-1], versions[i]
+		if missing := cur.num - prev.num - 1; missing > 0 {
+			gaps = append(gaps, VersionGap{
+				After:   prev.mig.Version,
+				Before:  cur.mig.Version,
+				Next:    cur.mig.Filename,
+				Missing: missing,
+			})
+		}
+	}
+	return gaps
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func versioned(version string) *Migration {
+	return &Migration{Version: version, Type: TypeVersioned, Filename: "V" + version + "__x.cql"}
+}
+
+func TestFindVersionGaps(t *testing.T) {
+	migs := []*Migration{
+		versioned("001"), versioned("003"), versioned("004"), versioned("8"),
+		{Version: "002", Type: TypeUndo, Filename: "U002__x.cql"},
+		{Version: "R", Type: TypeRepeatable, Filename: "R__views.cql"},
+	}
+
+	gaps := FindVersionGaps(migs)
+	require.Len(t, gaps, 2)
+	assert.Equal(t, VersionGap{After: "001", Before: "003", Next: "V003__x.cql", Missing: 1}, gaps[0])
+	assert.Equal(t, "version between V001 and V003 is missing", gaps[0].String())
+	assert.Equal(t, "3 versions between V004 and V8 are missing", gaps[1].String())
+
+	assert.Empty(t, FindVersionGaps([]*Migration{versioned("1"), versioned("2"), versioned("3")}))
+}
+
+func TestFindVersionGaps_TimestampVersions(t *testing.T) {
+	migs := []*Migration{versioned("20240101120000"), versioned("20240315093000")}
+	assert.Empty(t, FindVersionGaps(migs))
+}
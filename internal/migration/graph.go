@@ -0,0 +1,143 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GraphEdge says that migration From must be applied before To. Edges are
+// either declared with depends-on or link consecutive steps of the plan.
+type GraphEdge struct {
+	From, To  string
+	DependsOn bool
+}
+
+// Graph is the ordering graph of the versioned migrations: one node per
+// version, an edge between consecutive steps of the forward plan and one
+// edge per declared dependency.
+type Graph struct {
+	Nodes []*Migration
+	// Missing lists versions that are depended on but have no file
+	Missing []string
+	Edges   []GraphEdge
+	// Err is why no plan could be built, e.g. a dependency cycle; the
+	// graph then has only dependency edges and Nodes are in version order
+	Err error
+}
+
+// Graph builds the ordering graph from the forward plan the resolver would
+// execute against an empty keyspace, so the plan-order edges show the real
+// run order. When no plan can be built the error is kept in Err instead of
+// returned, so cycles and unknown dependencies can be seen in the rendered
+// graph.
+func (r *Resolver) Graph() *Graph {
+	g := &Graph{}
+	plan, err := r.BuildPlan(nil, "", DirectionForward)
+	if err != nil {
+		g.Err = err
+		g.Nodes = r.GetVersionedMigrations()
+	} else {
+		for _, step := range plan.Steps {
+			if step.Migration.Type == TypeVersioned {
+				g.Nodes = append(g.Nodes, step.Migration)
+			}
+		}
+		for i := 1; i < len(g.Nodes); i++ {
+			g.Edges = append(g.Edges, GraphEdge{From: g.Nodes[i-1].Version, To: g.Nodes[i].Version})
+		}
+	}
+
+	known := make(map[string]string, len(g.Nodes))
+	for _, mig := range g.Nodes {
+		known[normalizeVersion(mig.Version)] = mig.Version
+	}
+	missing := make(map[string]bool)
+	for _, mig := range g.Nodes {
+		for _, dep := range mig.Dependencies() {
+			from, ok := known[normalizeVersion(dep)]
+			if !ok {
+				from = dep
+				if !missing[dep] {
+					missing[dep] = true
+					g.Missing = append(g.Missing, dep)
+				}
+			}
+			g.Edges = append(g.Edges, GraphEdge{From: from, To: mig.Version, DependsOn: true})
+		}
+	}
+	return g
+}
+
+// normalizeVersion drops leading zeros so "3" and "003" name the same
+// version, as CompareVersions treats them.
+func normalizeVersion(version string) string {
+	v := strings.TrimLeft(version, "0")
+	if v == "" {
+		return "0"
+	}
+	return v
+}
+
+// WriteDOT renders the graph in Graphviz DOT format. Plan-order edges are dashed and missing dependencies are drawn in red.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph migrations {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, mig := range g.Nodes {
+		fmt.Fprintf(&b, "  %q [label=%q];\n", "V"+mig.Version, "V"+mig.Version+"\n"+mig.Description)
+	}
+	for _, v := range g.Missing {
+		fmt.Fprintf(&b, "  %q [label=%q, color=red, style=dashed];\n", "V"+v, "V"+v+"\n(missing)")
+	}
+	for _, e := range g.Edges {
+		if e.DependsOn {
+			fmt.Fprintf(&b, "  %q -> %q;\n", "V"+e.From, "V"+e.To)
+		} else {
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed, color=gray];\n", "V"+e.From, "V"+e.To)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid renders the graph as a Mermaid flowchart, for embedding in
+// Markdown. Plan-order edges are dotted. Node IDs are numbered, since
+// versions such as 1.2 are not valid Mermaid IDs; the version is the label.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	ids := make(map[string]string, len(g.Nodes)+len(g.Missing))
+	id := func(version string) string {
+		if _, ok := ids[version]; !ok {
+			ids[version] = fmt.Sprintf("n%d", len(ids))
+		}
+		return ids[version]
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, mig := range g.Nodes {
+		fmt.Fprintf(&b, "  %s[\"V%s %s\"]\n", id(mig.Version), mig.Version, mermaidEscape(mig.Description))
+	}
+	for _, v := range g.Missing {
+		fmt.Fprintf(&b, "  %s[\"V%s (missing)\"]:::missing\n", id(v), mermaidEscape(v))
+	}
+	for _, e := range g.Edges {
+		if e.DependsOn {
+			fmt.Fprintf(&b, "  %s --> %s\n", id(e.From), id(e.To))
+		} else {
+			fmt.Fprintf(&b, "  %s -.-> %s\n", id(e.From), id(e.To))
+		}
+	}
+	if len(g.Missing) > 0 {
+		b.WriteString("  classDef missing stroke:#d00,stroke-dasharray:4\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape makes text safe inside a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
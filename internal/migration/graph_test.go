@@ -0,0 +1,103 @@
+package migration
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func graphDir(t *testing.T, files map[string]string) []*Migration {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		createTestMigration(t, dir, name, content)
+	}
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	return scanned
+}
+
+func TestResolver_Graph(t *testing.T) {
+	g := NewResolver(graphDir(t, map[string]string{
+		"V001__a.cql":  "CREATE TABLE a (id int PRIMARY KEY);",
+		"V002__b.cql":  "-- scylla-migrate:depends-on 3\nCREATE TABLE b (id int PRIMARY KEY);",
+		"V003__c.cql":  "CREATE TABLE c (id int PRIMARY KEY);",
+		"R__views.cql": "SELECT now() FROM system.local;",
+		"U001__a.cql":  "DROP TABLE a;",
+	})).Graph()
+
+	require.NoError(t, g.Err)
+	var order []string
+	for _, mig := range g.Nodes {
+		order = append(order, mig.Version)
+	}
+	// plan order, not version order: 002 waits for 003
+	assert.Equal(t, []string{"001", "003", "002"}, order)
+	assert.Empty(t, g.Missing)
+	assert.Equal(t, []GraphEdge{
+		{From: "001", To: "003"},
+		{From: "003", To: "002"},
+		{From: "003", To: "002", DependsOn: true},
+	}, g.Edges)
+}
+
+func TestResolver_Graph_Unplannable(t *testing.T) {
+	g := NewResolver(graphDir(t, map[string]string{
+		"V001__a.cql": "CREATE TABLE a (id int PRIMARY KEY);",
+		"V003__c.cql": "-- scylla-migrate:depends-on 1, V009\nCREATE TABLE c (id int PRIMARY KEY);",
+	})).Graph()
+
+	require.Error(t, g.Err)
+	assert.Contains(t, g.Err.Error(), "V009")
+	require.Len(t, g.Nodes, 2)
+	assert.Equal(t, []string{"009"}, g.Missing)
+	assert.Equal(t, []GraphEdge{
+		{From: "001", To: "003", DependsOn: true},
+		{From: "009", To: "003", DependsOn: true},
+	}, g.Edges)
+}
+
+func TestGraph_Render(t *testing.T) {
+	a, b := versioned("001"), versioned("002")
+	a.Description = `say "hi"`
+	g := &Graph{
+		Nodes: []*Migration{a, b},
+		Edges: []GraphEdge{{From: "001", To: "002"}, {From: "001", To: "002", DependsOn: true}},
+	}
+
+	var dot bytes.Buffer
+	require.NoError(t, g.WriteDOT(&dot))
+	assert.Contains(t, dot.String(), `"V001" [label="V001\nsay \"hi\""];`)
+	assert.Contains(t, dot.String(), `"V001" -> "V002" [style=dashed, color=gray];`)
+	assert.Contains(t, dot.String(), `"V001" -> "V002";`)
+
+	var mermaid bytes.Buffer
+	require.NoError(t, g.WriteMermaid(&mermaid))
+	assert.Contains(t, mermaid.String(), "flowchart LR\n")
+	assert.Contains(t, mermaid.String(), `n0["V001 say #quot;hi#quot;"]`)
+	assert.Contains(t, mermaid.String(), "n0 -.-> n1\n")
+	assert.Contains(t, mermaid.String(), "n0 --> n1\n")
+}
+
+func TestGraph_WriteMermaid_DottedVersions(t *testing.T) {
+	g := &Graph{
+		Nodes:   []*Migration{versioned("1.2"), versioned("1.10")},
+		Missing: []string{"1.3"},
+		Edges: []GraphEdge{
+			{From: "1.2", To: "1.10"},
+			{From: "1.3", To: "1.10", DependsOn: true},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, g.WriteMermaid(&out))
+	assert.Equal(t, "flowchart LR\n"+
+		"  n0[\"V1.2 \"]\n"+
+		"  n1[\"V1.10 \"]\n"+
+		"  n2[\"V1.3 (missing)\"]:::missing\n"+
+		"  n0 -.-> n1\n"+
+		"  n2 --> n1\n"+
+		"  classDef missing stroke:#d00,stroke-dasharray:4\n", out.String())
+}
@@ -0,0 +1,29 @@
+package migration
+
+import "strings"
+
+// ReservedKeywords are the CQL keywords that cannot be used as unquoted
+// identifiers. Non-reserved keywords (e.g. KEY, TTL, TYPE) are valid names.
+var ReservedKeywords = []string{
+	"ADD", "ALLOW", "ALTER", "AND", "APPLY", "ASC", "AUTHORIZE", "BATCH",
+	"BEGIN", "BY", "COLUMNFAMILY", "CREATE", "DELETE", "DESC", "DESCRIBE",
+	"DROP", "ENTRIES", "EXECUTE", "FROM", "FULL", "GRANT", "IF", "IN",
+	"INDEX", "INFINITY", "INSERT", "INTO", "IS", "KEYSPACE", "LIMIT",
+	"MODIFY", "NAN", "NORECURSIVE", "NOT", "NULL", "OF", "ON", "OR", "ORDER",
+	"PRIMARY", "RENAME", "REPLACE", "REVOKE", "SCHEMA", "SELECT", "SET",
+	"TABLE", "TO", "TOKEN", "TRUNCATE", "UNLOGGED", "UPDATE", "USE", "USING",
+	"VIEW", "WHERE", "WITH",
+}
+
+var reservedSet = func() map[string]bool {
+	set := make(map[string]bool, len(ReservedKeywords))
+	for _, kw := range ReservedKeywords {
+		set[kw] = true
+	}
+	return set
+}()
+
+// IsReservedKeyword reports whether name, unquoted, is a reserved CQL keyword.
+func IsReservedKeyword(name string) bool {
+	return reservedSet[strings.ToUpper(name)]
+}
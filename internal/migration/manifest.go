@@ -0,0 +1,148 @@
+package migration
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ManifestFileName is the name of the optional manifest pinning the expected
+// set of migration files. It lives in the migrations directory and is
+// unrelated to the distributed lock held during migrate.
+const ManifestFileName = "migrations.lock"
+
+const manifestHeader = `# scylla-migrate migrations manifest
+# Pins the expected set of migration files and their checksums.
+# Regenerate with: scylla-migrate migrate --update-lock
+`
+
+type ManifestEntry struct {
+	Filename string
+	Checksum string
+}
+
+type ManifestDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (d ManifestDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// BuildManifest parses every scanned migration and returns its manifest
+// entries sorted by filename.
+func BuildManifest(migrations []*Migration) ([]ManifestEntry, error) {
+	entries := make([]ManifestEntry, 0, len(migrations))
+	for _, mig := range migrations {
+		if err := ParseMigrationFile(mig); err != nil {
+			return nil, fmt.Errorf("failed to parse migration %s: %w", mig.Filename, err)
+		}
+		entries = append(entries, ManifestEntry{Filename: mig.Filename, Checksum: mig.Checksum})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Filename < entries[j].Filename
+	})
+	return entries, nil
+}
+
+// ReadManifest reads a manifest in the "<checksum>  <filename>" line format.
+// Blank lines and lines starting with '#' are ignored.
+func ReadManifest(path string) ([]ManifestEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed manifest line %d in %s", lineNum, path)
+		}
+		entries = append(entries, ManifestEntry{Checksum: fields[0], Filename: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+func WriteManifest(path string, entries []ManifestEntry) error {
+	var b strings.Builder
+	b.WriteString(manifestHeader)
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s  %s\n", e.Checksum, e.Filename)
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", path, err)
+	}
+	return nil
+}
+
+// CompareAppendOnly is CompareManifest for the append-only rule: Changed and
+// Removed only list versioned migrations, since repeatable and undo files
+// may legitimately be edited or dropped after a release.
+func CompareAppendOnly(baseline, current []ManifestEntry) ManifestDiff {
+	diff := CompareManifest(baseline, current)
+	diff.Changed = onlyVersioned(diff.Changed)
+	diff.Removed = onlyVersioned(diff.Removed)
+	return diff
+}
+
+func onlyVersioned(filenames []string) []string {
+	var versioned []string
+	for _, name := range filenames {
+		if mig, err := parseMigrationFilename(name, ""); err == nil && mig.Type == TypeVersioned {
+			versioned = append(versioned, name)
+		}
+	}
+	return versioned
+}
+
+// CompareManifest reports files that were added, removed, or changed in
+// current relative to the pinned expected set.
+func CompareManifest(expected, current []ManifestEntry) ManifestDiff {
+	expectedMap := make(map[string]string, len(expected))
+	for _, e := range expected {
+		expectedMap[e.Filename] = e.Checksum
+	}
+	currentMap := make(map[string]string, len(current))
+	for _, c := range current {
+		currentMap[c.Filename] = c.Checksum
+	}
+
+	var diff ManifestDiff
+	for _, c := range current {
+		checksum, exists := expectedMap[c.Filename]
+		if !exists {
+			diff.Added = append(diff.Added, c.Filename)
+		} else if checksum != c.Checksum {
+			diff.Changed = append(diff.Changed, c.Filename)
+		}
+	}
+	for _, e := range expected {
+		if _, exists := currentMap[e.Filename]; !exists {
+			diff.Removed = append(diff.Removed, e.Filename)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
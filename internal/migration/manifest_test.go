@@ -0,0 +1,86 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V002__second.cql", "CREATE TABLE second (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V001__first.cql", "CREATE TABLE first (id UUID PRIMARY KEY);")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+
+	entries, err := BuildManifest(scanned)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "V001__first.cql", entries[0].Filename)
+
+	path := filepath.Join(dir, ManifestFileName)
+	require.NoError(t, WriteManifest(path, entries))
+
+	read, err := ReadManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, entries, read)
+
+	// The manifest itself must not be picked up as a migration
+	rescanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, rescanned, 2)
+}
+
+func TestReadManifest_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ManifestFileName)
+	require.NoError(t, os.WriteFile(path, []byte("abc123\n"), 0644))
+
+	_, err := ReadManifest(path)
+	assert.Error(t, err)
+}
+
+func TestCompareManifest(t *testing.T) {
+	expected := []ManifestEntry{
+		{Filename: "V001__first.cql", Checksum: "aaa"},
+		{Filename: "V002__second.cql", Checksum: "bbb"},
+		{Filename: "V003__third.cql", Checksum: "ccc"},
+	}
+	current := []ManifestEntry{
+		{Filename: "V001__first.cql", Checksum: "aaa"},
+		{Filename: "V002__second.cql", Checksum: "changed"},
+		{Filename: "V004__fourth.cql", Checksum: "ddd"},
+	}
+
+	diff := CompareManifest(expected, current)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []string{"V004__fourth.cql"}, diff.Added)
+	assert.Equal(t, []string{"V003__third.cql"}, diff.Removed)
+	assert.Equal(t, []string{"V002__second.cql"}, diff.Changed)
+
+	assert.True(t, CompareManifest(expected, expected).Empty())
+}
+
+func TestCompareAppendOnly(t *testing.T) {
+	baseline := []ManifestEntry{
+		{Filename: "R__views.cql", Checksum: "rrr"},
+		{Filename: "U002__second.cql", Checksum: "uuu"},
+		{Filename: "V001__first.cql", Checksum: "aaa"},
+		{Filename: "V002__second.cql", Checksum: "bbb"},
+		{Filename: "V003__third.cql", Checksum: "ccc"},
+	}
+	current := []ManifestEntry{
+		{Filename: "R__views.cql", Checksum: "edited"},
+		{Filename: "V001__first.cql", Checksum: "aaa"},
+		{Filename: "V002__second.cql", Checksum: "changed"},
+		{Filename: "V004__fourth.cql", Checksum: "ddd"},
+	}
+
+	diff := CompareAppendOnly(baseline, current)
+	assert.Equal(t, []string{"V004__fourth.cql"}, diff.Added)
+	assert.Equal(t, []string{"V003__third.cql"}, diff.Removed)
+	assert.Equal(t, []string{"V002__second.cql"}, diff.Changed)
+}
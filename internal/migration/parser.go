@@ -1,36 +1,144 @@
 package migration
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"unicode"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
 )
 
+// directivePrefix marks a header comment that configures how a migration
+// is handled, e.g. "-- scylla-migrate:depends-on 003".
+const directivePrefix = "-- scylla-migrate:"
+
+// StreamDirective marks a migration that may exceed MaxFileSize. Its
+// statements are read from disk while executing instead of being loaded up
+// front.
+const StreamDirective = "stream"
+
+// ParseOptions controls how migration files are read. Scanned migrations
+// carry the options they were scanned with, so different configurations can
+// be used side by side in one process.
+type ParseOptions struct {
+	// MaxFileSize is the largest migration file, in bytes, that is read into
+	// memory (max_migration_file_size). Zero means no limit.
+	MaxFileSize int64
+	// StatementSeparator ends a statement (statement_separator). It is only
+	// recognized outside quotes and comments; an alphabetic separator such
+	// as GO must also stand alone on its line and matches
+	// case-insensitively. Empty means ";".
+	StatementSeparator string
+	// ChecksumNormalization is applied to the content before hashing
+	// (checksum_normalization). Changing it changes checksums; recorded ones
+	// are brought in line with 'repair --normalize-checksums'.
+	ChecksumNormalization config.ChecksumNormalization
+}
+
+// DefaultParseOptions returns the options matching the configuration
+// defaults.
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{
+		StatementSeparator:    ";",
+		ChecksumNormalization: config.DefaultChecksumNormalization(),
+	}
+}
+
+// ParseOptionsFor returns the parse options configured in cfg.
+func ParseOptionsFor(cfg *config.Config) ParseOptions {
+	return ParseOptions{
+		MaxFileSize:           cfg.MaxMigrationFileSize,
+		StatementSeparator:    cfg.StatementSeparator,
+		ChecksumNormalization: cfg.ChecksumNormalization,
+	}
+}
+
+// parseOptions returns the options mig was scanned with, or the defaults
+// for a Migration built by hand.
+func (m *Migration) parseOptions() ParseOptions {
+	if m.options == nil {
+		return DefaultParseOptions()
+	}
+	return *m.options
+}
+
+// ParseMigrationFile reads and parses the file at mig.FilePath, plus its
+// companion args file if present, with the options mig was scanned with. It
+// is a thin wrapper around parseContent for callers that work with the
+// filesystem.
 func ParseMigrationFile(mig *Migration) error {
+	if err := readMigrationFile(mig); err != nil {
+		return err
+	}
+	return loadBindArgs(mig)
+}
+
+func readMigrationFile(mig *Migration) error {
+	if max := mig.parseOptions().MaxFileSize; max > 0 {
+		info, err := os.Stat(mig.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to stat migration file %s: %w", mig.FilePath, err)
+		}
+		if info.Size() > max {
+			return parseLargeFile(mig, info.Size())
+		}
+	}
+
 	content, err := os.ReadFile(mig.FilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read migration file %s: %w", mig.FilePath, err)
 	}
 
-	raw := string(content)
+	return parseContent(mig, string(content))
+}
+
+// Parse builds a fully populated Migration from a filename and its content
+// without touching the filesystem, using the default parse options.
+// FilePath is left empty.
+func Parse(name, content string) (*Migration, error) {
+	return ParseWith(name, content, DefaultParseOptions())
+}
+
+// ParseWith is Parse with explicit parse options.
+func ParseWith(name, content string, opts ParseOptions) (*Migration, error) {
+	mig, err := parseMigrationFilename(name, "")
+	if err != nil {
+		return nil, err
+	}
+	mig.options = &opts
+
+	if err := parseContent(mig, content); err != nil {
+		return nil, err
+	}
+
+	return mig, nil
+}
 
+func parseContent(mig *Migration, raw string) error {
 	// Strip UTF-8 BOM if present
 	raw = strings.TrimPrefix(raw, "\xef\xbb\xbf")
 
 	mig.RawContent = raw
 
-	// Normalize line endings
-	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	opts := mig.parseOptions()
 
 	// Calculate checksum
-	checksum, err := CalculateChecksumFromContent([]byte(raw))
+	checksum, err := checksumWith(strings.NewReader(raw), opts.ChecksumNormalization)
 	if err != nil {
 		return fmt.Errorf("failed to calculate checksum: %w", err)
 	}
 	mig.Checksum = checksum
 
+	// Normalize line endings
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+
+	mig.Directives = parseDirectives(raw)
+
 	// Split into statements
-	statements, err := splitStatements(raw)
+	statements, err := splitStatements(raw, opts.StatementSeparator)
 	if err != nil {
 		return fmt.Errorf("failed to parse CQL statements in %s: %w", mig.Filename, err)
 	}
@@ -39,19 +147,167 @@ func ParseMigrationFile(mig *Migration) error {
 	return nil
 }
 
-func splitStatements(content string) ([]string, error) {
+// parseLargeFile handles a file over MaxFileSize. Unless its header opts in
+// to streaming it is rejected; otherwise the checksum and directives are
+// computed in a single streaming pass and Statements is left empty.
+func parseLargeFile(mig *Migration, size int64) error {
+	f, err := os.Open(mig.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file %s: %w", mig.FilePath, err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	header, err := readHeader(br)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file %s: %w", mig.FilePath, err)
+	}
+
+	directives := parseDirectives(strings.TrimPrefix(header, "\xef\xbb\xbf"))
+	if _, ok := directives[StreamDirective]; !ok {
+		return fmt.Errorf("migration file %s is %d bytes, larger than max_migration_file_size (%d bytes) — split it into smaller migrations, or add %q to its header to stream it",
+			mig.Filename, size, mig.parseOptions().MaxFileSize, directivePrefix+StreamDirective)
+	}
+
+	// Checksum the same bytes parseContent would: BOM stripped, normalized
+	body := io.MultiReader(strings.NewReader(strings.TrimPrefix(header, "\xef\xbb\xbf")), br)
+	checksum, err := checksumWith(body, mig.parseOptions().ChecksumNormalization)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file %s: %w", mig.FilePath, err)
+	}
+
+	mig.Checksum = checksum
+	mig.Directives = directives
+	mig.Streamed = true
+	mig.Statements = nil
+	mig.RawContent = ""
+	return nil
+}
+
+// readHeader consumes lines up to and including the first one that is
+// neither blank nor a line comment, so the result covers every directive.
+func readHeader(br *bufio.Reader) (string, error) {
+	var header strings.Builder
+	for {
+		text, err := br.ReadString('\n')
+		header.WriteString(text)
+		if err == io.EOF {
+			return header.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		line := strings.TrimSpace(strings.TrimPrefix(text, "\xef\xbb\xbf"))
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return header.String(), nil
+		}
+	}
+}
+
+// parseDirectives collects "-- scylla-migrate:<name> <value>" lines from the
+// comment header at the top of a migration. Parsing stops at the first line
+// that is neither blank nor a line comment.
+func parseDirectives(content string) map[string]string {
+	directives := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		if !strings.HasPrefix(line, directivePrefix) {
+			continue
+		}
+
+		name, value, _ := strings.Cut(strings.TrimPrefix(line, directivePrefix), " ")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			directives[name] = strings.TrimSpace(value)
+		}
+	}
+	return directives
+}
+
+func splitStatements(content, sep string) ([]string, error) {
 	var statements []string
+	err := streamStatements(strings.NewReader(content), sep, func(stmt string) error {
+		statements = append(statements, stmt)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+// StreamStatements splits CQL read from r into statements and calls fn for
+// each one as soon as it is complete, so only the current statement is held
+// in memory. A leading BOM is skipped and CRLF line endings are normalized.
+// Returning an error from fn stops the split and returns that error.
+// Statements end with ";".
+func StreamStatements(r io.Reader, fn func(stmt string) error) error {
+	return streamStatements(r, ";", fn)
+}
+
+// streamStatements is StreamStatements with the separator sep; "" means ";".
+func streamStatements(r io.Reader, sep string, fn func(stmt string) error) error {
+	br := bufio.NewReader(r)
 	var current strings.Builder
+	if sep == "" {
+		sep = ";"
+	}
+	wordSep := isWordSeparator(sep)
 	inSingleQuote := false
 	inDoubleQuote := false
 	inLineComment := false
 	inBlockComment := false
 
-	runes := []rune(content)
-	length := len(runes)
+	// peek returns the next rune without consuming it
+	peek := func() (rune, bool) {
+		next, _, err := br.ReadRune()
+		if err != nil {
+			return 0, false
+		}
+		_ = br.UnreadRune()
+		return next, true
+	}
+	// skip consumes the rune returned by the last peek
+	skip := func() { _, _, _ = br.ReadRune() }
 
-	for i := 0; i < length; i++ {
-		ch := runes[i]
+	emit := func() error {
+		stmt := strings.TrimSpace(current.String())
+		current.Reset()
+		if stmt == "" {
+			return nil
+		}
+		return fn(stmt)
+	}
+
+	first := true
+	for {
+		ch, _, err := br.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CQL: %w", err)
+		}
+		if first {
+			first = false
+			if ch == '\uFEFF' {
+				continue
+			}
+		}
+
+		// Normalize CRLF to LF
+		if ch == '\r' {
+			if next, ok := peek(); ok && next == '\n' {
+				continue
+			}
+		}
 
 		// Handle line comments
 		if inLineComment {
@@ -64,34 +320,34 @@ func splitStatements(content string) ([]string, error) {
 
 		// Handle block comments
 		if inBlockComment {
-			if ch == '*' && i+1 < length && runes[i+1] == '/' {
+			if next, ok := peek(); ch == '*' && ok && next == '/' {
 				inBlockComment = false
-				i++ // skip '/'
+				skip() // skip '/'
 			}
 			continue
 		}
 
 		// Detect line comment start (--)
-		if !inSingleQuote && !inDoubleQuote && ch == '-' && i+1 < length && runes[i+1] == '-' {
+		if next, ok := peek(); !inSingleQuote && !inDoubleQuote && ch == '-' && ok && next == '-' {
 			inLineComment = true
-			i++ // skip second '-'
+			skip() // skip second '-'
 			continue
 		}
 
 		// Detect block comment start (/*)
-		if !inSingleQuote && !inDoubleQuote && ch == '/' && i+1 < length && runes[i+1] == '*' {
+		if next, ok := peek(); !inSingleQuote && !inDoubleQuote && ch == '/' && ok && next == '*' {
 			inBlockComment = true
-			i++ // skip '*'
+			skip() // skip '*'
 			continue
 		}
 
 		// Handle string literals
 		if !inDoubleQuote && ch == '\'' {
 			// Check for escaped quote ('')
-			if inSingleQuote && i+1 < length && runes[i+1] == '\'' {
+			if next, ok := peek(); inSingleQuote && ok && next == '\'' {
 				current.WriteRune(ch)
-				current.WriteRune(runes[i+1])
-				i++
+				current.WriteRune(next)
+				skip()
 				continue
 			}
 			inSingleQuote = !inSingleQuote
@@ -105,37 +361,62 @@ func splitStatements(content string) ([]string, error) {
 			continue
 		}
 
+		current.WriteRune(ch)
+
 		// Statement separator
-		if !inSingleQuote && !inDoubleQuote && ch == ';' {
-			stmt := strings.TrimSpace(current.String())
-			if stmt != "" {
-				statements = append(statements, stmt)
-			}
+		if !inSingleQuote && !inDoubleQuote && endsWithSeparator(current.String(), sep, wordSep, peek) {
+			stmt := current.String()
 			current.Reset()
-			continue
+			current.WriteString(stmt[:len(stmt)-len(sep)])
+			if err := emit(); err != nil {
+				return err
+			}
 		}
-
-		current.WriteRune(ch)
 	}
 
 	// Check for unterminated quotes
 	if inSingleQuote {
-		return nil, fmt.Errorf("unterminated single quote in CQL")
+		return fmt.Errorf("unterminated single quote in CQL")
 	}
 	if inDoubleQuote {
-		return nil, fmt.Errorf("unterminated double quote in CQL")
+		return fmt.Errorf("unterminated double quote in CQL")
 	}
 	if inBlockComment {
-		return nil, fmt.Errorf("unterminated block comment in CQL")
+		return fmt.Errorf("unterminated block comment in CQL")
 	}
 
 	// Handle last statement without trailing semicolon
-	stmt := strings.TrimSpace(current.String())
-	if stmt != "" {
-		statements = append(statements, stmt)
+	return emit()
+}
+
+// endsWithSeparator reports whether the statement text read so far ends with
+// the separator. A word separator must be alone on its line.
+func endsWithSeparator(s, sep string, word bool, peek func() (rune, bool)) bool {
+	if len(s) < len(sep) {
+		return false
+	}
+	tail := s[len(s)-len(sep):]
+	if !word {
+		return tail == sep
+	}
+	if !strings.EqualFold(tail, sep) {
+		return false
+	}
+	before := strings.TrimRight(s[:len(s)-len(sep)], " \t")
+	if before != "" && !strings.HasSuffix(before, "\n") {
+		return false
 	}
+	next, ok := peek()
+	return !ok || next == '\n' || next == '\r'
+}
 
-	return statements, nil
+func isWordSeparator(sep string) bool {
+	for _, r := range sep {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
 }
 
 func IsDDL(statement string) bool {
@@ -144,3 +425,79 @@ func IsDDL(statement string) bool {
 		strings.HasPrefix(upper, "ALTER") ||
 		strings.HasPrefix(upper, "DROP")
 }
+
+// IsDestructive reports whether a statement drops a schema object or
+// truncates table data.
+func IsDestructive(statement string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(statement))
+	return strings.HasPrefix(upper, "DROP") ||
+		strings.HasPrefix(upper, "TRUNCATE")
+}
+
+// DestructiveStatement is a DROP or TRUNCATE found in a migration.
+type DestructiveStatement struct {
+	Migration *Migration
+	Index     int
+	Statement string
+	Object    string
+}
+
+// FindDestructiveStatements returns every DROP/TRUNCATE statement in the
+// given (already parsed) migrations, in execution order.
+func FindDestructiveStatements(migrations []*Migration) []DestructiveStatement {
+	var found []DestructiveStatement
+	for _, mig := range migrations {
+		err := mig.EachStatement(func(i int, stmt string) error {
+			if IsDestructive(stmt) {
+				found = append(found, DestructiveStatement{
+					Migration: mig,
+					Index:     i,
+					Statement: stmt,
+					Object:    destructiveObject(stmt),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			// A streamed file we can no longer read will fail loudly at
+			// execution; flag it here rather than pretend it is safe
+			found = append(found, DestructiveStatement{
+				Migration: mig,
+				Index:     -1,
+				Statement: err.Error(),
+				Object:    "unreadable streamed migration " + mig.Filename,
+			})
+		}
+	}
+	return found
+}
+
+// destructiveObject names the object affected by a DROP/TRUNCATE statement,
+// e.g. "TABLE app.users" for "DROP TABLE IF EXISTS app.users".
+func destructiveObject(statement string) string {
+	fields := strings.Fields(statement)
+	if len(fields) < 2 {
+		return statement
+	}
+
+	rest := fields[1:]
+	kind := "TABLE"
+	if strings.EqualFold(fields[0], "DROP") || strings.EqualFold(rest[0], "TABLE") {
+		kind = strings.ToUpper(rest[0])
+		rest = rest[1:]
+		// Multi-word object kinds: MATERIALIZED VIEW
+		if kind == "MATERIALIZED" && len(rest) > 0 {
+			kind += " " + strings.ToUpper(rest[0])
+			rest = rest[1:]
+		}
+	}
+
+	if len(rest) >= 2 && strings.EqualFold(rest[0], "IF") && strings.EqualFold(rest[1], "EXISTS") {
+		rest = rest[2:]
+	}
+	if len(rest) == 0 {
+		return kind
+	}
+
+	return kind + " " + rest[0]
+}
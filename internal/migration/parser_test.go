@@ -3,6 +3,7 @@ package migration
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,7 +89,7 @@ func TestSplitStatements(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := splitStatements(tt.input)
+			got, err := splitStatements(tt.input, ";")
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -124,6 +125,47 @@ CREATE INDEX ON users (name);
 	assert.NotEmpty(t, mig.Checksum)
 }
 
+func TestParse_InMemory(t *testing.T) {
+	content := "\xef\xbb\xbf-- Migration: create users\r\n" +
+		"-- scylla-migrate:depends-on 001, 002\r\n" +
+		"-- scylla-migrate:Defer-Agreement\r\n" +
+		"CREATE TABLE users (id UUID PRIMARY KEY);\r\n" +
+		"-- scylla-migrate:ignored not-in-header\r\n" +
+		"CREATE INDEX ON users (name);\r\n"
+
+	mig, err := Parse("V003__create_users.cql", content)
+	require.NoError(t, err)
+
+	assert.Equal(t, "003", mig.Version)
+	assert.Equal(t, TypeVersioned, mig.Type)
+	assert.Equal(t, "create users", mig.Description)
+	assert.Empty(t, mig.FilePath)
+	assert.Len(t, mig.Statements, 2)
+	assert.Equal(t, map[string]string{
+		"depends-on":      "001, 002",
+		"defer-agreement": "",
+	}, mig.Directives)
+
+	// Checksum matches the file-based path for identical content
+	dir := t.TempDir()
+	path := filepath.Join(dir, "V003__create_users.cql")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	fromFile := &Migration{Filename: "V003__create_users.cql", FilePath: path}
+	require.NoError(t, ParseMigrationFile(fromFile))
+	assert.Equal(t, fromFile.Checksum, mig.Checksum)
+	assert.Equal(t, fromFile.Statements, mig.Statements)
+}
+
+func TestParse_InvalidName(t *testing.T) {
+	_, err := Parse("readme.txt", "CREATE TABLE foo (id UUID PRIMARY KEY);")
+	assert.Error(t, err)
+}
+
+func TestParse_InvalidContent(t *testing.T) {
+	_, err := Parse("V001__broken.cql", "INSERT INTO foo VALUES ('unterminated);")
+	assert.Error(t, err)
+}
+
 func TestIsDDL(t *testing.T) {
 	assert.True(t, IsDDL("CREATE TABLE foo (id UUID PRIMARY KEY)"))
 	assert.True(t, IsDDL("ALTER TABLE foo ADD name TEXT"))
@@ -133,3 +175,135 @@ func TestIsDDL(t *testing.T) {
 	assert.False(t, IsDDL("SELECT * FROM foo"))
 	assert.False(t, IsDDL("UPDATE foo SET name = 'test'"))
 }
+
+func TestIsDestructive(t *testing.T) {
+	assert.True(t, IsDestructive("DROP TABLE foo"))
+	assert.True(t, IsDestructive("  truncate foo"))
+	assert.False(t, IsDestructive("CREATE TABLE foo (id UUID PRIMARY KEY)"))
+	assert.False(t, IsDestructive("INSERT INTO foo (id) VALUES (1)"))
+}
+
+func TestFindDestructiveStatements(t *testing.T) {
+	migrations := []*Migration{
+		{Version: "001", Statements: []string{
+			"CREATE TABLE app.users (id UUID PRIMARY KEY)",
+			"DROP TABLE IF EXISTS app.legacy_users",
+		}},
+		{Version: "002", Statements: []string{
+			"TRUNCATE app.sessions",
+			"TRUNCATE TABLE app.tokens",
+			"DROP MATERIALIZED VIEW app.users_by_email",
+			"DROP INDEX app.users_email_idx",
+		}},
+	}
+
+	found := FindDestructiveStatements(migrations)
+	require.Len(t, found, 5)
+	assert.Equal(t, "001", found[0].Migration.Version)
+	assert.Equal(t, 1, found[0].Index)
+	assert.Equal(t, "TABLE app.legacy_users", found[0].Object)
+	assert.Equal(t, "TABLE app.sessions", found[1].Object)
+	assert.Equal(t, "TABLE app.tokens", found[2].Object)
+	assert.Equal(t, "MATERIALIZED VIEW app.users_by_email", found[3].Object)
+	assert.Equal(t, "INDEX app.users_email_idx", found[4].Object)
+}
+
+func TestStreamStatements(t *testing.T) {
+	input := "\xef\xbb\xbfCREATE TABLE a (id int PRIMARY KEY);\r\n-- comment; not a split\r\nINSERT INTO a (id) VALUES (1) /* ; */;\r\nSELECT 'x;y' FROM a"
+
+	var got []string
+	err := StreamStatements(strings.NewReader(input), func(stmt string) error {
+		got = append(got, stmt)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE TABLE a (id int PRIMARY KEY)",
+		"INSERT INTO a (id) VALUES (1)",
+		"SELECT 'x;y' FROM a",
+	}, got)
+}
+
+func TestStreamStatements_CustomSeparator(t *testing.T) {
+	split := func(sep, input string) []string {
+		var got []string
+		err := streamStatements(strings.NewReader(input), sep, func(stmt string) error {
+			got = append(got, stmt)
+			return nil
+		})
+		require.NoError(t, err)
+		return got
+	}
+
+	assert.Equal(t, []string{
+		"INSERT INTO a (id, note) VALUES (1, 'one; two;; three')",
+		"UPDATE a SET note = ';' WHERE id = 1",
+		"SELECT * FROM a",
+	}, split(";;", "INSERT INTO a (id, note) VALUES (1, 'one; two;; three');;\n"+
+		"-- ;; in a comment\nUPDATE a SET note = ';' WHERE id = 1 /* ;; */;;\n"+
+		"SELECT * FROM a"))
+
+	assert.Equal(t, []string{
+		"CREATE TABLE a (id int PRIMARY KEY, category text)",
+		"INSERT INTO a (id, category) VALUES (1, 'go\nGO\n')",
+	}, split("GO", "CREATE TABLE a (id int PRIMARY KEY, category text)\ngo\n"+
+		"INSERT INTO a (id, category) VALUES (1, 'go\nGO\n')\n  GO\n"))
+}
+
+func TestParseMigrationFile_MaxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	content := "-- Big data load\r\nINSERT INTO t (id) VALUES (1);\r\nDROP TABLE old;\r\n"
+	createTestMigration(t, dir, "V001__big.cql", content)
+	createTestMigration(t, dir, "V002__big_streamed.cql", "-- scylla-migrate:stream\r\n"+content)
+
+	opts := DefaultParseOptions()
+	opts.MaxFileSize = 16
+	scanned, err := ScanMigrationsDirs([]string{dir}, opts)
+	require.NoError(t, err)
+	require.Len(t, scanned, 2)
+
+	err = ParseMigrationFile(scanned[0])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_migration_file_size")
+
+	streamed := scanned[1]
+	require.NoError(t, ParseMigrationFile(streamed))
+	assert.True(t, streamed.Streamed)
+	assert.Empty(t, streamed.Statements)
+
+	// Streaming must produce the same checksum and statements as a full read
+	full := *streamed
+	full.Streamed = false
+	full.options = nil
+	require.NoError(t, ParseMigrationFile(&full))
+	assert.Equal(t, full.Checksum, streamed.Checksum)
+
+	var stmts []string
+	require.NoError(t, streamed.EachStatement(func(_ int, stmt string) error {
+		stmts = append(stmts, stmt)
+		return nil
+	}))
+	assert.Equal(t, full.Statements, stmts)
+
+	found := FindDestructiveStatements([]*Migration{streamed})
+	require.Len(t, found, 1)
+	assert.Equal(t, 1, found[0].Index)
+}
+
+func TestScanMigrationsDirs_OptionsPerScan(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__two.cql", "INSERT INTO a (id, s) VALUES (1, 'a;b')\nGO\nINSERT INTO a (id) VALUES (2)\nGO\n")
+
+	custom := DefaultParseOptions()
+	custom.StatementSeparator = "GO"
+	withCustom, err := ScanMigrationsDirs([]string{dir}, custom)
+	require.NoError(t, err)
+	withDefault, err := ScanMigrationsDirs([]string{dir}, DefaultParseOptions())
+	require.NoError(t, err)
+
+	// Both scans are parsed with their own options in the same process
+	require.NoError(t, ParseMigrationFile(withCustom[0]))
+	require.NoError(t, ParseMigrationFile(withDefault[0]))
+	assert.Len(t, withCustom[0].Statements, 2)
+	assert.Len(t, withDefault[0].Statements, 1)
+}
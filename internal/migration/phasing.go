@@ -0,0 +1,271 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+// PhaseDirective puts every statement of a migration in one phase for
+// cross_migration_phasing, e.g. "-- scylla-migrate:phase dml". Without it
+// each statement's phase is its StatementType.
+const PhaseDirective = "phase"
+
+// Phase returns the phase declared by the directive, or "" when none is.
+func (m *Migration) Phase() (string, error) {
+	value, ok := m.Directives[PhaseDirective]
+	if !ok {
+		return "", nil
+	}
+	switch phase := strings.ToLower(value); phase {
+	case StatementTypeDDL, StatementTypeDML:
+		return phase, nil
+	}
+	return "", fmt.Errorf("%s: invalid %s directive %q (use ddl or dml)", m.Filename, PhaseDirective, value)
+}
+
+// phasedMigration tracks one migration across both phases.
+type phasedMigration struct {
+	mig      *Migration
+	rec      schema.MigrationRecord
+	phase    string
+	timeout  time.Duration
+	total    int
+	ran      int
+	started  bool
+	recorded bool
+	elapsed  time.Duration
+}
+
+func newPhasedMigration(mig *Migration, defaultTimeout time.Duration) (*phasedMigration, error) {
+	phase, err := mig.Phase()
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := mig.StatementTimeout(defaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	// Phases interleave the statements of several migrations, which leaves
+	// no run of one migration's statements to spread over workers
+	if workers, err := mig.Concurrency(); err != nil {
+		return nil, err
+	} else if workers > 1 {
+		return nil, fmt.Errorf("%s: the %s directive is not supported with cross_migration_phasing", mig.Filename, ConcurrencyDirective)
+	}
+	st := &phasedMigration{mig: mig, rec: toRecord(mig), phase: phase, timeout: timeout}
+	if err := mig.EachStatement(func(int, string) error { st.total++; return nil }); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (st *phasedMigration) phaseOf(stmt string) string {
+	if st.phase != "" {
+		return st.phase
+	}
+	return StatementType(stmt)
+}
+
+// runPhased implements cross_migration_phasing: the DDL statements of all
+// migrations run first, in migration order, then their DML statements. Each
+// migration is recorded once its DML phase is done. If the run stops early,
+// migrations whose statements all ran are recorded as applied, the failing
+// one and any that ran only part of their statements as failed, and the rest
+// stay pending.
+func (e *Executor) runPhased(migrations []*Migration) *RunResult {
+	start := time.Now()
+	result := &RunResult{Total: len(migrations)}
+	e.warnings = nil
+	defer func() {
+		e.finishAgreement(result)
+		result.Duration = time.Since(start)
+		result.Warnings = e.warnings
+	}()
+
+	// Each migration is visited twice, so approvals are collected up front
+	var states []*phasedMigration
+	for _, mig := range migrations {
+		if e.BeforeEach != nil && !e.ctx.DryRun {
+			proceed, err := e.BeforeEach(mig)
+			if err != nil {
+				result.Err = err
+				return result
+			}
+			if !proceed {
+				e.ctx.Logger.Warn().
+					Str("version", mig.Version).
+					Str("description", mig.Description).
+					Msg("Migration skipped")
+				result.Skipped++
+				continue
+			}
+		}
+		st, err := newPhasedMigration(mig, e.ctx.Config.StatementTimeout)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		if mig.Type == TypeRepeatable && !e.ctx.DryRun {
+			if err := e.claimRepeatable(mig, st.rec); errors.Is(err, errRepeatableTaken) {
+				result.Skipped++
+				continue
+			} else if err != nil {
+				result.Err = err
+				e.finishPhased(states, nil, err, result)
+				return result
+			}
+		}
+		states = append(states, st)
+	}
+
+	for _, phase := range []string{StatementTypeDDL, StatementTypeDML} {
+		if phase == StatementTypeDML {
+			// Nothing is recorded before the DDL phase's deferred agreement
+			if err := e.flushAgreement(); err != nil {
+				result.Err = err
+				e.finishPhased(states, nil, err, result)
+				return result
+			}
+		}
+		e.ctx.Logger.Info().Str("phase", phase).Int("migrations", len(states)).Msg("Starting phase (cross_migration_phasing)")
+		for _, st := range states {
+			if err := e.runPhase(st, phase); err != nil {
+				result.Err = err
+				e.finishPhased(states, st, err, result)
+				return result
+			}
+			if phase == StatementTypeDML {
+				if err := e.recordPhased(st, result); err != nil {
+					result.Err = err
+					e.finishPhased(states, nil, err, result)
+					return result
+				}
+			}
+		}
+	}
+	return result
+}
+
+// runPhase executes the statements of st that belong to phase.
+func (e *Executor) runPhase(st *phasedMigration, phase string) error {
+	mig := st.mig
+	return mig.EachStatement(func(i int, stmt string) error {
+		if st.phaseOf(stmt) != phase {
+			return nil
+		}
+		if e.ctx.DryRun {
+			e.ctx.Logger.Info().
+				Str("version", mig.Version).
+				Str("phase", phase).
+				Int("statement", i+1).
+				Str("cql", truncateStr(ApplyUsingTimeout(stmt, st.timeout), 120)).
+				Msg("[DRY RUN] Would execute")
+			st.ran++
+			return nil
+		}
+
+		if !st.started {
+			st.started = true
+			e.ctx.Logger.Info().
+				Str("version", mig.Version).
+				Str("description", mig.Description).
+				Int("statements", st.total).
+				Msg("Applying migration")
+			e.ctx.RecordEvent(schema.EventMigrationStarted, st.rec.Version, mig.Description, "")
+		}
+
+		stmtStart := time.Now()
+		err := e.executeStatement(context.Background(), mig, i, stmt, st.timeout)
+		st.elapsed += time.Since(stmtStart)
+		if err != nil {
+			return err
+		}
+		st.ran++
+		return nil
+	})
+}
+
+// recordPhased records st as successfully applied.
+func (e *Executor) recordPhased(st *phasedMigration, result *RunResult) error {
+	st.recorded = true
+	mig := st.mig
+	if !e.ctx.DryRun {
+		if mig.Type == TypeRepeatable {
+			defer e.completeRepeatable(mig, st.rec, st.elapsed, true)
+		}
+		if err := e.ctx.store.RecordMigration(st.rec, st.elapsed, true, e.ctx.hostname); err != nil {
+			var recorded *schema.AlreadyRecordedError
+			if !errors.As(err, &recorded) {
+				return fmt.Errorf("migration %s executed successfully but failed to record metadata: %w", mig.Filename, err)
+			}
+			e.warnings = append(e.warnings, fmt.Sprintf("V%s: %s", mig.Version, recorded.Error()))
+			e.ctx.Logger.Warn().
+				Str("version", mig.Version).
+				Str("applied_by", recorded.AppliedBy).
+				Time("applied_at", recorded.AppliedAt).
+				Msg("Migration was already recorded as applied — it may have been applied twice; existing record kept (safe_record)")
+		}
+		e.ctx.RecordEvent(schema.EventMigrationApplied, st.rec.Version, mig.Description,
+			fmt.Sprintf("applied in %s (cross_migration_phasing)", st.elapsed.Round(time.Millisecond)))
+		e.ctx.Logger.Info().
+			Str("version", mig.Version).
+			Str("description", mig.Description).
+			Dur("duration", st.elapsed).
+			Msg("Migration applied successfully")
+	}
+
+	result.Applied = append(result.Applied, MigrationResult{
+		Version:     mig.Version,
+		Description: mig.Description,
+		Type:        mig.Type,
+		Statements:  st.total,
+		Duration:    st.elapsed,
+	})
+	return nil
+}
+
+// finishPhased records the migrations not yet recorded when a phased run
+// stops: failed is the migration that failed (nil if the failure was not in
+// a statement).
+func (e *Executor) finishPhased(states []*phasedMigration, failed *phasedMigration, runErr error, result *RunResult) {
+	if e.ctx.DryRun {
+		return
+	}
+	for _, st := range states {
+		if st.recorded {
+			continue
+		}
+		// Every statement ran, but the schema may not have settled
+		unconfirmed := st.ran == st.total && st.mig.DefersAgreement() && errors.Is(runErr, errDeferredAgreement)
+		if st.mig.Type == TypeRepeatable && (st.ran == 0 || st.ran < st.total || unconfirmed) {
+			e.completeRepeatable(st.mig, st.rec, st.elapsed, false)
+		}
+		switch {
+		case st == failed, unconfirmed:
+			_ = e.ctx.store.RecordMigration(st.rec, st.elapsed, false, e.ctx.hostname)
+			e.ctx.RecordEvent(schema.EventMigrationFailed, st.rec.Version, st.mig.Description, runErr.Error())
+		case st.ran == 0:
+			// Untouched; stays pending
+		case st.ran == st.total:
+			// Every statement ran in the DDL phase
+			if err := e.recordPhased(st, result); err != nil {
+				e.ctx.Logger.Error().Err(err).Str("version", st.mig.Version).Msg("Failed to record migration")
+			}
+		default:
+			msg := fmt.Sprintf("partially applied: %d of %d statement(s) ran before the run stopped (cross_migration_phasing)", st.ran, st.total)
+			_ = e.ctx.store.RecordMigration(st.rec, st.elapsed, false, e.ctx.hostname)
+			e.ctx.RecordEvent(schema.EventMigrationFailed, st.rec.Version, st.mig.Description, msg)
+			e.warnings = append(e.warnings, fmt.Sprintf("V%s %s", st.mig.Version, msg))
+			e.ctx.Logger.Error().
+				Str("version", st.mig.Version).
+				Int("ran", st.ran).
+				Int("statements", st.total).
+				Msg("Migration partially applied and recorded as failed (cross_migration_phasing)")
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+func TestMigration_Phase(t *testing.T) {
+	mig, err := Parse("V001__backfill.cql", "-- scylla-migrate:phase DML\nCREATE TABLE t (id int PRIMARY KEY);")
+	require.NoError(t, err)
+	phase, err := mig.Phase()
+	require.NoError(t, err)
+	assert.Equal(t, StatementTypeDML, phase)
+
+	mig, err = Parse("V002__plain.cql", "CREATE TABLE t (id int PRIMARY KEY);")
+	require.NoError(t, err)
+	phase, err = mig.Phase()
+	require.NoError(t, err)
+	assert.Empty(t, phase)
+
+	mig, err = Parse("V003__bad.cql", "-- scylla-migrate:phase later\nSELECT 1 FROM t;")
+	require.NoError(t, err)
+	_, err = mig.Phase()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "V003__bad.cql")
+}
+
+func TestNewPhasedMigration(t *testing.T) {
+	mig, err := Parse("V001__users.cql", `CREATE TABLE users (id int PRIMARY KEY, tier text);
+INSERT INTO users (id, tier) VALUES (1, 'free');
+ALTER TABLE users ADD email text;`)
+	require.NoError(t, err)
+
+	st, err := newPhasedMigration(mig, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, st.total)
+
+	var phases []string
+	for _, stmt := range mig.Statements {
+		phases = append(phases, st.phaseOf(stmt))
+	}
+	assert.Equal(t, []string{"ddl", "dml", "ddl"}, phases)
+
+	// A declared phase applies to every statement
+	st.phase = StatementTypeDML
+	assert.Equal(t, StatementTypeDML, st.phaseOf(mig.Statements[0]))
+}
+
+func TestNewPhasedMigration_RejectsConcurrency(t *testing.T) {
+	mig, err := Parse("V001__users.cql", "-- scylla-migrate:concurrency 4\nINSERT INTO users (id) VALUES (1);")
+	require.NoError(t, err)
+	_, err = newPhasedMigration(mig, 0)
+	assert.ErrorContains(t, err, "not supported with cross_migration_phasing")
+}
+
+func parsePhased(t *testing.T, files ...string) []*Migration {
+	t.Helper()
+	var migs []*Migration
+	for i := 0; i < len(files); i += 2 {
+		mig, err := Parse(files[i], files[i+1])
+		require.NoError(t, err)
+		migs = append(migs, mig)
+	}
+	return migs
+}
+
+func TestRunPhased_DDLBeforeDML(t *testing.T) {
+	store, cluster := &fakeStore{}, &fakeCluster{}
+	migs := parsePhased(t,
+		"V001__users.cql", "CREATE TABLE users (id int PRIMARY KEY);\nINSERT INTO users (id) VALUES (1);",
+		"V002__orders.cql", "CREATE TABLE orders (id int PRIMARY KEY);\nINSERT INTO orders (id) VALUES (1);",
+	)
+
+	result := newTestExecutor(store, cluster).runPhased(migs)
+	require.NoError(t, result.Err)
+	assert.Equal(t, []string{
+		"CREATE TABLE users (id int PRIMARY KEY)",
+		"CREATE TABLE orders (id int PRIMARY KEY)",
+		"INSERT INTO users (id) VALUES (1)",
+		"INSERT INTO orders (id) VALUES (1)",
+	}, cluster.executed)
+	assert.Equal(t, []recordedMigration{{"001", true}, {"002", true}}, store.records)
+	assert.Equal(t, []string{"001", "002"}, result.AppliedVersions())
+}
+
+func TestRunPhased_FailureInDMLPhase(t *testing.T) {
+	store, cluster := &fakeStore{}, &fakeCluster{failOn: "INSERT INTO orders"}
+	migs := parsePhased(t,
+		"V001__users.cql", "CREATE TABLE users (id int PRIMARY KEY);\nINSERT INTO users (id) VALUES (1);",
+		"V002__orders.cql", "CREATE TABLE orders (id int PRIMARY KEY);\nINSERT INTO orders (id) VALUES (1);",
+		"V003__items.cql", "CREATE TABLE items (id int PRIMARY KEY);",
+		"V004__tags.cql", "CREATE TABLE tags (id int PRIMARY KEY);\nINSERT INTO tags (id) VALUES (1);",
+	)
+
+	e := newTestExecutor(store, cluster)
+	result := e.runPhased(migs)
+	require.Error(t, result.Err)
+
+	assert.Equal(t, []recordedMigration{
+		{"001", true},  // both phases done before the failure
+		{"002", false}, // the failing migration
+		{"003", true},  // only DDL, which all ran
+		{"004", false}, // DDL ran, DML did not: partially applied
+	}, store.records)
+	assert.Equal(t, []string{"001", "003"}, result.AppliedVersions())
+	assert.Contains(t, store.eventTypes("002"), schema.EventMigrationFailed)
+	assert.Contains(t, store.eventTypes("004"), schema.EventMigrationFailed)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "V004 partially applied: 1 of 2")
+}
+
+func TestRunPhased_FailureInDDLPhase(t *testing.T) {
+	store, cluster := &fakeStore{}, &fakeCluster{failOn: "CREATE TABLE users"}
+	migs := parsePhased(t,
+		"V001__users.cql", "CREATE TABLE users (id int PRIMARY KEY);\nINSERT INTO users (id) VALUES (1);",
+		"V002__orders.cql", "CREATE TABLE orders (id int PRIMARY KEY);",
+	)
+
+	result := newTestExecutor(store, cluster).runPhased(migs)
+	require.Error(t, result.Err)
+	assert.Empty(t, cluster.executed)
+	// V002 never started, so it stays pending
+	assert.Equal(t, []recordedMigration{{"001", false}}, store.records)
+	assert.Empty(t, result.Applied)
+}
+
+func TestRunPhased_RecordFailure(t *testing.T) {
+	store := &fakeStore{recordErrs: map[string]error{"001": errors.New("write timeout")}}
+	cluster := &fakeCluster{}
+	migs := parsePhased(t,
+		"V001__users.cql", "CREATE TABLE users (id int PRIMARY KEY);\nINSERT INTO users (id) VALUES (1);",
+		"V002__orders.cql", "CREATE TABLE orders (id int PRIMARY KEY);",
+		"V003__tags.cql", "CREATE TABLE tags (id int PRIMARY KEY);\nINSERT INTO tags (id) VALUES (1);",
+	)
+
+	result := newTestExecutor(store, cluster).runPhased(migs)
+	require.Error(t, result.Err)
+	assert.Contains(t, result.Err.Error(), "failed to record metadata")
+	// Not a statement failure: the fully run V002 is still recorded, the
+	// half-run V003 is recorded as failed
+	assert.Equal(t, []recordedMigration{{"002", true}, {"003", false}}, store.records)
+}
+
+func TestRunPhased_Repeatables(t *testing.T) {
+	store := &fakeStore{taken: map[string]bool{"R_grants": true}}
+	cluster := &fakeCluster{failOn: "INSERT INTO audit"}
+	migs := parsePhased(t,
+		"R__grants.cql", "CREATE TABLE grants (id int PRIMARY KEY);",
+		"R__views.cql", "CREATE TABLE views (id int PRIMARY KEY);",
+		"R__audit.cql", "CREATE TABLE audit (id int PRIMARY KEY);\nINSERT INTO audit (id) VALUES (1);",
+	)
+
+	result := newTestExecutor(store, cluster).runPhased(migs)
+	require.Error(t, result.Err)
+	assert.Equal(t, 1, result.Skipped, "claimed by another run")
+	assert.NotContains(t, cluster.executed, "CREATE TABLE grants (id int PRIMARY KEY)")
+
+	assert.Equal(t, []recordedMigration{{"R_views", true}, {"R_audit", false}}, store.records)
+	assert.ElementsMatch(t, []completedRepeatable{{"R_views", true}, {"R_audit", false}}, store.completed)
+}
+
+func TestRunPhased_DryRun(t *testing.T) {
+	store, cluster := &fakeStore{}, &fakeCluster{}
+	e := newTestExecutor(store, cluster)
+	e.ctx.DryRun = true
+
+	result := e.runPhased(parsePhased(t,
+		"V001__users.cql", "CREATE TABLE users (id int PRIMARY KEY);\nINSERT INTO users (id) VALUES (1);",
+	))
+	require.NoError(t, result.Err)
+	assert.Empty(t, cluster.executed)
+	assert.Empty(t, store.records)
+	assert.Equal(t, []string{"001"}, result.AppliedVersions())
+}
+
+func TestRunPhased_DeferredAgreementFailure(t *testing.T) {
+	store, cluster := &fakeStore{}, &fakeCluster{agreementErr: errors.New("timeout")}
+	migs := parsePhased(t,
+		"V001__a.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE a (id int PRIMARY KEY);",
+		"V002__b.cql", "-- scylla-migrate:defer-agreement\nCREATE TABLE b (id int PRIMARY KEY);\nINSERT INTO b (id) VALUES (1);",
+	)
+
+	result := newTestExecutor(store, cluster).runPhased(migs)
+	require.ErrorIs(t, result.Err, errDeferredAgreement)
+	// Nothing is recorded as applied before the deferred wait succeeds
+	assert.Equal(t, []recordedMigration{{"001", false}, {"002", false}}, store.records)
+	assert.Empty(t, result.Applied)
+	assert.NotContains(t, cluster.executed, "INSERT INTO b (id) VALUES (1)")
+}
@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+// PlanVersion is the version of the dry-run JSON plan schema. Bump it on any
+// change to field names, types or meaning; CI jobs diff plans across branches
+// and rely on the schema staying fixed within a version.
+const PlanVersion = 1
+
+type Direction string
+
+const (
+	DirectionForward  Direction = "forward"
+	DirectionRollback Direction = "rollback"
+)
+
+// Plan is the ordered list of migrations a run would execute. Only
+// PlanVersion and Migrations are serialized, as the machine-readable dry-run
+// output of migrate; their field order is part of the schema.
+type Plan struct {
+	PlanVersion int         `json:"plan_version"`
+	Migrations  []PlanEntry `json:"migrations"`
+
+	Direction Direction  `json:"-"`
+	Steps     []PlanStep `json:"-"`
+	Warnings  []string   `json:"-"`
+}
+
+// PlanStep is one migration in execution order. For a forward plan,
+// Migration is the pending migration and Undo its undo file, if any. For a
+// rollback plan, Migration is the undo file to run and Applied the record it
+// reverts.
+type PlanStep struct {
+	Migration *Migration
+	Undo      *Migration
+	Applied   *schema.AppliedMigration
+}
+
+// ExecutionOrder returns the migrations to execute, in order.
+func (p *Plan) ExecutionOrder() []*Migration {
+	migs := make([]*Migration, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		migs = append(migs, step.Migration)
+	}
+	return migs
+}
+
+type PlanEntry struct {
+	Version           string `json:"version"`
+	Type              string `json:"type"`
+	Description       string `json:"description"`
+	Checksum          string `json:"checksum"`
+	StatementCount    int    `json:"statement_count"`
+	DDLStatementCount int    `json:"ddl_statement_count"`
+}
+
+// NewPlan builds a plan from parsed pending migrations, in execution order.
+// Streamed migrations are read from disk to count their statements.
+func NewPlan(pending []*Migration) (Plan, error) {
+	plan := Plan{
+		PlanVersion: PlanVersion,
+		Migrations:  make([]PlanEntry, 0, len(pending)),
+		Direction:   DirectionForward,
+	}
+
+	for _, mig := range pending {
+		count, ddl := 0, 0
+		err := mig.EachStatement(func(_ int, stmt string) error {
+			count++
+			if IsDDL(stmt) {
+				ddl++
+			}
+			return nil
+		})
+		if err != nil {
+			return Plan{}, err
+		}
+		plan.Migrations = append(plan.Migrations, PlanEntry{
+			Version:           mig.Version,
+			Type:              string(mig.Type),
+			Description:       mig.Description,
+			Checksum:          mig.Checksum,
+			StatementCount:    count,
+			DDLStatementCount: ddl,
+		})
+		plan.Steps = append(plan.Steps, PlanStep{Migration: mig})
+	}
+
+	return plan, nil
+}
+
+func (p Plan) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
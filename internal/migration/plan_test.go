@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files")
+
+// TestPlan_Golden pins the dry-run JSON plan schema. If this fails because the
+// schema changed on purpose, bump PlanVersion and rerun with -update.
+func TestPlan_Golden(t *testing.T) {
+	scanned, err := ScanMigrationsDir("../../testdata/migrations")
+	require.NoError(t, err)
+
+	var pending []*Migration
+	for _, mig := range scanned {
+		if mig.Type == TypeUndo {
+			continue
+		}
+		require.NoError(t, ParseMigrationFile(mig))
+		pending = append(pending, mig)
+	}
+
+	plan, err := NewPlan(pending)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, plan.WriteJSON(&buf))
+
+	golden := filepath.Join("testdata", "plan_v1.golden.json")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0644))
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestPlan_EmptyHasMigrationsArray(t *testing.T) {
+	plan, err := NewPlan(nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, plan.WriteJSON(&buf))
+	assert.JSONEq(t, `{"plan_version": 1, "migrations": []}`, buf.String())
+}
@@ -0,0 +1,123 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+// BaselineVersion returns the highest version covered by a baseline record
+// left by metadata prune, or "" when there is none.
+func BaselineVersion(applied []schema.AppliedMigration) string {
+	baseline := ""
+	for _, a := range applied {
+		if a.Success && a.Type == schema.RecordTypeBaseline && (baseline == "" || CompareVersions(a.Version, baseline) > 0) {
+			baseline = a.Version
+		}
+	}
+	return baseline
+}
+
+// coveredByBaseline reports whether version counts as applied because its
+// record was pruned into baseline.
+func coveredByBaseline(version, baseline string) bool {
+	return baseline != "" && CompareVersions(version, baseline) <= 0
+}
+
+// PruneOptions selects the records metadata prune may remove. When both are
+// set a record must satisfy both.
+type PruneOptions struct {
+	// KeepLast keeps the newest KeepLast versioned records (0 = no limit)
+	KeepLast int
+	// Before only prunes records applied before this time (zero = any)
+	Before time.Time
+}
+
+// PrunePlan is what metadata prune will do: Remove are deleted and the
+// record of Baseline is replaced by a baseline record covering all of them.
+type PrunePlan struct {
+	Baseline string
+	Remove   []schema.AppliedMigration
+	// StoppedAt explains why pruning stopped before a record the options
+	// allowed, e.g. an unapplied migration the baseline would hide
+	StoppedAt string
+}
+
+// Folded returns the number of records the baseline will stand for.
+func (p *PrunePlan) Folded() int {
+	if p.Baseline == "" {
+		return 0
+	}
+	return len(p.Remove) + 1
+}
+
+// PlanPrune decides which successful versioned records can be folded into a
+// baseline. Records are taken oldest first and pruning stops at the first
+// record the options keep, and before any migration file that has no
+// successful record: a baseline above it would make it look applied.
+// Failed and repeatable records are never pruned.
+func PlanPrune(applied []schema.AppliedMigration, scanned []*Migration, opts PruneOptions) (*PrunePlan, error) {
+	if opts.KeepLast < 0 {
+		return nil, fmt.Errorf("--keep-last must not be negative")
+	}
+	if opts.KeepLast == 0 && opts.Before.IsZero() {
+		return nil, fmt.Errorf("specify --keep-last or --older-than")
+	}
+
+	var records []schema.AppliedMigration
+	recorded := make(map[string]bool)
+	for _, a := range applied {
+		if a.Success && (a.Type == string(TypeVersioned) || a.Type == schema.RecordTypeBaseline) {
+			records = append(records, a)
+			recorded[a.Version] = true
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return CompareVersions(records[i].Version, records[j].Version) < 0
+	})
+
+	baseline := BaselineVersion(applied)
+	var unapplied []string
+	for _, mig := range scanned {
+		if mig.Type == TypeVersioned && !recorded[mig.Version] && !coveredByBaseline(mig.Version, baseline) {
+			unapplied = append(unapplied, mig.Version)
+		}
+	}
+
+	plan := &PrunePlan{}
+	var folded []schema.AppliedMigration
+	for i, a := range records {
+		if opts.KeepLast > 0 && i >= len(records)-opts.KeepLast {
+			break
+		}
+		if !opts.Before.IsZero() && !a.AppliedAt.Before(opts.Before) {
+			break
+		}
+		if gap := firstBelow(unapplied, a.Version); gap != "" {
+			plan.StoppedAt = fmt.Sprintf("V%s has no successful record; a baseline at V%s would hide it", gap, a.Version)
+			break
+		}
+		folded = append(folded, a)
+	}
+
+	// A lone existing baseline is already as pruned as it gets
+	if len(folded) == 0 || (len(folded) == 1 && folded[0].Type == schema.RecordTypeBaseline) {
+		return plan, nil
+	}
+	plan.Baseline = folded[len(folded)-1].Version
+	plan.Remove = folded[:len(folded)-1]
+	return plan, nil
+}
+
+// firstBelow returns the lowest of versions that sorts before limit.
+func firstBelow(versions []string, limit string) string {
+	lowest := ""
+	for _, v := range versions {
+		if CompareVersions(v, limit) < 0 && (lowest == "" || CompareVersions(v, lowest) < 0) {
+			lowest = v
+		}
+	}
+	return lowest
+}
@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+func pruneRecords(versions ...string) []schema.AppliedMigration {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var applied []schema.AppliedMigration
+	for i, v := range versions {
+		applied = append(applied, schema.AppliedMigration{
+			Version: v, Type: "versioned", Success: true, AppliedAt: start.AddDate(0, i, 0),
+		})
+	}
+	return applied
+}
+
+func pruneFiles(versions ...string) []*Migration {
+	var migs []*Migration
+	for _, v := range versions {
+		migs = append(migs, &Migration{Version: v, Type: TypeVersioned})
+	}
+	return migs
+}
+
+func TestPlanPrune_KeepLast(t *testing.T) {
+	applied := pruneRecords("001", "002", "003", "004", "005")
+	plan, err := PlanPrune(applied, pruneFiles("001", "002", "003", "004", "005"), PruneOptions{KeepLast: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "003", plan.Baseline)
+	assert.Len(t, plan.Remove, 2)
+	assert.Equal(t, 3, plan.Folded())
+}
+
+func TestPlanPrune_OlderThanAndKeepLastBothApply(t *testing.T) {
+	applied := pruneRecords("001", "002", "003", "004", "005")
+	before := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+	plan, err := PlanPrune(applied, nil, PruneOptions{KeepLast: 1, Before: before})
+	require.NoError(t, err)
+	assert.Equal(t, "002", plan.Baseline)
+}
+
+func TestPlanPrune_StopsBeforeUnappliedMigration(t *testing.T) {
+	applied := pruneRecords("001", "002", "004", "005")
+	plan, err := PlanPrune(applied, pruneFiles("001", "002", "003", "004", "005"), PruneOptions{KeepLast: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "002", plan.Baseline)
+	assert.Contains(t, plan.StoppedAt, "V003")
+}
+
+func TestPlanPrune_ExtendsExistingBaseline(t *testing.T) {
+	applied := pruneRecords("003", "004", "005")
+	applied[0].Type = schema.RecordTypeBaseline
+
+	plan, err := PlanPrune(applied, pruneFiles("001", "002", "003", "004", "005"), PruneOptions{KeepLast: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "004", plan.Baseline)
+	require.Len(t, plan.Remove, 1)
+	assert.Equal(t, "003", plan.Remove[0].Version)
+
+	// nothing new to fold
+	plan, err = PlanPrune(applied, nil, PruneOptions{KeepLast: 2})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Baseline)
+}
+
+func TestPlanPrune_RequiresAWindow(t *testing.T) {
+	_, err := PlanPrune(nil, nil, PruneOptions{})
+	require.Error(t, err)
+}
+
+func TestGetPendingMigrations_BaselineCoversPrunedVersions(t *testing.T) {
+	applied := []schema.AppliedMigration{
+		{Version: "002", Type: schema.RecordTypeBaseline, Success: true},
+	}
+	migs, err := ScanMigrationsDir("../../testdata/migrations")
+	require.NoError(t, err)
+
+	pending, err := NewResolver(migs).GetPendingMigrations(applied)
+	require.NoError(t, err)
+	for _, mig := range pending {
+		assert.NotEqual(t, TypeVersioned, mig.Type, mig.Filename)
+	}
+}
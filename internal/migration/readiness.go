@@ -0,0 +1,78 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// WaitForReadiness runs readiness_query until the cluster reports it is
+// ready, retrying every interval until the timeout. It returns immediately
+// when no readiness query is configured.
+func WaitForReadiness(ctx *ExecutionContext) error {
+	rc := ctx.Config.ReadinessQuery
+	if rc.Query == "" {
+		return nil
+	}
+
+	deadline := time.Now().Add(rc.Timeout)
+	for attempt := 1; ; attempt++ {
+		ready, reason := checkReadiness(ctx, rc.Query, rc.Expect)
+		if ready {
+			ctx.Logger.Info().Int("attempt", attempt).Msg("Readiness query passed")
+			return nil
+		}
+		if time.Now().Add(rc.Interval).After(deadline) {
+			return fmt.Errorf("cluster not ready after %s: %s", rc.Timeout, reason)
+		}
+		ctx.Logger.Warn().Int("attempt", attempt).Str("reason", reason).Dur("retry_in", rc.Interval).
+			Msg("Readiness query not satisfied, waiting")
+		time.Sleep(rc.Interval)
+	}
+}
+
+func checkReadiness(ctx *ExecutionContext, query, expect string) (bool, string) {
+	iter := ctx.Session.Query(query).Iter()
+	row := make(map[string]interface{})
+	found := iter.MapScan(row)
+	columns := iter.Columns()
+	if err := iter.Close(); err != nil {
+		return false, fmt.Sprintf("query failed: %v", err)
+	}
+	if !found {
+		return false, "query returned no rows"
+	}
+	return readinessMet(columns, row, expect)
+}
+
+// readinessMet applies the result contract: any row means ready, unless
+// expect is set, in which case the first column of the first row must equal
+// it when formatted as text.
+func readinessMet(columns []gocql.ColumnInfo, row map[string]interface{}, expect string) (bool, string) {
+	if expect == "" {
+		return true, ""
+	}
+	if len(columns) == 0 {
+		return false, "query returned no columns"
+	}
+	got := formatReadinessValue(row[columns[0].Name])
+	if got != expect {
+		return false, fmt.Sprintf("%s is %q, want %q", columns[0].Name, got, expect)
+	}
+	return true, ""
+}
+
+func formatReadinessValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,31 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessMet(t *testing.T) {
+	columns := []gocql.ColumnInfo{{Name: "status"}, {Name: "other"}}
+
+	ready, _ := readinessMet(columns, map[string]interface{}{"status": "idle"}, "")
+	assert.True(t, ready, "any row is enough without expect")
+
+	ready, _ = readinessMet(columns, map[string]interface{}{"status": "idle", "other": "x"}, "idle")
+	assert.True(t, ready)
+
+	ready, reason := readinessMet(columns, map[string]interface{}{"status": "repairing"}, "idle")
+	assert.False(t, ready)
+	assert.Equal(t, `status is "repairing", want "idle"`, reason)
+
+	ready, _ = readinessMet(columns, map[string]interface{}{"status": 0}, "0")
+	assert.True(t, ready, "non-text values compare in their text form")
+
+	ready, _ = readinessMet(columns, map[string]interface{}{"status": true}, "true")
+	assert.True(t, ready)
+
+	ready, _ = readinessMet(columns, map[string]interface{}{"status": nil}, "idle")
+	assert.False(t, ready)
+}
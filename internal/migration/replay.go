@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+// WriteReplayScript writes the successfully applied versioned migrations, in
+// version order, as a single CQL script that recreates the schema as
+// applied. Each migration comes from its file when the file still matches
+// the recorded checksum, otherwise from stored (store_script_content). A
+// migration with neither is an error, and nothing is written. Stored content
+// is split with opts. Pruned metadata is an error too: the records folded
+// into a baseline no longer say what was applied. So is a migration with bind
+// args: cqlsh has no values for its "?" markers, and a JSON value does not
+// say which CQL literal it stands for.
+func WriteReplayScript(w io.Writer, applied []schema.AppliedMigration, scanned []*Migration, stored map[string]string, opts ParseOptions) error {
+	if baseline := BaselineVersion(applied); baseline != "" {
+		return fmt.Errorf("cannot reconstruct the schema: the records up to V%s were pruned into a baseline (metadata prune)", baseline)
+	}
+
+	files := make(map[string]*Migration)
+	for _, mig := range scanned {
+		if mig.Type == TypeVersioned {
+			files[mig.Version] = mig
+		}
+	}
+
+	var versioned []schema.AppliedMigration
+	for _, a := range applied {
+		if a.Success && a.Type == string(TypeVersioned) {
+			versioned = append(versioned, a)
+		}
+	}
+	sort.SliceStable(versioned, func(i, j int) bool {
+		return CompareVersions(versioned[i].Version, versioned[j].Version) < 0
+	})
+
+	type source struct {
+		applied schema.AppliedMigration
+		mig     *Migration
+		origin  string
+	}
+	var sources []source
+	var missing []string
+	for _, a := range versioned {
+		if mig, ok := files[a.Version]; ok {
+			if err := ParseMigrationFile(mig); err == nil && mig.Checksum == a.Checksum {
+				sources = append(sources, source{a, mig, mig.Filename})
+				continue
+			}
+		}
+		if content, ok := stored[a.Version]; ok {
+			mig := &Migration{Version: a.Version, Description: a.Description, Type: TypeVersioned, Filename: a.Script, options: &opts}
+			if err := parseContent(mig, content); err != nil {
+				return fmt.Errorf("failed to parse stored script of V%s: %w", a.Version, err)
+			}
+			sources = append(sources, source{a, mig, "stored script content"})
+			continue
+		}
+		if _, ok := files[a.Version]; ok {
+			missing = append(missing, fmt.Sprintf("V%s (file modified since it was applied)", a.Version))
+		} else {
+			missing = append(missing, fmt.Sprintf("V%s (file missing)", a.Version))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("cannot reconstruct %s as applied and no script content is stored — enable store_script_content to keep it in the metadata",
+			strings.Join(missing, ", "))
+	}
+	for _, s := range sources {
+		for i, args := range s.mig.Args {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot write V%s as a replay script: statement %d takes bind args from %s, which cqlsh cannot supply",
+					s.applied.Version, i+1, filepath.Base(ArgsFilePath(s.mig.FilePath)))
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "-- scylla-migrate replay script\n")
+	fmt.Fprintf(w, "-- %d applied versioned migration(s), in version order\n", len(sources))
+	for _, s := range sources {
+		fmt.Fprintf(w, "\n-- ==================================================================\n")
+		fmt.Fprintf(w, "-- V%s: %s\n", s.applied.Version, s.applied.Description)
+		fmt.Fprintf(w, "-- Source: %s\n", s.origin)
+		fmt.Fprintf(w, "-- Checksum: %s\n", s.applied.Checksum)
+		fmt.Fprintf(w, "-- ==================================================================\n\n")
+		err := s.mig.EachStatement(func(_ int, stmt string) error {
+			_, err := fmt.Fprintf(w, "%s;\n\n", stmt)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package migration
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+func TestWriteReplayScript(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__users.cql", "-- users\nCREATE TABLE users (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V002__orders.cql", "CREATE TABLE orders (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V003__pending.cql", "CREATE TABLE later (id UUID PRIMARY KEY);")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	for _, mig := range scanned {
+		require.NoError(t, ParseMigrationFile(mig))
+	}
+
+	applied := []schema.AppliedMigration{
+		{Version: "002", Description: "orders", Type: "versioned", Checksum: scanned[1].Checksum, Success: true},
+		{Version: "001", Description: "users", Type: "versioned", Checksum: scanned[0].Checksum, Success: true},
+		{Version: "R_views", Type: "repeatable", Success: true},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReplayScript(&buf, applied, scanned, nil, DefaultParseOptions()))
+	out := buf.String()
+	assert.Contains(t, out, "-- V001: users\n-- Source: V001__users.cql\n")
+	assert.Contains(t, out, "CREATE TABLE users (id UUID PRIMARY KEY);\n")
+	assert.NotContains(t, out, "later", "pending migrations are not part of the script")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("V001")), bytes.Index(buf.Bytes(), []byte("V002")))
+}
+
+func TestWriteReplayScript_MissingFile(t *testing.T) {
+	applied := []schema.AppliedMigration{
+		{Version: "001", Description: "users", Type: "versioned", Script: "V001__users.cql", Checksum: "x", Success: true},
+	}
+
+	var buf bytes.Buffer
+	err := WriteReplayScript(&buf, applied, nil, nil, DefaultParseOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "V001 (file missing)")
+	assert.Contains(t, err.Error(), "store_script_content")
+	assert.Empty(t, buf.String())
+
+	stored := map[string]string{"001": "CREATE TABLE users (id UUID PRIMARY KEY);"}
+	require.NoError(t, WriteReplayScript(&buf, applied, nil, stored, DefaultParseOptions()))
+	assert.Contains(t, buf.String(), "-- Source: stored script content\n")
+	assert.Contains(t, buf.String(), "CREATE TABLE users (id UUID PRIMARY KEY);\n")
+}
+
+func TestWriteReplayScript_Baseline(t *testing.T) {
+	applied := []schema.AppliedMigration{
+		{Version: "002", Description: "baseline", Type: schema.RecordTypeBaseline, Success: true},
+		{Version: "003", Description: "items", Type: "versioned", Checksum: "x", Success: true},
+	}
+	stored := map[string]string{"003": "CREATE TABLE items (id UUID PRIMARY KEY);"}
+
+	var buf bytes.Buffer
+	err := WriteReplayScript(&buf, applied, nil, stored, DefaultParseOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "V002")
+	assert.Empty(t, buf.String())
+}
+
+func TestWriteReplayScript_BindArgs(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__users.cql", "CREATE TABLE users (id int PRIMARY KEY, name text);")
+	createTestMigration(t, dir, "V002__seed.cql", "CREATE INDEX ON users (name);\nINSERT INTO users (id, name) VALUES (?, ?);")
+	createTestMigration(t, dir, "V002__seed.args.json", `[null, [1, "admin"]]`)
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	var applied []schema.AppliedMigration
+	for _, mig := range scanned {
+		require.NoError(t, ParseMigrationFile(mig))
+		applied = append(applied, schema.AppliedMigration{
+			Version: mig.Version, Description: mig.Description, Type: "versioned", Checksum: mig.Checksum, Success: true,
+		})
+	}
+	require.Len(t, applied, 2)
+
+	var buf bytes.Buffer
+	err = WriteReplayScript(&buf, applied, scanned, nil, DefaultParseOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "V002")
+	assert.Contains(t, err.Error(), "statement 2")
+	assert.Contains(t, err.Error(), "V002__seed.args.json")
+	assert.Empty(t, buf.String(), "nothing is written")
+}
@@ -3,18 +3,134 @@ package migration
 import (
 	"fmt"
 	"sort"
+	"strings"
 
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
 	"github.com/scylla-migrate/scylla-migrate/internal/schema"
 )
 
+// OutOfOrderPolicy decides what happens to pending versioned migrations
+// whose version is lower than the latest applied one.
+type OutOfOrderPolicy string
+
+const (
+	// OutOfOrderFail refuses to resolve pending migrations.
+	OutOfOrderFail OutOfOrderPolicy = "fail"
+	// OutOfOrderWarnAndApply runs them; BuildPlan reports them as warnings.
+	OutOfOrderWarnAndApply OutOfOrderPolicy = "warn-and-apply"
+	// OutOfOrderIgnore treats them as intentionally superseded and never
+	// applies them; see Ignored.
+	OutOfOrderIgnore OutOfOrderPolicy = "ignore"
+)
+
+// RepeatableMode decides when an applied repeatable migration runs again.
+type RepeatableMode string
+
+const (
+	// RepeatableChecksum re-runs a repeatable whenever its checksum changes.
+	RepeatableChecksum RepeatableMode = "checksum"
+	// RepeatableOnce applies a repeatable only if it was never applied;
+	// later content changes are ignored.
+	RepeatableOnce RepeatableMode = "once"
+)
+
 type Resolver struct {
-	migrations []*Migration
+	migrations     []*Migration
+	outOfOrder     OutOfOrderPolicy
+	ignored        []*Migration
+	environment    string
+	skipped        []*Migration
+	repeatableMode RepeatableMode
+	repeatableRuns []schema.RepeatableRun
 }
 
 func NewResolver(migrations []*Migration) *Resolver {
 	return &Resolver{migrations: migrations}
 }
 
+// NewResolverFor returns a resolver with the out-of-order policy,
+// repeatable mode and environment of cfg.
+func NewResolverFor(cfg *config.Config, migrations []*Migration) *Resolver {
+	r := NewResolver(migrations)
+	r.SetOutOfOrderPolicy(OutOfOrderPolicy(cfg.OutOfOrder))
+	r.SetRepeatableMode(RepeatableMode(cfg.RepeatableMode))
+	r.SetEnvironment(cfg.Environment)
+	return r
+}
+
+// NewConfiguredResolver is NewResolverFor the context's config, with the
+// repeatable index read from the cluster. If the index cannot be read, a
+// warning is logged and repeatables are judged by their migration records.
+func NewConfiguredResolver(ctx *ExecutionContext, migrations []*Migration) *Resolver {
+	r := NewResolverFor(ctx.Config, migrations)
+	if runs, err := ctx.MetadataManager.GetRepeatableRuns(); err != nil {
+		ctx.Logger.Warn().Err(err).Msg("Failed to read repeatable index, judging repeatables by their migration records")
+	} else {
+		r.SetRepeatableRuns(runs)
+	}
+	return r
+}
+
+// SetOutOfOrderPolicy sets how GetPendingMigrations treats out-of-order
+// migrations. The zero value behaves like OutOfOrderFail.
+func (r *Resolver) SetOutOfOrderPolicy(policy OutOfOrderPolicy) {
+	r.outOfOrder = policy
+}
+
+// Ignored returns the out-of-order migrations left out by the last
+// GetPendingMigrations call under OutOfOrderIgnore.
+func (r *Resolver) Ignored() []*Migration {
+	return r.ignored
+}
+
+// SetEnvironment sets the active environment, against which migrations with
+// an environments directive are matched.
+func (r *Resolver) SetEnvironment(env string) {
+	r.environment = env
+}
+
+// Skipped returns the migrations left out by the last GetPendingMigrations
+// call because they do not run in the active environment.
+func (r *Resolver) Skipped() []*Migration {
+	return r.skipped
+}
+
+// SetRepeatableMode controls whether changed repeatable migrations re-run
+// (RepeatableChecksum, the default) or are applied only once.
+func (r *Resolver) SetRepeatableMode(mode RepeatableMode) {
+	r.repeatableMode = mode
+}
+
+// SetRepeatableRuns supplies the schema_repeatables index. A repeatable
+// listed there is judged by the content last applied according to the index
+// rather than by its single schema_migrations record.
+func (r *Resolver) SetRepeatableRuns(runs []schema.RepeatableRun) {
+	r.repeatableRuns = runs
+}
+
+// repeatableState returns the checksum of the content currently applied for
+// the repeatable recorded as key, if any.
+func (r *Resolver) repeatableState(key string, appliedMap map[string]schema.AppliedMigration) (string, bool) {
+	if run, ok := schema.LatestRepeatableRun(r.repeatableRuns, key); ok {
+		return run.Checksum, true
+	}
+	a, ok := appliedMap[key]
+	return a.Checksum, ok
+}
+
+// AppliedChecksum returns the checksum of the content in effect for mig,
+// given the checksum of its migration record: for a repeatable listed in the
+// repeatable index, the one last applied according to the index, as
+// GetPendingMigrations judges it.
+func (r *Resolver) AppliedChecksum(mig *Migration, recorded string) string {
+	if mig.Type == TypeRepeatable {
+		if run, ok := schema.LatestRepeatableRun(r.repeatableRuns, mig.Version+"_"+mig.Description); ok {
+			return run.Checksum
+		}
+	}
+	return recorded
+}
+
 func (r *Resolver) GetPendingMigrations(applied []schema.AppliedMigration) ([]*Migration, error) {
 	appliedMap := make(map[string]schema.AppliedMigration)
 	for _, a := range applied {
@@ -24,24 +140,34 @@ func (r *Resolver) GetPendingMigrations(applied []schema.AppliedMigration) ([]*M
 	}
 
 	var pending []*Migration
+	r.skipped = nil
+	baseline := BaselineVersion(applied)
 
 	for _, mig := range r.migrations {
 		switch mig.Type {
 		case TypeVersioned:
-			if _, exists := appliedMap[mig.Version]; !exists {
+			if _, exists := appliedMap[mig.Version]; !exists && !coveredByBaseline(mig.Version, baseline) {
 				if err := ParseMigrationFile(mig); err != nil {
 					return nil, fmt.Errorf("failed to parse migration %s: %w", mig.Filename, err)
 				}
+				if !mig.RunsIn(r.environment) {
+					r.skipped = append(r.skipped, mig)
+					continue
+				}
 				pending = append(pending, mig)
 			}
 		case TypeRepeatable:
 			if err := ParseMigrationFile(mig); err != nil {
 				return nil, fmt.Errorf("failed to parse migration %s: %w", mig.Filename, err)
 			}
+			if !mig.RunsIn(r.environment) {
+				r.skipped = append(r.skipped, mig)
+				continue
+			}
 			key := mig.Version + "_" + mig.Description
-			if a, exists := appliedMap[key]; !exists {
+			if checksum, exists := r.repeatableState(key, appliedMap); !exists {
 				pending = append(pending, mig)
-			} else if a.Checksum != mig.Checksum {
+			} else if checksum != mig.Checksum && r.repeatableMode != RepeatableOnce {
 				pending = append(pending, mig)
 			}
 		case TypeUndo:
@@ -49,11 +175,179 @@ func (r *Resolver) GetPendingMigrations(applied []schema.AppliedMigration) ([]*M
 		}
 	}
 
-	return pending, nil
+	r.ignored = nil
+	if outOfOrder := r.FindOutOfOrder(pending, applied); len(outOfOrder) > 0 {
+		switch r.outOfOrder {
+		case OutOfOrderWarnAndApply:
+		case OutOfOrderIgnore:
+			r.ignored = outOfOrder
+			pending = withoutMigrations(pending, outOfOrder)
+		default:
+			var versions []string
+			for _, mig := range outOfOrder {
+				versions = append(versions, "V"+mig.Version)
+			}
+			return nil, fmt.Errorf("pending migrations are older than the latest applied version: %s "+
+				"(set out_of_order to warn-and-apply or ignore to allow this)", strings.Join(versions, ", "))
+		}
+	}
+
+	return r.OrderByDependencies(pending, applied)
+}
+
+func withoutMigrations(migs, remove []*Migration) []*Migration {
+	skip := make(map[*Migration]bool, len(remove))
+	for _, mig := range remove {
+		skip[mig] = true
+	}
+	var kept []*Migration
+	for _, mig := range migs {
+		if !skip[mig] {
+			kept = append(kept, mig)
+		}
+	}
+	return kept
+}
+
+// DependsOnDirective declares versions that must be applied before a
+// migration, e.g. "-- scylla-migrate:depends-on 003,007".
+const DependsOnDirective = "depends-on"
+
+// Dependencies returns the versions listed in the depends-on directive.
+func (m *Migration) Dependencies() []string {
+	value, ok := m.Directives[DependsOnDirective]
+	if !ok {
+		return nil
+	}
+	var deps []string
+	for _, f := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		deps = append(deps, strings.TrimLeft(f, "Vv"))
+	}
+	return deps
+}
+
+// OrderByDependencies sorts migrations topologically by their declared
+// dependencies, breaking ties by the incoming (version) order, so the result
+// is unchanged when nothing declares a dependency. Each dependency must be a
+// known versioned migration that is either already applied or part of
+// migrations; cycles are rejected.
+func (r *Resolver) OrderByDependencies(migrations []*Migration, applied []schema.AppliedMigration) ([]*Migration, error) {
+	baseline := BaselineVersion(applied)
+	isApplied := func(version string) bool {
+		if coveredByBaseline(version, baseline) {
+			return true
+		}
+		for _, a := range applied {
+			if a.Success && a.Type == string(TypeVersioned) && CompareVersions(a.Version, version) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	exists := func(version string) bool {
+		for _, mig := range r.migrations {
+			if mig.Type == TypeVersioned && CompareVersions(mig.Version, version) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	indexOf := func(version string) int {
+		for i, mig := range migrations {
+			if mig.Type == TypeVersioned && CompareVersions(mig.Version, version) == 0 {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// edges[i] lists the migrations that must wait for migrations[i]
+	edges := make([][]int, len(migrations))
+	indegree := make([]int, len(migrations))
+	declared := false
+	for i, mig := range migrations {
+		for _, dep := range mig.Dependencies() {
+			declared = true
+			if j := indexOf(dep); j >= 0 {
+				if j == i {
+					return nil, fmt.Errorf("%s depends on itself", mig.Filename)
+				}
+				edges[j] = append(edges[j], i)
+				indegree[i]++
+				continue
+			}
+			if isApplied(dep) {
+				continue
+			}
+			if !exists(dep) {
+				return nil, fmt.Errorf("%s depends on V%s, which does not exist", mig.Filename, dep)
+			}
+			return nil, fmt.Errorf("%s depends on V%s, which is neither applied nor selected to run", mig.Filename, dep)
+		}
+	}
+	if !declared {
+		return migrations, nil
+	}
+
+	ordered := make([]*Migration, 0, len(migrations))
+	done := make([]bool, len(migrations))
+	for len(ordered) < len(migrations) {
+		next := -1
+		for i := range migrations {
+			if !done[i] && indegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for i, mig := range migrations {
+				if !done[i] {
+					cycle = append(cycle, mig.Filename)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle between migrations: %s", strings.Join(cycle, ", "))
+		}
+
+		done[next] = true
+		ordered = append(ordered, migrations[next])
+		for _, j := range edges[next] {
+			indegree[j]--
+		}
+	}
+
+	return ordered, nil
+}
+
+// Validation issue kinds reported by ValidateAppliedChecksumsDetailed.
+const (
+	IssueMissingFile      = "missing_file"
+	IssueParseError       = "parse_error"
+	IssueChecksumMismatch = "checksum_mismatch"
+)
+
+// ValidationIssue describes one applied migration that no longer matches
+// its file.
+type ValidationIssue struct {
+	Version          string `json:"version"`
+	Kind             string `json:"kind"`
+	RecordedChecksum string `json:"recorded_checksum"`
+	CurrentChecksum  string `json:"current_checksum,omitempty"`
+	Message          string `json:"message"`
 }
 
 func (r *Resolver) ValidateAppliedChecksums(applied []schema.AppliedMigration) []string {
 	var errors []string
+	for _, issue := range r.ValidateAppliedChecksumsDetailed(applied) {
+		errors = append(errors, issue.Message)
+	}
+	return errors
+}
+
+// ValidateAppliedChecksumsDetailed is ValidateAppliedChecksums with typed
+// fields, for machine-readable output.
+func (r *Resolver) ValidateAppliedChecksumsDetailed(applied []schema.AppliedMigration) []ValidationIssue {
+	var issues []ValidationIssue
 
 	fileMap := make(map[string]*Migration)
 	for _, mig := range r.migrations {
@@ -63,36 +357,45 @@ func (r *Resolver) ValidateAppliedChecksums(applied []schema.AppliedMigration) [
 	}
 
 	for _, a := range applied {
-		if !a.Success || a.Type == "repeatable" {
+		if !a.Success || a.Type == "repeatable" || a.Type == schema.RecordTypeBaseline {
 			continue
 		}
 
+		issue := ValidationIssue{Version: a.Version, RecordedChecksum: a.Checksum}
+
 		fileMig, exists := fileMap[a.Version]
 		if !exists {
-			errors = append(errors, fmt.Sprintf(
+			issue.Kind = IssueMissingFile
+			issue.Message = fmt.Sprintf(
 				"applied migration V%s (%s) has no corresponding file",
 				a.Version, a.Description,
-			))
+			)
+			issues = append(issues, issue)
 			continue
 		}
 
 		if err := ParseMigrationFile(fileMig); err != nil {
-			errors = append(errors, fmt.Sprintf(
+			issue.Kind = IssueParseError
+			issue.Message = fmt.Sprintf(
 				"failed to parse V%s (%s): %s",
 				a.Version, a.Description, err,
-			))
+			)
+			issues = append(issues, issue)
 			continue
 		}
 
 		if fileMig.Checksum != a.Checksum {
-			errors = append(errors, fmt.Sprintf(
+			issue.Kind = IssueChecksumMismatch
+			issue.CurrentChecksum = fileMig.Checksum
+			issue.Message = fmt.Sprintf(
 				"checksum mismatch for V%s (%s): recorded=%s, current=%s",
 				a.Version, a.Description, a.Checksum, fileMig.Checksum,
-			))
+			)
+			issues = append(issues, issue)
 		}
 	}
 
-	return errors
+	return issues
 }
 
 func (r *Resolver) GetVersionedMigrations() []*Migration {
@@ -130,3 +433,176 @@ func (r *Resolver) FilterUpToTarget(migrations []*Migration, target string) []*M
 	}
 	return filtered
 }
+
+// FilterRange keeps versioned migrations whose version lies within the
+// inclusive range [from, to]. An empty bound leaves that side open.
+// Repeatable migrations are only kept when includeRepeatable is set, since
+// a range is meant to apply a deliberate window of versioned changes.
+func (r *Resolver) FilterRange(migrations []*Migration, from, to string, includeRepeatable bool) []*Migration {
+	var filtered []*Migration
+	for _, mig := range migrations {
+		if mig.Type == TypeRepeatable {
+			if includeRepeatable {
+				filtered = append(filtered, mig)
+			}
+			continue
+		}
+		if from != "" && CompareVersions(mig.Version, from) < 0 {
+			continue
+		}
+		if to != "" && CompareVersions(mig.Version, to) > 0 {
+			continue
+		}
+		filtered = append(filtered, mig)
+	}
+	return filtered
+}
+
+// FindOutOfOrder returns the pending versioned migrations whose version is
+// lower than the highest successfully applied versioned migration.
+func (r *Resolver) FindOutOfOrder(pending []*Migration, applied []schema.AppliedMigration) []*Migration {
+	latest := ""
+	for _, a := range applied {
+		if !a.Success || (a.Type != string(TypeVersioned) && a.Type != schema.RecordTypeBaseline) {
+			continue
+		}
+		if latest == "" || CompareVersions(a.Version, latest) > 0 {
+			latest = a.Version
+		}
+	}
+	if latest == "" {
+		return nil
+	}
+
+	var outOfOrder []*Migration
+	for _, mig := range pending {
+		if mig.Type == TypeVersioned && CompareVersions(mig.Version, latest) < 0 {
+			outOfOrder = append(outOfOrder, mig)
+		}
+	}
+	return outOfOrder
+}
+
+// BuildPlan computes the ordered migrations for a run without executing
+// anything. Forward plans contain the pending migrations up to target (all
+// when empty), ordered by version and declared dependencies; checksum
+// mismatches, out-of-order versions and missing undo files are reported as
+// warnings. Rollback plans undo every applied versioned migration above
+// target, newest first ("0" undoes everything; empty undoes only the latest)
+// and fail if an undo file is missing.
+func (r *Resolver) BuildPlan(applied []schema.AppliedMigration, target string, direction Direction) (*Plan, error) {
+	switch direction {
+	case DirectionForward:
+		return r.buildForwardPlan(applied, target)
+	case DirectionRollback:
+		return r.buildRollbackPlan(applied, target)
+	default:
+		return nil, fmt.Errorf("unknown plan direction %q", direction)
+	}
+}
+
+func (r *Resolver) buildForwardPlan(applied []schema.AppliedMigration, target string) (*Plan, error) {
+	pending, err := r.GetPendingMigrations(applied)
+	if err != nil {
+		return nil, err
+	}
+
+	if target != "" {
+		pending = r.FilterUpToTarget(pending, target)
+		if pending, err = r.OrderByDependencies(pending, applied); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := NewPlan(pending)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Warnings = append(plan.Warnings, r.ValidateAppliedChecksums(applied)...)
+	for _, mig := range r.FindOutOfOrder(pending, applied) {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+			"V%s (%s) is older than the latest applied version", mig.Version, mig.Description))
+	}
+	for i, step := range plan.Steps {
+		if step.Migration.Type != TypeVersioned {
+			continue
+		}
+		plan.Steps[i].Undo = r.GetUndoMigration(step.Migration.Version)
+		if plan.Steps[i].Undo == nil {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+				"V%s (%s) has no undo migration", step.Migration.Version, step.Migration.Description))
+		}
+	}
+
+	return &plan, nil
+}
+
+func (r *Resolver) buildRollbackPlan(applied []schema.AppliedMigration, target string) (*Plan, error) {
+	versioned := appliedVersionedDesc(applied)
+
+	var toRollback []schema.AppliedMigration
+	switch {
+	case target != "":
+		for _, a := range versioned {
+			if CompareVersions(a.Version, target) > 0 {
+				toRollback = append(toRollback, a)
+			}
+		}
+	case len(versioned) > 0:
+		toRollback = versioned[:1]
+	}
+
+	var undos []*Migration
+	for _, a := range toRollback {
+		undo := r.GetUndoMigration(a.Version)
+		if undo == nil {
+			return nil, fmt.Errorf("no undo migration file found for version %s (%s) — expected U%s__*.cql",
+				a.Version, a.Description, a.Version)
+		}
+		if err := ParseMigrationFile(undo); err != nil {
+			return nil, fmt.Errorf("failed to parse undo migration %s: %w", undo.Filename, err)
+		}
+		undos = append(undos, undo)
+	}
+
+	plan, err := NewPlan(undos)
+	if err != nil {
+		return nil, err
+	}
+	plan.Direction = DirectionRollback
+	for i := range plan.Steps {
+		a := toRollback[i]
+		plan.Steps[i].Applied = &a
+	}
+
+	return &plan, nil
+}
+
+// RollbackTarget converts a step count into a rollback target: rolling back
+// steps migrations means keeping everything up to the returned version.
+func (r *Resolver) RollbackTarget(applied []schema.AppliedMigration, steps int) string {
+	versioned := appliedVersionedDesc(applied)
+	if steps < 1 {
+		steps = 1
+	}
+	if steps >= len(versioned) {
+		return "0"
+	}
+	return versioned[steps].Version
+}
+
+// appliedVersionedDesc returns the successfully applied versioned
+// migrations, newest first.
+func appliedVersionedDesc(applied []schema.AppliedMigration) []schema.AppliedMigration {
+	var versioned []schema.AppliedMigration
+	for _, a := range applied {
+		if a.Success && a.Type == string(TypeVersioned) {
+			versioned = append(versioned, a)
+		}
+	}
+	sort.Slice(versioned, func(i, j int) bool {
+		return CompareVersions(versioned[i].Version, versioned[j].Version) > 0
+	})
+	return versioned
+}
@@ -2,11 +2,14 @@ package migration
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
 	"github.com/scylla-migrate/scylla-migrate/internal/schema"
 )
 
@@ -96,6 +99,104 @@ func TestResolver_FilterUpToTarget(t *testing.T) {
 	assert.Equal(t, TypeRepeatable, filtered[2].Type)
 }
 
+func TestResolver_FilterRange(t *testing.T) {
+	migrations := []*Migration{
+		{Version: "001", Type: TypeVersioned},
+		{Version: "002", Type: TypeVersioned},
+		{Version: "003", Type: TypeVersioned},
+		{Version: "004", Type: TypeVersioned},
+		{Version: "R", Type: TypeRepeatable, Description: "views"},
+	}
+
+	resolver := NewResolver(nil)
+
+	filtered := resolver.FilterRange(migrations, "002", "003", false)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "002", filtered[0].Version)
+	assert.Equal(t, "003", filtered[1].Version)
+
+	filtered = resolver.FilterRange(migrations, "003", "", true)
+	require.Len(t, filtered, 3) // 003, 004, and the repeatable
+	assert.Equal(t, TypeRepeatable, filtered[2].Type)
+
+	filtered = resolver.FilterRange(migrations, "", "001", false)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "001", filtered[0].Version)
+}
+
+func TestResolver_FindOutOfOrder(t *testing.T) {
+	pending := []*Migration{
+		{Version: "002", Type: TypeVersioned},
+		{Version: "004", Type: TypeVersioned},
+		{Version: "R", Type: TypeRepeatable, Description: "views"},
+	}
+	applied := []schema.AppliedMigration{
+		{Version: "001", Success: true, Type: "versioned"},
+		{Version: "003", Success: true, Type: "versioned"},
+		{Version: "005", Success: false, Type: "versioned"},
+	}
+
+	resolver := NewResolver(nil)
+
+	outOfOrder := resolver.FindOutOfOrder(pending, applied)
+	require.Len(t, outOfOrder, 1)
+	assert.Equal(t, "002", outOfOrder[0].Version)
+
+	assert.Empty(t, resolver.FindOutOfOrder(pending, nil))
+}
+
+func TestResolver_GetPendingMigrations_DependsOn(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V003__users.cql", "-- scylla-migrate:depends-on 007\nCREATE TABLE users (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V005__orders.cql", "CREATE TABLE orders (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V007__types.cql", "-- scylla-migrate:depends-on V001\nCREATE TYPE address (street text);")
+	createTestMigration(t, dir, "R__views.cql", "SELECT now() FROM system.local;")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+
+	applied := []schema.AppliedMigration{
+		{Version: "001", Success: true, Type: "versioned"},
+	}
+
+	pending, err := NewResolver(scanned).GetPendingMigrations(applied)
+	require.NoError(t, err)
+
+	var order []string
+	for _, mig := range pending {
+		order = append(order, mig.Version)
+	}
+	assert.Equal(t, []string{"005", "007", "003", "R"}, order)
+}
+
+func TestResolver_OrderByDependencies_Errors(t *testing.T) {
+	migs := []*Migration{
+		{Version: "001", Type: TypeVersioned, Filename: "V001__a.cql", Directives: map[string]string{"depends-on": "002"}},
+		{Version: "002", Type: TypeVersioned, Filename: "V002__b.cql", Directives: map[string]string{"depends-on": "001"}},
+		{Version: "003", Type: TypeVersioned, Filename: "V003__c.cql"},
+	}
+	resolver := NewResolver(migs)
+
+	_, err := resolver.OrderByDependencies(migs, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+
+	missing := []*Migration{
+		{Version: "003", Type: TypeVersioned, Filename: "V003__c.cql", Directives: map[string]string{"depends-on": "009"}},
+	}
+	_, err = resolver.OrderByDependencies(missing, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+
+	// The dependency exists but was filtered out of this run
+	filtered := []*Migration{
+		{Version: "003", Type: TypeVersioned, Filename: "V003__c.cql", Directives: map[string]string{"depends-on": "002"}},
+	}
+	_, err = resolver.OrderByDependencies(filtered, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "neither applied nor selected")
+}
+
 func TestResolver_ValidateAppliedChecksums(t *testing.T) {
 	dir := t.TempDir()
 	createTestMigration(t, dir, "V001__first.cql", "CREATE TABLE first (id UUID PRIMARY KEY);")
@@ -123,6 +224,35 @@ func TestResolver_ValidateAppliedChecksums(t *testing.T) {
 	assert.Contains(t, errors[0], "checksum mismatch")
 }
 
+func TestResolver_ValidateAppliedChecksumsDetailed(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__first.cql", "CREATE TABLE first (id UUID PRIMARY KEY);")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, ParseMigrationFile(scanned[0]))
+	current := scanned[0].Checksum
+
+	applied := []schema.AppliedMigration{
+		{Version: "001", Checksum: "stale", Success: true, Type: "versioned", Description: "first"},
+		{Version: "002", Checksum: "gone", Success: true, Type: "versioned", Description: "second"},
+		{Version: "003", Checksum: "failed", Success: false, Type: "versioned", Description: "third"},
+	}
+
+	issues := NewResolver(scanned).ValidateAppliedChecksumsDetailed(applied)
+	require.Len(t, issues, 2)
+
+	assert.Equal(t, "001", issues[0].Version)
+	assert.Equal(t, IssueChecksumMismatch, issues[0].Kind)
+	assert.Equal(t, "stale", issues[0].RecordedChecksum)
+	assert.Equal(t, current, issues[0].CurrentChecksum)
+
+	assert.Equal(t, "002", issues[1].Version)
+	assert.Equal(t, IssueMissingFile, issues[1].Kind)
+	assert.Empty(t, issues[1].CurrentChecksum)
+	assert.Contains(t, issues[1].Message, "has no corresponding file")
+}
+
 func TestCompareVersions(t *testing.T) {
 	tests := []struct {
 		a, b string
@@ -153,3 +283,265 @@ func createTestMigration(t *testing.T, dir, filename, content string) {
 	path := dir + "/" + filename
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 }
+
+func setupPlanDir(t *testing.T) []*Migration {
+	t.Helper()
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__users.cql", "CREATE TABLE users (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "U001__users.cql", "DROP TABLE users;")
+	createTestMigration(t, dir, "V002__orders.cql", "CREATE TABLE orders (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "U002__orders.cql", "DROP TABLE orders;")
+	createTestMigration(t, dir, "V003__index.cql", "CREATE INDEX ON orders (id);")
+	createTestMigration(t, dir, "R__views.cql", "SELECT now() FROM system.local;")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	return scanned
+}
+
+func planVersions(plan *Plan) []string {
+	var versions []string
+	for _, mig := range plan.ExecutionOrder() {
+		versions = append(versions, string(mig.Type[0])+mig.Version)
+	}
+	return versions
+}
+
+func TestResolver_BuildPlan_Forward(t *testing.T) {
+	resolver := NewResolver(setupPlanDir(t))
+
+	plan, err := resolver.BuildPlan(nil, "", DirectionForward)
+	require.NoError(t, err)
+	assert.Equal(t, DirectionForward, plan.Direction)
+	assert.Equal(t, []string{"v001", "v002", "v003", "rR"}, planVersions(plan))
+	require.Len(t, plan.Migrations, 4)
+	assert.Equal(t, 1, plan.Migrations[0].StatementCount)
+
+	// Undo files are attached; a missing one is a warning, not an error
+	require.NotNil(t, plan.Steps[0].Undo)
+	assert.Equal(t, "U001__users.cql", plan.Steps[0].Undo.Filename)
+	assert.Nil(t, plan.Steps[2].Undo)
+	require.Len(t, plan.Warnings, 1)
+	assert.Contains(t, plan.Warnings[0], "V003")
+}
+
+func TestResolver_BuildPlan_ForwardTargetAndApplied(t *testing.T) {
+	scanned := setupPlanDir(t)
+	resolver := NewResolver(scanned)
+	resolver.SetOutOfOrderPolicy(OutOfOrderWarnAndApply)
+
+	plan, err := resolver.BuildPlan(nil, "002", DirectionForward)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v001", "v002", "rR"}, planVersions(plan))
+
+	applied := []schema.AppliedMigration{
+		{Version: "001", Type: "versioned", Success: true, Checksum: "stale"},
+		{Version: "003", Type: "versioned", Success: true},
+	}
+	plan, err = resolver.BuildPlan(applied, "", DirectionForward)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v002", "rR"}, planVersions(plan))
+
+	warnings := strings.Join(plan.Warnings, "\n")
+	assert.Contains(t, warnings, "checksum mismatch for V001")
+	assert.Contains(t, warnings, "V002 (orders) is older than the latest applied version")
+}
+
+func TestResolver_BuildPlan_Rollback(t *testing.T) {
+	resolver := NewResolver(setupPlanDir(t))
+	applied := []schema.AppliedMigration{
+		{Version: "001", Type: "versioned", Success: true, Description: "users"},
+		{Version: "002", Type: "versioned", Success: true, Description: "orders"},
+		{Version: "R_views", Type: "repeatable", Success: true},
+	}
+
+	// Empty target rolls back only the latest
+	plan, err := resolver.BuildPlan(applied, "", DirectionRollback)
+	require.NoError(t, err)
+	assert.Equal(t, DirectionRollback, plan.Direction)
+	assert.Equal(t, []string{"u002"}, planVersions(plan))
+	assert.Equal(t, "002", plan.Steps[0].Applied.Version)
+	assert.Equal(t, []string{"DROP TABLE orders"}, plan.Steps[0].Migration.Statements)
+
+	plan, err = resolver.BuildPlan(applied, "0", DirectionRollback)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u002", "u001"}, planVersions(plan))
+	assert.Equal(t, "001", plan.Steps[1].Applied.Version)
+
+	plan, err = resolver.BuildPlan(applied, "002", DirectionRollback)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Steps)
+	assert.NotNil(t, plan.Migrations)
+}
+
+func TestResolver_BuildPlan_RollbackMissingUndo(t *testing.T) {
+	resolver := NewResolver(setupPlanDir(t))
+	applied := []schema.AppliedMigration{
+		{Version: "002", Type: "versioned", Success: true},
+		{Version: "003", Type: "versioned", Success: true, Description: "index"},
+	}
+
+	_, err := resolver.BuildPlan(applied, "001", DirectionRollback)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no undo migration file found for version 003")
+
+	_, err = resolver.BuildPlan(applied, "", "sideways")
+	assert.Error(t, err)
+}
+
+func TestResolver_RollbackTarget(t *testing.T) {
+	resolver := NewResolver(nil)
+	applied := []schema.AppliedMigration{
+		{Version: "001", Type: "versioned", Success: true},
+		{Version: "002", Type: "versioned", Success: true},
+		{Version: "003", Type: "versioned", Success: false},
+		{Version: "004", Type: "versioned", Success: true},
+	}
+
+	assert.Equal(t, "002", resolver.RollbackTarget(applied, 1))
+	assert.Equal(t, "001", resolver.RollbackTarget(applied, 2))
+	assert.Equal(t, "0", resolver.RollbackTarget(applied, 3))
+	assert.Equal(t, "0", resolver.RollbackTarget(applied, 10))
+	assert.Equal(t, "002", resolver.RollbackTarget(applied, 0))
+}
+
+func TestResolver_GetPendingMigrations_OutOfOrderPolicy(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__users.cql", "CREATE TABLE users (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V002__legacy.cql", "CREATE TABLE legacy (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V003__orders.cql", "CREATE TABLE orders (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V004__items.cql", "CREATE TABLE items (id UUID PRIMARY KEY);")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+
+	applied := []schema.AppliedMigration{
+		{Version: "001", Success: true, Type: "versioned"},
+		{Version: "003", Success: true, Type: "versioned"},
+	}
+
+	resolver := NewResolver(scanned)
+
+	_, err = resolver.GetPendingMigrations(applied)
+	require.Error(t, err, "the zero value must behave like fail")
+
+	resolver.SetOutOfOrderPolicy(OutOfOrderFail)
+	_, err = resolver.GetPendingMigrations(applied)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "V002")
+
+	resolver.SetOutOfOrderPolicy(OutOfOrderWarnAndApply)
+	pending, err := resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "002", pending[0].Version)
+	assert.Empty(t, resolver.Ignored())
+
+	resolver.SetOutOfOrderPolicy(OutOfOrderIgnore)
+	pending, err = resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "004", pending[0].Version)
+	require.Len(t, resolver.Ignored(), 1)
+	assert.Equal(t, "002", resolver.Ignored()[0].Version)
+}
+
+func TestNewResolverFor(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__users.cql", "CREATE TABLE users (id UUID PRIMARY KEY);")
+	createTestMigration(t, dir, "V002__legacy.cql", "CREATE TABLE legacy (id UUID PRIMARY KEY);")
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	applied := []schema.AppliedMigration{{Version: "002", Success: true, Type: "versioned"}}
+
+	cfg := &config.Config{OutOfOrder: string(OutOfOrderIgnore)}
+	pending, err := NewResolverFor(cfg, scanned).GetPendingMigrations(applied)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	cfg.OutOfOrder = string(OutOfOrderFail)
+	_, err = NewResolverFor(cfg, scanned).GetPendingMigrations(applied)
+	assert.Error(t, err)
+}
+
+func TestResolver_GetPendingMigrations_RepeatableMode(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "R__views.cql", "SELECT now() FROM system.local;")
+	createTestMigration(t, dir, "R__reports.cql", "SELECT now() FROM system.local;")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+
+	// views was applied with different content; reports never ran
+	applied := []schema.AppliedMigration{
+		{Version: "R_views", Type: "repeatable", Checksum: "old", Success: true},
+	}
+
+	resolver := NewResolver(scanned)
+	pending, err := resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2, "checksum mode re-runs the changed repeatable")
+
+	resolver.SetRepeatableMode(RepeatableOnce)
+	pending, err = resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "once mode ignores the changed repeatable")
+	assert.Equal(t, "reports", pending[0].Description)
+}
+
+func TestResolver_GetPendingMigrations_RepeatableIndex(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "R__views.cql", "SELECT now() FROM system.local;")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, ParseMigrationFile(scanned[0]))
+	current := scanned[0].Checksum
+
+	// The single record still has the old checksum, as when a concurrent
+	// run applied the current content after this run read it
+	applied := []schema.AppliedMigration{
+		{Version: "R_views", Type: "repeatable", Checksum: "old", Success: true},
+	}
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	resolver := NewResolver(scanned)
+	resolver.SetRepeatableRuns([]schema.RepeatableRun{
+		{Name: "R_views", Checksum: "old", Status: schema.RepeatableApplied, AppliedAt: at},
+		{Name: "R_views", Checksum: current, Status: schema.RepeatableApplied, AppliedAt: at.Add(time.Hour)},
+	})
+	pending, err := resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	assert.Empty(t, pending, "the index shows this content is in effect")
+
+	// Content reverted to a version applied before: it runs again
+	resolver.SetRepeatableRuns([]schema.RepeatableRun{
+		{Name: "R_views", Checksum: current, Status: schema.RepeatableApplied, AppliedAt: at},
+		{Name: "R_views", Checksum: "newer", Status: schema.RepeatableApplied, AppliedAt: at.Add(time.Hour)},
+		{Name: "R_views", Checksum: "broken", Status: schema.RepeatableFailed, AppliedAt: at.Add(2 * time.Hour)},
+	})
+	pending, err = resolver.GetPendingMigrations(applied)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+}
+
+func TestResolver_AppliedChecksum(t *testing.T) {
+	dir := t.TempDir()
+	createTestMigration(t, dir, "V001__init.cql", "CREATE TABLE t (id int PRIMARY KEY);")
+	createTestMigration(t, dir, "R__views.cql", "SELECT now() FROM system.local;")
+
+	scanned, err := ScanMigrationsDir(dir)
+	require.NoError(t, err)
+	versioned, views := scanned[0], scanned[1]
+	require.NoError(t, ParseMigrationFile(views))
+
+	resolver := NewResolver(scanned)
+	assert.Equal(t, "old", resolver.AppliedChecksum(views, "old"), "no index: the record decides")
+
+	resolver.SetRepeatableRuns([]schema.RepeatableRun{
+		{Name: "R_views", Checksum: views.Checksum, Status: schema.RepeatableApplied, AppliedAt: time.Now()},
+		{Name: "V001_init", Checksum: "other", Status: schema.RepeatableApplied, AppliedAt: time.Now()},
+	})
+	assert.Equal(t, views.Checksum, resolver.AppliedChecksum(views, "old"))
+	assert.Equal(t, "recorded", resolver.AppliedChecksum(versioned, "recorded"), "only repeatables use the index")
+}
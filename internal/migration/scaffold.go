@@ -0,0 +1,144 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var scaffoldIdentifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// ScalarTypes are the CQL types accepted by the create scaffolding, on their
+// own or inside list<>, set<> and map<>.
+var ScalarTypes = []string{
+	"ascii", "bigint", "blob", "boolean", "counter", "date", "decimal", "double",
+	"duration", "float", "inet", "int", "smallint", "text", "time", "timestamp",
+	"timeuuid", "tinyint", "uuid", "varchar", "varint",
+}
+
+var collectionType = regexp.MustCompile(`^(list|set)<\s*(\w+)\s*>$|^map<\s*(\w+)\s*,\s*(\w+)\s*>$`)
+
+// Column is a column of a scaffolded table.
+type Column struct {
+	Name string
+	Type string
+}
+
+// TableSpec describes a table to scaffold. The first primary key column is
+// the partition key; any further ones are clustering columns.
+type TableSpec struct {
+	Name       string
+	PrimaryKey []Column
+	Columns    []Column
+}
+
+// ParseColumn parses a "name:type" flag value, normalizing the type.
+func ParseColumn(spec string) (Column, error) {
+	name, typ, ok := strings.Cut(spec, ":")
+	name, typ = strings.TrimSpace(name), strings.ToLower(strings.TrimSpace(typ))
+	if !ok || name == "" || typ == "" {
+		return Column{}, fmt.Errorf("invalid column %q: expected name:type", spec)
+	}
+	if !scaffoldIdentifier.MatchString(name) {
+		return Column{}, fmt.Errorf("invalid column name %q", name)
+	}
+
+	typ, err := normalizeColumnType(typ)
+	if err != nil {
+		return Column{}, fmt.Errorf("column %s: %w", name, err)
+	}
+	return Column{Name: name, Type: typ}, nil
+}
+
+func normalizeColumnType(typ string) (string, error) {
+	if isScalarType(typ) {
+		return typ, nil
+	}
+
+	m := collectionType.FindStringSubmatch(typ)
+	switch {
+	case m == nil:
+	case m[1] != "" && isScalarType(m[2]) && m[2] != "counter":
+		return fmt.Sprintf("%s<%s>", m[1], m[2]), nil
+	case m[3] != "" && isScalarType(m[3]) && isScalarType(m[4]) && m[3] != "counter" && m[4] != "counter":
+		return fmt.Sprintf("map<%s, %s>", m[3], m[4]), nil
+	}
+	return "", fmt.Errorf("unsupported type %q (allowed: %s, or list/set/map of them)",
+		typ, strings.Join(ScalarTypes, ", "))
+}
+
+func isScalarType(typ string) bool {
+	for _, t := range ScalarTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the table and column names, that a primary key is given,
+// that no column is declared twice and that counter columns are not mixed
+// with others, which Scylla would reject.
+func (t TableSpec) Validate() error {
+	ks, name, qualified := strings.Cut(t.Name, ".")
+	if !scaffoldIdentifier.MatchString(ks) || (qualified && !scaffoldIdentifier.MatchString(name)) {
+		return fmt.Errorf("invalid table name %q", t.Name)
+	}
+	for _, part := range []string{ks, name} {
+		if IsReservedKeyword(part) {
+			return fmt.Errorf("table name %q uses the reserved keyword %s", t.Name, strings.ToUpper(part))
+		}
+	}
+	if len(t.PrimaryKey) == 0 {
+		return fmt.Errorf("table %s needs at least one --pk column", t.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, col := range append(append([]Column{}, t.PrimaryKey...), t.Columns...) {
+		key := strings.ToLower(col.Name)
+		if seen[key] {
+			return fmt.Errorf("column %s is declared more than once", col.Name)
+		}
+		seen[key] = true
+		if IsReservedKeyword(col.Name) {
+			return fmt.Errorf("column name %s is a reserved CQL keyword", col.Name)
+		}
+	}
+	for _, col := range t.PrimaryKey {
+		if col.Type == "counter" || strings.Contains(col.Type, "<") {
+			return fmt.Errorf("primary key column %s cannot be of type %s", col.Name, col.Type)
+		}
+	}
+	counters := 0
+	for _, col := range t.Columns {
+		if col.Type == "counter" {
+			counters++
+		}
+	}
+	if counters > 0 && counters < len(t.Columns) {
+		return fmt.Errorf("table %s mixes counter and non-counter columns: outside the primary key a counter table may only have counters", t.Name)
+	}
+	return nil
+}
+
+// CreateCQL renders the CREATE TABLE statement for the spec.
+func (t TableSpec) CreateCQL() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n", t.Name)
+
+	var keys []string
+	for _, col := range t.PrimaryKey {
+		fmt.Fprintf(&b, "    %s %s,\n", col.Name, col.Type)
+		keys = append(keys, col.Name)
+	}
+	for _, col := range t.Columns {
+		fmt.Fprintf(&b, "    %s %s,\n", col.Name, col.Type)
+	}
+	fmt.Fprintf(&b, "    PRIMARY KEY (%s)\n);\n", strings.Join(keys, ", "))
+	return b.String()
+}
+
+// DropCQL renders the statement that undoes CreateCQL.
+func (t TableSpec) DropCQL() string {
+	return fmt.Sprintf("DROP TABLE %s;\n", t.Name)
+}
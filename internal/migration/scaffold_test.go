@@ -0,0 +1,80 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColumn(t *testing.T) {
+	col, err := ParseColumn("email:TEXT")
+	require.NoError(t, err)
+	assert.Equal(t, Column{Name: "email", Type: "text"}, col)
+
+	col, err = ParseColumn("tags:map< text ,int>")
+	require.NoError(t, err)
+	assert.Equal(t, "map<text, int>", col.Type)
+
+	for _, bad := range []string{"email", ":text", "1st:text", "x:jsonb", "x:list<counter>", "x:frozen<address>"} {
+		_, err := ParseColumn(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestTableSpec_CQL(t *testing.T) {
+	spec := TableSpec{
+		Name:       "app.events",
+		PrimaryKey: []Column{{"tenant_id", "uuid"}, {"created_at", "timestamp"}},
+		Columns:    []Column{{"payload", "text"}},
+	}
+	require.NoError(t, spec.Validate())
+
+	assert.Equal(t, `CREATE TABLE app.events (
+    tenant_id uuid,
+    created_at timestamp,
+    payload text,
+    PRIMARY KEY (tenant_id, created_at)
+);
+`, spec.CreateCQL())
+	assert.Equal(t, "DROP TABLE app.events;\n", spec.DropCQL())
+
+	stmts, err := splitStatements(spec.CreateCQL(), ";")
+	require.NoError(t, err)
+	assert.Len(t, stmts, 1)
+}
+
+func TestTableSpec_Validate(t *testing.T) {
+	assert.Error(t, TableSpec{Name: "users"}.Validate())
+	assert.Error(t, TableSpec{Name: "bad-name", PrimaryKey: []Column{{"id", "uuid"}}}.Validate())
+	assert.Error(t, TableSpec{Name: "users", PrimaryKey: []Column{{"id", "uuid"}}, Columns: []Column{{"ID", "text"}}}.Validate())
+	assert.Error(t, TableSpec{Name: "users", PrimaryKey: []Column{{"tags", "set<text>"}}}.Validate())
+}
+
+func TestTableSpec_Validate_CountersAndKeywords(t *testing.T) {
+	id := []Column{{"id", "uuid"}}
+	tests := []struct {
+		name    string
+		spec    TableSpec
+		wantErr string
+	}{
+		{"counters only", TableSpec{Name: "hits", PrimaryKey: id, Columns: []Column{{"views", "counter"}, {"likes", "counter"}}}, ""},
+		{"counter mixed with text", TableSpec{Name: "hits", PrimaryKey: id, Columns: []Column{{"views", "counter"}, {"title", "text"}}}, "mixes counter"},
+		{"non-reserved keyword", TableSpec{Name: "app.key", PrimaryKey: []Column{{"ttl", "int"}}}, ""},
+		{"reserved table", TableSpec{Name: "table", PrimaryKey: id}, "reserved keyword TABLE"},
+		{"reserved qualified table", TableSpec{Name: "app.order", PrimaryKey: id}, "reserved keyword ORDER"},
+		{"reserved keyspace", TableSpec{Name: "select.users", PrimaryKey: id}, "reserved keyword SELECT"},
+		{"reserved key column", TableSpec{Name: "users", PrimaryKey: []Column{{"token", "uuid"}}}, "reserved"},
+		{"reserved column", TableSpec{Name: "users", PrimaryKey: id, Columns: []Column{{"From", "text"}}}, "reserved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -16,7 +16,13 @@ var (
 	repeatablePattern = regexp.MustCompile(`^R__(.+)\.(cql|sql)$`)
 )
 
+// ScanMigrationsDir lists the migrations in one directory, to be parsed
+// with the default parse options.
 func ScanMigrationsDir(dirPath string) ([]*Migration, error) {
+	return scanMigrationsDir(dirPath, DefaultParseOptions())
+}
+
+func scanMigrationsDir(dirPath string, opts ParseOptions) ([]*Migration, error) {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migrations directory %s: %w", dirPath, err)
@@ -42,11 +48,51 @@ func ScanMigrationsDir(dirPath string) ([]*Migration, error) {
 		if err != nil {
 			continue // skip non-migration files
 		}
+		mig.options = &opts
 
 		migrations = append(migrations, mig)
 	}
 
-	sort.Slice(migrations, func(i, j int) bool {
+	sortMigrations(migrations)
+
+	return migrations, nil
+}
+
+// ScanMigrationsDirs scans several directories and merges their migrations
+// into one ordered sequence. A version (or repeatable description) defined
+// more than once is an error, whether the duplicates share a directory or not.
+// The migrations are parsed with opts.
+func ScanMigrationsDirs(dirPaths []string, opts ParseOptions) ([]*Migration, error) {
+	var migrations []*Migration
+	seen := make(map[string]string)
+
+	for _, dir := range dirPaths {
+		scanned, err := scanMigrationsDir(dir, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, mig := range scanned {
+			key := string(mig.Type) + ":" + mig.Version
+			if mig.Type == TypeRepeatable {
+				key = string(mig.Type) + ":" + mig.Description
+			}
+			if prev, exists := seen[key]; exists {
+				return nil, fmt.Errorf("duplicate migration: %s conflicts with %s", mig.FilePath, prev)
+			}
+			seen[key] = mig.FilePath
+		}
+
+		migrations = append(migrations, scanned...)
+	}
+
+	sortMigrations(migrations)
+
+	return migrations, nil
+}
+
+func sortMigrations(migrations []*Migration) {
+	sort.SliceStable(migrations, func(i, j int) bool {
 		mi, mj := migrations[i], migrations[j]
 
 		// Versioned and Undo first, then Repeatable
@@ -67,10 +113,8 @@ func ScanMigrationsDir(dirPath string) ([]*Migration, error) {
 		}
 
 		// Same version: versioned before undo
-		return mi.Type == TypeVersioned
+		return mi.Type == TypeVersioned && mj.Type != TypeVersioned
 	})
-
-	return migrations, nil
 }
 
 func parseMigrationFilename(filename, fullPath string) (*Migration, error) {
@@ -111,39 +155,57 @@ func humanize(s string) string {
 	return strings.ReplaceAll(s, "_", " ")
 }
 
-func GetNextVersion(dirPath string) (int, error) {
-	entries, err := os.ReadDir(dirPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return 1, nil
-		}
-		return 0, err
-	}
-
+// GetNextVersion returns the version following the highest versioned or
+// undo migration found across all given directories.
+func GetNextVersion(dirPaths ...string) (int, error) {
 	maxVersion := 0
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		if matches := versionedPattern.FindStringSubmatch(entry.Name()); matches != nil {
-			v, err := strconv.Atoi(matches[1])
-			if err != nil {
+	for _, dirPath := range dirPaths {
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			if os.IsNotExist(err) {
 				continue
 			}
-			if v > maxVersion {
-				maxVersion = v
-			}
+			return 0, err
 		}
-		if matches := undoPattern.FindStringSubmatch(entry.Name()); matches != nil {
-			v, err := strconv.Atoi(matches[1])
-			if err != nil {
+
+		for _, entry := range entries {
+			if entry.IsDir() {
 				continue
 			}
-			if v > maxVersion {
-				maxVersion = v
+			if matches := versionedPattern.FindStringSubmatch(entry.Name()); matches != nil {
+				v, err := strconv.Atoi(matches[1])
+				if err != nil {
+					continue
+				}
+				if v > maxVersion {
+					maxVersion = v
+				}
+			}
+			if matches := undoPattern.FindStringSubmatch(entry.Name()); matches != nil {
+				v, err := strconv.Atoi(matches[1])
+				if err != nil {
+					continue
+				}
+				if v > maxVersion {
+					maxVersion = v
+				}
 			}
 		}
 	}
 
 	return maxVersion + 1, nil
 }
+
+// GetNextVersions returns the count versions create would assign to count
+// migrations created one after another.
+func GetNextVersions(count int, dirPaths ...string) ([]int, error) {
+	next, err := GetNextVersion(dirPaths...)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, count)
+	for i := range versions {
+		versions[i] = next + i
+	}
+	return versions, nil
+}
@@ -123,3 +123,47 @@ func TestGetNextVersion(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 4, v)
 }
+
+func TestGetNextVersions(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "V002__second.cql"), []byte("test"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "U005__fifth.cql"), []byte("test"), 0644))
+
+	versions, err := GetNextVersions(3, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []int{6, 7, 8}, versions)
+}
+
+func TestScanMigrationsDirs_Merge(t *testing.T) {
+	dirA := t.TempDir()
+	dirB := t.TempDir()
+	createTestMigration(t, dirA, "V001__users.cql", "CREATE TABLE users (id UUID PRIMARY KEY);")
+	createTestMigration(t, dirA, "V003__orders.cql", "CREATE TABLE orders (id UUID PRIMARY KEY);")
+	createTestMigration(t, dirB, "V002__products.cql", "CREATE TABLE products (id UUID PRIMARY KEY);")
+	createTestMigration(t, dirB, "R__views.cql", "SELECT now() FROM system.local;")
+
+	migrations, err := ScanMigrationsDirs([]string{dirA, dirB}, DefaultParseOptions())
+	require.NoError(t, err)
+	require.Len(t, migrations, 4)
+
+	assert.Equal(t, "001", migrations[0].Version)
+	assert.Equal(t, "002", migrations[1].Version)
+	assert.Equal(t, dirB, filepath.Dir(migrations[1].FilePath))
+	assert.Equal(t, "003", migrations[2].Version)
+	assert.Equal(t, TypeRepeatable, migrations[3].Type)
+
+	v, err := GetNextVersion(dirA, dirB)
+	require.NoError(t, err)
+	assert.Equal(t, 4, v)
+}
+
+func TestScanMigrationsDirs_DuplicateAcrossDirs(t *testing.T) {
+	dirA := t.TempDir()
+	dirB := t.TempDir()
+	createTestMigration(t, dirA, "V001__users.cql", "CREATE TABLE users (id UUID PRIMARY KEY);")
+	createTestMigration(t, dirB, "V001__products.cql", "CREATE TABLE products (id UUID PRIMARY KEY);")
+
+	_, err := ScanMigrationsDirs([]string{dirA, dirB}, DefaultParseOptions())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate migration")
+}
@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/schema"
+)
+
+// LoadScript parses a CQL file that is not a migration, such as a one-off
+// maintenance script, with the same statement splitting and directives.
+func LoadScript(path string, opts ParseOptions) (*Migration, error) {
+	name := filepath.Base(path)
+	mig := &Migration{Description: name, Filename: name, FilePath: path, options: &opts}
+	if err := readMigrationFile(mig); err != nil {
+		return nil, err
+	}
+	return mig, nil
+}
+
+// ExecuteScript runs the statements of a script loaded with LoadScript,
+// waiting for schema agreement after DDL as migrations do. Nothing is
+// recorded in schema_migrations; the run is only noted in the event log.
+func (e *Executor) ExecuteScript(script *Migration) error {
+	timeout, err := script.StatementTimeout(e.ctx.Config.StatementTimeout)
+	if err != nil {
+		return err
+	}
+
+	if e.ctx.DryRun {
+		return script.EachStatement(func(i int, stmt string) error {
+			e.ctx.Logger.Info().
+				Int("statement", i+1).
+				Str("cql", truncateStr(ApplyUsingTimeout(stmt, timeout), 120)).
+				Msg("[DRY RUN] Would execute")
+			return nil
+		})
+	}
+
+	start := time.Now()
+	e.ctx.Logger.Info().Str("file", script.Filename).Bool("streamed", script.Streamed).
+		Int("statements", len(script.Statements)).Msg("Executing script")
+
+	err = script.EachStatement(func(i int, stmt string) error {
+		return e.executeStatement(context.Background(), script, i, stmt, timeout)
+	})
+	if flushErr := e.flushAgreement(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		e.ctx.RecordEvent(schema.EventScriptFailed, "", script.Filename, err.Error())
+		return err
+	}
+
+	elapsed := time.Since(start)
+	e.ctx.RecordEvent(schema.EventScriptExecuted, "", script.Filename,
+		fmt.Sprintf("executed in %s", elapsed.Round(time.Millisecond)))
+	e.ctx.Logger.Info().Str("file", script.Filename).Dur("duration", elapsed).Msg("Script executed successfully")
+	return nil
+}
@@ -0,0 +1,28 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fix-orphaned-orders.cql")
+	content := `-- scylla-migrate:timeout 30s
+DELETE FROM orders WHERE id = 1;
+UPDATE orders SET status = 'done' WHERE id = 2;
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	script, err := LoadScript(path, DefaultParseOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "fix-orphaned-orders.cql", script.Filename)
+	assert.Len(t, script.Statements, 2)
+	assert.Equal(t, "30s", script.Directives[TimeoutDirective])
+
+	_, err = LoadScript(filepath.Join(t.TempDir(), "missing.cql"), DefaultParseOptions())
+	assert.Error(t, err)
+}
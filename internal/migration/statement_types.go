@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Statement types for allowed_statement_types, classified with IsDDL:
+// CREATE/ALTER/DROP are ddl, everything else (including TRUNCATE) is dml.
+const (
+	StatementTypeDDL = "ddl"
+	StatementTypeDML = "dml"
+)
+
+var StatementTypes = []string{StatementTypeDDL, StatementTypeDML}
+
+func StatementType(statement string) string {
+	if IsDDL(statement) {
+		return StatementTypeDDL
+	}
+	return StatementTypeDML
+}
+
+// DisallowedStatement is a statement whose type is not in
+// allowed_statement_types.
+type DisallowedStatement struct {
+	Migration *Migration
+	Index     int
+	Statement string
+	Type      string
+}
+
+// StatementTypeError reports every disallowed statement in a set of
+// migrations; Error names the first one.
+type StatementTypeError struct {
+	Allowed []string
+	Found   []DisallowedStatement
+}
+
+func (e *StatementTypeError) Error() string {
+	first := e.Found[0]
+	return fmt.Sprintf("%s statement %d is %s, but allowed_statement_types is [%s] (%d disallowed statement(s) in total): %s",
+		first.Migration.Filename, first.Index+1, first.Type, strings.Join(e.Allowed, ", "),
+		len(e.Found), truncateStr(first.Statement, 100))
+}
+
+// CheckStatementTypes rejects migrations containing a statement whose type is
+// not allowed. An empty allowed list permits everything.
+func CheckStatementTypes(migrations []*Migration, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	permitted := make(map[string]bool, len(allowed))
+	for _, t := range allowed {
+		permitted[strings.ToLower(t)] = true
+	}
+
+	var found []DisallowedStatement
+	for _, mig := range migrations {
+		err := mig.EachStatement(func(i int, stmt string) error {
+			if t := StatementType(stmt); !permitted[t] {
+				found = append(found, DisallowedStatement{Migration: mig, Index: i, Statement: stmt, Type: t})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(found) > 0 {
+		return &StatementTypeError{Allowed: allowed, Found: found}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckStatementTypes(t *testing.T) {
+	migs := []*Migration{
+		{Version: "001", Filename: "V001__backfill.cql", Statements: []string{
+			"UPDATE users SET tier = 'free' WHERE id = 1",
+			"INSERT INTO flags (name) VALUES ('x')",
+		}},
+		{Version: "002", Filename: "V002__add_table.cql", Statements: []string{
+			"INSERT INTO flags (name) VALUES ('y')",
+			"CREATE TABLE audit (id UUID PRIMARY KEY)",
+		}},
+	}
+
+	assert.NoError(t, CheckStatementTypes(migs, nil), "no restriction by default")
+	assert.NoError(t, CheckStatementTypes(migs, []string{"ddl", "dml"}))
+	assert.NoError(t, CheckStatementTypes(migs[:1], []string{"dml"}))
+
+	err := CheckStatementTypes(migs, []string{"DML"})
+	require.Error(t, err)
+	var typeErr *StatementTypeError
+	require.True(t, errors.As(err, &typeErr))
+	require.Len(t, typeErr.Found, 1)
+	assert.Equal(t, "002", typeErr.Found[0].Migration.Version)
+	assert.Equal(t, 1, typeErr.Found[0].Index)
+	assert.Contains(t, err.Error(), "V002__add_table.cql statement 2 is ddl")
+
+	err = CheckStatementTypes(migs, []string{"ddl"})
+	require.True(t, errors.As(err, &typeErr))
+	assert.Len(t, typeErr.Found, 3)
+}
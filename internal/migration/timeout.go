@@ -0,0 +1,140 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimeoutDirective sets a server-side timeout for the DML statements of a
+// migration, e.g. "-- scylla-migrate:timeout 10m". "0" disables a timeout
+// configured globally through statement_timeout.
+const TimeoutDirective = "timeout"
+
+var (
+	usingPattern    = regexp.MustCompile(`(?i)\bUSING\s+`)
+	timeoutPattern  = regexp.MustCompile(`(?is)\bUSING\b.*\bTIMEOUT\b`)
+	updateTarget    = regexp.MustCompile(`(?is)^UPDATE\s+(?:"[^"]*"|\w+)(?:\s*\.\s*(?:"[^"]*"|\w+))?`)
+	deleteTarget    = regexp.MustCompile(`(?is)^DELETE\b.*?\bFROM\s+(?:"[^"]*"|\w+)(?:\s*\.\s*(?:"[^"]*"|\w+))?`)
+	leadingUsingSep = regexp.MustCompile(`(?i)^\s+USING\s+`)
+)
+
+// StatementTimeout returns the USING TIMEOUT duration for this migration:
+// the timeout directive if present, otherwise def.
+func (m *Migration) StatementTimeout(def time.Duration) (time.Duration, error) {
+	value, ok := m.Directives[TimeoutDirective]
+	if !ok {
+		return def, nil
+	}
+	if value == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s directive %q in %s: must be a positive duration such as 30s or 10m", TimeoutDirective, value, m.Filename)
+	}
+	return d, nil
+}
+
+// FormatCQLDuration renders d as a CQL duration literal, which only accepts
+// integer amounts per unit: 90s -> "1m30s", 1.5s -> "1s500ms".
+func FormatCQLDuration(d time.Duration) string {
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+		{"ms", time.Millisecond},
+		{"us", time.Microsecond},
+		{"ns", time.Nanosecond},
+	}
+
+	var b strings.Builder
+	for _, u := range units {
+		if n := d / u.size; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, u.suffix)
+			d -= n * u.size
+		}
+	}
+	if b.Len() == 0 {
+		return "0s"
+	}
+	return b.String()
+}
+
+// ApplyUsingTimeout adds "USING TIMEOUT <d>" to SELECT, INSERT, UPDATE and
+// DELETE statements. Other statements (DDL, BATCH) and statements that
+// already set a timeout are returned unchanged. This is ScyllaDB-specific
+// CQL; Cassandra rejects it.
+func ApplyUsingTimeout(stmt string, d time.Duration) string {
+	if d <= 0 {
+		return stmt
+	}
+
+	// Match against a copy with literal contents blanked out, so keywords
+	// inside strings or quoted names are ignored; offsets stay the same
+	masked := maskLiterals(stmt)
+	if timeoutPattern.MatchString(masked) {
+		return stmt
+	}
+
+	clause := "TIMEOUT " + FormatCQLDuration(d)
+	verb := strings.ToUpper(strings.Fields(masked + " ")[0])
+
+	switch verb {
+	case "SELECT":
+		return stmt + " USING " + clause
+	case "INSERT":
+		// USING comes last in INSERT, after VALUES/JSON and IF NOT EXISTS
+		if loc := usingPattern.FindStringIndex(masked); loc != nil {
+			return stmt[:loc[1]] + clause + " AND " + stmt[loc[1]:]
+		}
+		return stmt + " USING " + clause
+	case "UPDATE":
+		return insertAfterTarget(stmt, masked, updateTarget, clause)
+	case "DELETE":
+		return insertAfterTarget(stmt, masked, deleteTarget, clause)
+	}
+	return stmt
+}
+
+// insertAfterTarget places the clause right after the table name, merging
+// with an existing USING clause there.
+func insertAfterTarget(stmt, masked string, target *regexp.Regexp, clause string) string {
+	loc := target.FindStringIndex(masked)
+	if loc == nil {
+		return stmt
+	}
+	end := loc[1]
+	if using := leadingUsingSep.FindStringIndex(masked[end:]); using != nil {
+		at := end + using[1]
+		return stmt[:at] + clause + " AND " + stmt[at:]
+	}
+	return stmt[:end] + " USING " + clause + stmt[end:]
+}
+
+// maskLiterals replaces the contents of string literals and quoted
+// identifiers with spaces, keeping byte offsets intact.
+func maskLiterals(stmt string) string {
+	b := []byte(stmt)
+	var quote byte
+	for i := 0; i < len(b); i++ {
+		switch {
+		case quote == 0 && (b[i] == '\'' || b[i] == '"'):
+			quote = b[i]
+		case quote != 0 && b[i] == quote:
+			if i+1 < len(b) && b[i+1] == quote {
+				b[i], b[i+1] = ' ', ' '
+				i++
+				continue
+			}
+			quote = 0
+		case quote != 0:
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCQLDuration(t *testing.T) {
+	assert.Equal(t, "30s", FormatCQLDuration(30*time.Second))
+	assert.Equal(t, "1m30s", FormatCQLDuration(90*time.Second))
+	assert.Equal(t, "1s500ms", FormatCQLDuration(1500*time.Millisecond))
+	assert.Equal(t, "2h", FormatCQLDuration(2*time.Hour))
+}
+
+func TestApplyUsingTimeout(t *testing.T) {
+	d := 10 * time.Minute
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"SELECT * FROM ks.t WHERE id = 1", "SELECT * FROM ks.t WHERE id = 1 USING TIMEOUT 10m"},
+		{"INSERT INTO ks.t (id, v) VALUES (1, 'using x')", "INSERT INTO ks.t (id, v) VALUES (1, 'using x') USING TIMEOUT 10m"},
+		{"INSERT INTO t (id) VALUES (1) IF NOT EXISTS USING TTL 60", "INSERT INTO t (id) VALUES (1) IF NOT EXISTS USING TIMEOUT 10m AND TTL 60"},
+		{"UPDATE ks.t SET v = 1 WHERE id = 1", "UPDATE ks.t USING TIMEOUT 10m SET v = 1 WHERE id = 1"},
+		{"UPDATE \"My Table\" USING TTL 5 SET v = 1 WHERE id = 1", "UPDATE \"My Table\" USING TIMEOUT 10m AND TTL 5 SET v = 1 WHERE id = 1"},
+		{"DELETE v FROM ks.t WHERE id = 1", "DELETE v FROM ks.t USING TIMEOUT 10m WHERE id = 1"},
+		{"DELETE FROM t USING TIMESTAMP 123 WHERE id = 1", "DELETE FROM t USING TIMEOUT 10m AND TIMESTAMP 123 WHERE id = 1"},
+		{"CREATE TABLE t (id int PRIMARY KEY)", "CREATE TABLE t (id int PRIMARY KEY)"},
+		{"BEGIN BATCH INSERT INTO t (id) VALUES (1); APPLY BATCH", "BEGIN BATCH INSERT INTO t (id) VALUES (1); APPLY BATCH"},
+		{"SELECT * FROM t USING TIMEOUT 5s", "SELECT * FROM t USING TIMEOUT 5s"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ApplyUsingTimeout(tt.in, d), tt.in)
+	}
+
+	assert.Equal(t, "SELECT * FROM t", ApplyUsingTimeout("SELECT * FROM t", 0))
+}
+
+func TestMigration_StatementTimeout(t *testing.T) {
+	mig := &Migration{Filename: "V001__a.cql"}
+	d, err := mig.StatementTimeout(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, d)
+
+	mig.Directives = map[string]string{"timeout": "10m"}
+	d, err = mig.StatementTimeout(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, d)
+
+	mig.Directives["timeout"] = "0"
+	d, err = mig.StatementTimeout(time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	mig.Directives["timeout"] = "soon"
+	_, err = mig.StatementTimeout(time.Minute)
+	assert.Error(t, err)
+}
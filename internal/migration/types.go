@@ -1,6 +1,10 @@
 package migration
 
-import "strconv"
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
 
 type MigrationType string
 
@@ -19,6 +23,43 @@ type Migration struct {
 	Checksum    string
 	Statements  []string
 	RawContent  string
+	Directives  map[string]string
+	// Streamed is set for large files parsed without loading their
+	// statements; use EachStatement to read them.
+	Streamed bool
+	// Args holds positional bind values per statement, loaded from the
+	// companion .args.json file.
+	Args [][]interface{}
+
+	// options are the parse options the migration was scanned with; nil
+	// means the defaults
+	options *ParseOptions
+}
+
+// EachStatement calls fn for every statement in order. Streamed migrations
+// are read from FilePath as they are iterated.
+func (m *Migration) EachStatement(fn func(i int, stmt string) error) error {
+	if !m.Streamed {
+		for i, stmt := range m.Statements {
+			if err := fn(i, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f, err := os.Open(m.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open migration file %s: %w", m.FilePath, err)
+	}
+	defer f.Close()
+
+	i := 0
+	return streamStatements(f, m.parseOptions().StatementSeparator, func(stmt string) error {
+		err := fn(i, stmt)
+		i++
+		return err
+	})
 }
 
 // CompareVersions compares two version strings numerically.
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+const redacted = "[REDACTED]"
+
+type Payload struct {
+	Event           string   `json:"event"`
+	Success         bool     `json:"success"`
+	Keyspace        string   `json:"keyspace"`
+	Host            string   `json:"host"`
+	AppliedVersions []string `json:"applied_versions"`
+	DurationMS      int64    `json:"duration_ms"`
+	Error           string   `json:"error,omitempty"`
+	Timestamp       string   `json:"timestamp"`
+}
+
+type Notifier struct {
+	url     string
+	secrets []string
+	client  *http.Client
+	Logger  zerolog.Logger
+}
+
+func NewNotifier(cfg *config.Config, logger zerolog.Logger) *Notifier {
+	// Webhook URLs often embed a token (e.g. Slack), so treat them as secret too
+	secrets := []string{cfg.Notify.WebhookURL}
+	if cfg.Password != "" {
+		secrets = append(secrets, cfg.Password)
+	}
+
+	return &Notifier{
+		url:     cfg.Notify.WebhookURL,
+		secrets: secrets,
+		client:  &http.Client{Timeout: cfg.Notify.Timeout},
+		Logger:  logger,
+	}
+}
+
+func (n *Notifier) Enabled() bool {
+	return n.url != ""
+}
+
+// NewPayload builds a notification payload for a completed run. The host is
+// the machine that ran the command, not a cluster node.
+func NewPayload(event, keyspace string, versions []string, duration time.Duration, runErr error) Payload {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	p := Payload{
+		Event:           event,
+		Success:         runErr == nil,
+		Keyspace:        keyspace,
+		Host:            hostname,
+		AppliedVersions: versions,
+		DurationMS:      duration.Milliseconds(),
+		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+	}
+	if p.AppliedVersions == nil {
+		p.AppliedVersions = []string{}
+	}
+	if runErr != nil {
+		p.Error = runErr.Error()
+	}
+	return p
+}
+
+// Send posts the payload to the configured webhook. It is best-effort: any
+// failure is logged and never returned, so a broken webhook cannot fail a run.
+func (n *Notifier) Send(p Payload) {
+	if !n.Enabled() {
+		return
+	}
+
+	p.Error = n.redact(p.Error)
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		n.Logger.Warn().Err(err).Msg("Failed to encode notification payload")
+		return
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		n.Logger.Warn().Str("error", n.redact(err.Error())).Msg("Failed to send notification")
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		n.Logger.Warn().Int("status", resp.StatusCode).Msg("Notification webhook returned non-success status")
+		return
+	}
+
+	n.Logger.Debug().Int("status", resp.StatusCode).Msg("Notification sent")
+}
+
+func (n *Notifier) redact(s string) string {
+	for _, secret := range n.secrets {
+		if secret == "" {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+)
+
+func TestNotifier_Send(t *testing.T) {
+	received := make(chan Payload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		received <- p
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Password: "s3cret",
+		Notify:   config.NotifyConfig{WebhookURL: server.URL, Timeout: time.Second},
+	}
+	n := NewNotifier(cfg, zerolog.Nop())
+
+	runErr := errors.New("auth failed for password s3cret")
+	n.Send(NewPayload("migrate", "app", []string{"001", "002"}, 1500*time.Millisecond, runErr))
+
+	p := <-received
+	assert.Equal(t, "migrate", p.Event)
+	assert.False(t, p.Success)
+	assert.Equal(t, "app", p.Keyspace)
+	assert.Equal(t, []string{"001", "002"}, p.AppliedVersions)
+	assert.Equal(t, int64(1500), p.DurationMS)
+	assert.NotContains(t, p.Error, "s3cret")
+	assert.Contains(t, p.Error, redacted)
+}
+
+func TestNotifier_SendFailureIsBestEffort(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Notify: config.NotifyConfig{WebhookURL: server.URL, Timeout: 50 * time.Millisecond},
+	}
+	n := NewNotifier(cfg, zerolog.Nop())
+
+	// Must return without panicking or blocking past the timeout
+	start := time.Now()
+	n.Send(NewPayload("migrate", "app", nil, 0, nil))
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestNotifier_Disabled(t *testing.T) {
+	n := NewNotifier(&config.Config{}, zerolog.Nop())
+	assert.False(t, n.Enabled())
+	n.Send(NewPayload("migrate", "app", nil, 0, nil))
+}
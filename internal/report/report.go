@@ -0,0 +1,146 @@
+// Package report builds the execution report written by 'migrate --report':
+// a single artifact describing what a run did, for change tickets.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Report struct {
+	StartedAt           time.Time   `json:"started_at"`
+	Operator            string      `json:"operator"`
+	Keyspace            string      `json:"keyspace"`
+	Cluster             string      `json:"cluster"`
+	SchemaVersionBefore string      `json:"schema_version_before"`
+	SchemaVersionAfter  string      `json:"schema_version_after"`
+	DryRun              bool        `json:"dry_run"`
+	Success             bool        `json:"success"`
+	DurationMS          int64       `json:"duration_ms"`
+	Migrations          []Migration `json:"migrations"`
+	Failure             *Failure    `json:"failure,omitempty"`
+	Warnings            []string    `json:"warnings"`
+}
+
+// Migration is one migration applied by the run.
+type Migration struct {
+	Version     string `json:"version"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Statements  int    `json:"statements"`
+	DurationMS  int64  `json:"duration_ms"`
+}
+
+// Failure describes why the run stopped. Statement is 1-based and zero when
+// the failure was not caused by a specific statement.
+type Failure struct {
+	Version   string `json:"version,omitempty"`
+	File      string `json:"file,omitempty"`
+	Statement int    `json:"statement,omitempty"`
+	CQL       string `json:"cql,omitempty"`
+	Error     string `json:"error"`
+}
+
+// Warn adds a warning; it is a no-op on a nil report so callers don't need
+// to check whether a report was requested.
+func (r *Report) Warn(msg string) {
+	if r != nil {
+		r.Warnings = append(r.Warnings, msg)
+	}
+}
+
+// IsMarkdown reports whether path asks for a Markdown report (.md or
+// .markdown); every other path gets JSON.
+func IsMarkdown(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".md" || ext == ".markdown"
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := *r
+	if out.Migrations == nil {
+		out.Migrations = []Migration{}
+	}
+	if out.Warnings == nil {
+		out.Warnings = []string{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	outcome := "Succeeded"
+	if !r.Success {
+		outcome = "FAILED"
+	}
+	if r.DryRun {
+		outcome += " (dry run)"
+	}
+
+	fmt.Fprintf(&b, "# Migration report: %s\n\n", r.Keyspace)
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Outcome | %s |\n", outcome)
+	fmt.Fprintf(&b, "| Started | %s |\n", r.StartedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "| Operator | %s |\n", orUnknown(r.Operator))
+	fmt.Fprintf(&b, "| Cluster | %s |\n", orUnknown(r.Cluster))
+	fmt.Fprintf(&b, "| Schema version before | %s |\n", orUnknown(r.SchemaVersionBefore))
+	fmt.Fprintf(&b, "| Schema version after | %s |\n", orUnknown(r.SchemaVersionAfter))
+	fmt.Fprintf(&b, "| Duration | %s |\n", time.Duration(r.DurationMS)*time.Millisecond)
+
+	fmt.Fprintf(&b, "\n## Applied migrations (%d)\n\n", len(r.Migrations))
+	if len(r.Migrations) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString("| Version | Type | Description | Statements | Duration |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+		for _, m := range r.Migrations {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d | %s |\n", m.Version, m.Type, escapeCell(m.Description),
+				m.Statements, time.Duration(m.DurationMS)*time.Millisecond)
+		}
+	}
+
+	if f := r.Failure; f != nil {
+		b.WriteString("\n## Failure\n\n")
+		if f.Version != "" {
+			fmt.Fprintf(&b, "Migration %s (%s)", f.Version, f.File)
+			if f.Statement > 0 {
+				fmt.Fprintf(&b, ", statement %d", f.Statement)
+			}
+			b.WriteString(":\n\n")
+		}
+		if f.CQL != "" {
+			fmt.Fprintf(&b, "```sql\n%s\n```\n\n", f.CQL)
+		}
+		fmt.Fprintf(&b, "```\n%s\n```\n", f.Error)
+	}
+
+	if len(r.Warnings) > 0 {
+		b.WriteString("\n## Warnings\n\n")
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// orUnknown fills cells for values that could not be read, e.g. when the
+// cluster was unreachable.
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
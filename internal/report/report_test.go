@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_WriteJSON(t *testing.T) {
+	rep := &Report{Keyspace: "app", Success: true}
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteJSON(&buf))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, []interface{}{}, decoded["migrations"])
+	assert.Equal(t, []interface{}{}, decoded["warnings"])
+	assert.NotContains(t, decoded, "failure")
+}
+
+func TestReport_WriteMarkdown(t *testing.T) {
+	rep := &Report{
+		StartedAt:           time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Operator:            "ops@runner",
+		Keyspace:            "app",
+		Cluster:             "prod",
+		SchemaVersionBefore: "aaa",
+		SchemaVersionAfter:  "bbb",
+		DurationMS:          1500,
+		Migrations: []Migration{
+			{Version: "001", Type: "versioned", Description: "create users | accounts", Statements: 2, DurationMS: 800},
+		},
+		Failure: &Failure{Version: "002", File: "V002__index.cql", Statement: 3,
+			CQL: "CREATE INDEX ON app.users (email)", Error: "failed to execute statement 3"},
+	}
+	rep.Warn("V000 ignored")
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteMarkdown(&buf))
+	md := buf.String()
+
+	assert.Contains(t, md, "| Outcome | FAILED |")
+	assert.Contains(t, md, "| Started | 2024-05-01T12:00:00Z |")
+	assert.Contains(t, md, "| Duration | 1.5s |")
+	assert.Contains(t, md, "| 001 | versioned | create users \\| accounts | 2 | 800ms |")
+	assert.Contains(t, md, "Migration 002 (V002__index.cql), statement 3:")
+	assert.Contains(t, md, "```sql\nCREATE INDEX ON app.users (email)\n```")
+	assert.Contains(t, md, "- V000 ignored")
+
+	var none *Report
+	none.Warn("ignored on a nil report")
+}
+
+func TestIsMarkdown(t *testing.T) {
+	assert.True(t, IsMarkdown("out/report.md"))
+	assert.True(t, IsMarkdown("REPORT.Markdown"))
+	assert.False(t, IsMarkdown("report.json"))
+	assert.False(t, IsMarkdown("report"))
+}
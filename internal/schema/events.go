@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// Event types written to schema_events.
+const (
+	EventMigrationStarted = "migration_started"
+	EventMigrationApplied = "migration_applied"
+	EventMigrationFailed  = "migration_failed"
+	EventRolledBack       = "rolled_back"
+	EventMigrationSkipped = "migration_skipped"
+	EventMetadataPruned   = "metadata_pruned"
+	EventChecksumUpdated  = "checksum_updated"
+	EventScriptExecuted   = "script_executed"
+	EventScriptFailed     = "script_failed"
+)
+
+// eventBucketFormat partitions schema_events by UTC day so that recent events
+// can be read without scanning the whole table.
+const eventBucketFormat = "2006-01-02"
+
+// EventTTL is how long schema_events rows are kept (the table's
+// default_time_to_live).
+const EventTTL = 30 * 24 * time.Hour
+
+type Event struct {
+	ID          gocql.UUID
+	Type        string
+	Version     string
+	Description string
+	Actor       string
+	Message     string
+}
+
+func (e Event) Time() time.Time {
+	return e.ID.Time()
+}
+
+// RecordEvent appends an event to schema_events. Callers treat failures as
+// non-fatal: the event log is an observability aid, not part of the
+// migration state.
+func (m *MetadataManager) RecordEvent(e Event) error {
+	if e.ID == (gocql.UUID{}) {
+		e.ID = gocql.TimeUUID()
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO %s.schema_events
+		 (bucket, event_time, event_type, version, description, actor, message)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		m.keyspace,
+	)
+
+	return m.session.Execute(query,
+		e.ID.Time().UTC().Format(eventBucketFormat),
+		e.ID,
+		e.Type,
+		e.Version,
+		e.Description,
+		e.Actor,
+		e.Message,
+	)
+}
+
+// eventBuckets returns the day buckets that can hold events after the given
+// time, up to now. Buckets older than EventTTL are skipped since their rows
+// have expired.
+func eventBuckets(after, now time.Time) []string {
+	today := now.UTC().Truncate(24 * time.Hour)
+	day := after.UTC().Truncate(24 * time.Hour)
+	if oldest := now.Add(-EventTTL).UTC().Truncate(24 * time.Hour); day.Before(oldest) {
+		day = oldest
+	}
+	var buckets []string
+	for ; !day.After(today); day = day.Add(24 * time.Hour) {
+		buckets = append(buckets, day.Format(eventBucketFormat))
+	}
+	return buckets
+}
+
+// GetEventsAfter returns events recorded after the given time UUID, oldest
+// first. Only the day buckets from after's day on are read. Use
+// gocql.MinTimeUUID to start from a point in time.
+func (m *MetadataManager) GetEventsAfter(after gocql.UUID) ([]Event, error) {
+	query := fmt.Sprintf(
+		`SELECT event_time, event_type, version, description, actor, message
+		 FROM %s.schema_events WHERE bucket = ? AND event_time > ?`,
+		m.keyspace,
+	)
+
+	var events []Event
+	for _, bucket := range eventBuckets(after.Time(), time.Now()) {
+		iter := m.session.QueryWithSpeculation(query, bucket, after).Iter()
+
+		var e Event
+		for iter.Scan(&e.ID, &e.Type, &e.Version, &e.Description, &e.Actor, &e.Message) {
+			events = append(events, e)
+			e = Event{}
+		}
+		if err := iter.Close(); err != nil {
+			return nil, fmt.Errorf("failed to query events: %w", err)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID.Timestamp() < events[j].ID.Timestamp()
+	})
+
+	return events, nil
+}
+
+// RecordedVersions returns the versions that have an event of the given type
+// still in the event log, i.e. recorded within EventTTL.
+func (m *MetadataManager) RecordedVersions(eventType string) (map[string]bool, error) {
+	events, err := m.GetEventsAfter(gocql.MinTimeUUID(time.Now().Add(-EventTTL)))
+	if err != nil {
+		return nil, err
+	}
+	return eventVersions(events, eventType), nil
+}
+
+func eventVersions(events []Event, eventType string) map[string]bool {
+	versions := make(map[string]bool)
+	for _, e := range events {
+		if e.Type == eventType {
+			versions[e.Version] = true
+		}
+	}
+	return versions
+}
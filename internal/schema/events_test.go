@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBuckets(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{"2024-03-08", "2024-03-09", "2024-03-10"},
+		eventBuckets(now.Add(-50*time.Hour), now))
+	assert.Equal(t, []string{"2024-03-10"}, eventBuckets(now.Add(-time.Minute), now))
+
+	// Buckets past the TTL have expired and are not read
+	buckets := eventBuckets(now.AddDate(-1, 0, 0), now)
+	assert.Len(t, buckets, 31)
+	assert.Equal(t, "2024-02-09", buckets[0])
+}
+
+func TestEventVersions(t *testing.T) {
+	events := []Event{
+		{Type: EventMigrationSkipped, Version: "002"},
+		{Type: EventMigrationApplied, Version: "003"},
+		{Type: EventMigrationSkipped, Version: "002"},
+	}
+	assert.Equal(t, map[string]bool{"002": true}, eventVersions(events, EventMigrationSkipped))
+	assert.Empty(t, eventVersions(nil, EventMigrationSkipped))
+}
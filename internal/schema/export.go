@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportColumns is the column order of exported CSV files. It matches the
+// schema_migrations definition so the file can be loaded with:
+//
+//	COPY <ks>.schema_migrations (version, description, ...) FROM 'file.csv' WITH HEADER = true
+var ExportColumns = []string{
+	"version", "description", "type", "script", "checksum",
+	"applied_by", "applied_at", "execution_time_ms", "success",
+}
+
+// cqlshTimestampFormat is the format cqlsh COPY uses for TIMESTAMP columns.
+const cqlshTimestampFormat = "2006-01-02 15:04:05.000-0700"
+
+type exportRow struct {
+	Version         string    `json:"version"`
+	Description     string    `json:"description"`
+	Type            string    `json:"type"`
+	Script          string    `json:"script"`
+	Checksum        string    `json:"checksum"`
+	AppliedBy       string    `json:"applied_by"`
+	AppliedAt       time.Time `json:"applied_at"`
+	ExecutionTimeMS int       `json:"execution_time_ms"`
+	Success         bool      `json:"success"`
+}
+
+func exportRows(applied []AppliedMigration) []exportRow {
+	rows := make([]exportRow, 0, len(applied))
+	for _, a := range applied {
+		rows = append(rows, exportRow(a))
+	}
+	return rows
+}
+
+func ExportJSON(w io.Writer, applied []AppliedMigration) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exportRows(applied))
+}
+
+type LockRow struct {
+	LockID    string    `json:"lock_id"`
+	LockedBy  string    `json:"locked_by"`
+	LockedAt  time.Time `json:"locked_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Backup is a snapshot of the metadata tables taken before a destructive
+// operation. schema_migrations rows use the same shape as ExportJSON.
+type Backup struct {
+	CreatedAt        time.Time   `json:"created_at"`
+	MetadataKeyspace string      `json:"metadata_keyspace"`
+	SchemaMigrations []exportRow `json:"schema_migrations"`
+	SchemaLock       []LockRow   `json:"schema_lock"`
+}
+
+func NewBackup(keyspace string, applied []AppliedMigration, locks []LockRow) Backup {
+	if locks == nil {
+		locks = []LockRow{}
+	}
+	return Backup{
+		CreatedAt:        time.Now().UTC(),
+		MetadataKeyspace: keyspace,
+		SchemaMigrations: exportRows(applied),
+		SchemaLock:       locks,
+	}
+}
+
+func (b Backup) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// ExportCSV writes applied migrations as CSV with a header row. Fields
+// containing commas, quotes or newlines are quoted; timestamps are written in
+// UTC in the format cqlsh COPY FROM accepts.
+func ExportCSV(w io.Writer, applied []AppliedMigration) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ExportColumns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, a := range applied {
+		record := []string{
+			a.Version,
+			a.Description,
+			a.Type,
+			a.Script,
+			a.Checksum,
+			a.AppliedBy,
+			a.AppliedAt.UTC().Format(cqlshTimestampFormat),
+			strconv.Itoa(a.ExecutionTimeMS),
+			cqlshBool(a.Success),
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row for version %s: %w", a.Version, err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func cqlshBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+// ImportJSON reads rows written by ExportJSON.
+func ImportJSON(r io.Reader) ([]AppliedMigration, error) {
+	var rows []exportRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("invalid metadata export: %w", err)
+	}
+
+	applied := make([]AppliedMigration, 0, len(rows))
+	for _, row := range rows {
+		applied = append(applied, AppliedMigration(row))
+	}
+	return applied, nil
+}
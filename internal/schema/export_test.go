@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testApplied() []AppliedMigration {
+	return []AppliedMigration{
+		{
+			Version:         "001",
+			Description:     "create users, orders",
+			Type:            "versioned",
+			Script:          "V001__create_users,_orders.cql",
+			Checksum:        "abc123",
+			AppliedBy:       "host-1",
+			AppliedAt:       time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+			ExecutionTimeMS: 42,
+			Success:         true,
+		},
+		{
+			Version:     "002",
+			Description: `say "hi"`,
+			Type:        "versioned",
+			AppliedAt:   time.Date(2024, 3, 2, 8, 0, 0, 0, time.FixedZone("CET", 3600)),
+		},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportCSV(&buf, testApplied()))
+
+	assert.Contains(t, buf.String(), `"create users, orders"`)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, ExportColumns, records[0])
+	assert.Equal(t, []string{
+		"001", "create users, orders", "versioned", "V001__create_users,_orders.cql", "abc123",
+		"host-1", "2024-03-01 12:30:00.000+0000", "42", "True",
+	}, records[1])
+	assert.Equal(t, `say "hi"`, records[2][1])
+	assert.Equal(t, "2024-03-02 07:00:00.000+0000", records[2][6])
+	assert.Equal(t, "False", records[2][8])
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportJSON(&buf, testApplied()))
+
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rows))
+	require.Len(t, rows, 2)
+	assert.Equal(t, "001", rows[0]["version"])
+	assert.Equal(t, true, rows[0]["success"])
+
+	imported, err := ImportJSON(&buf)
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+	assert.Equal(t, testApplied()[0], imported[0])
+	assert.True(t, testApplied()[1].AppliedAt.Equal(imported[1].AppliedAt))
+
+	_, err = ImportJSON(bytes.NewBufferString("{}"))
+	assert.Error(t, err)
+}
+
+func TestBackup_WriteJSON(t *testing.T) {
+	backup := NewBackup("scylla_migrate", testApplied(), nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, backup.WriteJSON(&buf))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "scylla_migrate", decoded["metadata_keyspace"])
+	assert.Len(t, decoded["schema_migrations"], 2)
+	assert.Equal(t, []any{}, decoded["schema_lock"])
+}
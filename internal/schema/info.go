@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+)
+
+// MetadataSchemaVersion is the version of scylla-migrate's own metadata
+// tables this build writes. Bump it together with a new entry in
+// metadataSchemaSteps whenever a metadata table or column is added.
+const MetadataSchemaVersion = 3
+
+// metadataSchemaKey is the schema_info row holding the metadata version.
+const metadataSchemaKey = "metadata"
+
+// metadataSchemaStep upgrades the metadata tables from version-1 to version.
+// Statements must be safe to run again: a run that failed after a step's
+// statements but before recording the version repeats the step.
+type metadataSchemaStep struct {
+	version     int
+	description string
+	statements  func(keyspace string) []string
+}
+
+var metadataSchemaSteps = []metadataSchemaStep{
+	// The tables created unconditionally by InitializeMetadata. Clusters
+	// initialized before schema_info existed have no row and start here.
+	{version: 1, description: "baseline metadata tables"},
+	{version: 2, description: "schema_migrations.content for store_script_content", statements: func(ks string) []string {
+		return []string{fmt.Sprintf(`ALTER TABLE %s.schema_migrations ADD content TEXT`, ks)}
+	}},
+	{version: 3, description: "schema_repeatables content index", statements: func(ks string) []string {
+		return []string{fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s.schema_repeatables (
+				name TEXT,
+				checksum TEXT,
+				status TEXT,
+				applied_by TEXT,
+				applied_at TIMESTAMP,
+				execution_time_ms INT,
+				PRIMARY KEY ((name), checksum)
+			) WITH comment = 'scylla-migrate: every applied content version of repeatable migrations'`, ks)}
+	}},
+}
+
+// pendingMetadataSteps returns the steps needed to bring metadata at version
+// current up to MetadataSchemaVersion, in order.
+func pendingMetadataSteps(current int) ([]metadataSchemaStep, error) {
+	if current > MetadataSchemaVersion {
+		return nil, fmt.Errorf("metadata schema version %d is newer than this scylla-migrate supports (%d) — upgrade scylla-migrate",
+			current, MetadataSchemaVersion)
+	}
+	var steps []metadataSchemaStep
+	for _, step := range metadataSchemaSteps {
+		if step.version > current {
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+// ReadMetadataSchemaVersion returns the metadata schema version recorded in
+// schema_info, or 0 when none has been recorded yet.
+func ReadMetadataSchemaVersion(session *driver.Session, keyspace string) (int, error) {
+	var version int
+	err := session.QueryWithSpeculation(
+		fmt.Sprintf(`SELECT version FROM %s.schema_info WHERE key = ?`, keyspace), metadataSchemaKey,
+	).Scan(&version)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read metadata schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrateMetadataSchema creates schema_info if needed and applies the
+// metadata upgrade steps the cluster has not seen yet.
+func migrateMetadataSchema(session *driver.Session, cfg *config.Config, logger zerolog.Logger) error {
+	keyspace := cfg.MetadataKeyspace
+
+	createInfo := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s.schema_info (
+			key TEXT PRIMARY KEY,
+			version INT,
+			updated_by TEXT,
+			updated_at TIMESTAMP
+		) WITH comment = 'scylla-migrate: version of the metadata tables'`,
+		keyspace,
+	)
+	if err := session.Execute(createInfo); err != nil {
+		return fmt.Errorf("failed to create schema_info table: %w", err)
+	}
+	if err := session.WaitForSchemaAgreement(cfg.SchemaAgreementTimeout); err != nil {
+		return fmt.Errorf("schema agreement timeout after creating schema_info table: %w", err)
+	}
+
+	current, err := ReadMetadataSchemaVersion(session, keyspace)
+	if err != nil {
+		return err
+	}
+	steps, err := pendingMetadataSteps(current)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		logger.Info().Int("from", current).Int("to", step.version).Str("step", step.description).
+			Msg("Upgrading metadata schema")
+		if step.statements != nil {
+			for _, stmt := range step.statements(keyspace) {
+				if err := session.Execute(stmt); err != nil && !alreadyApplied(err) {
+					return fmt.Errorf("metadata schema upgrade to version %d failed: %w", step.version, err)
+				}
+			}
+			if err := session.WaitForSchemaAgreement(cfg.SchemaAgreementTimeout); err != nil {
+				return fmt.Errorf("schema agreement timeout after metadata schema upgrade to version %d: %w", step.version, err)
+			}
+		}
+		if err := writeMetadataSchemaVersion(session, keyspace, step.version); err != nil {
+			return err
+		}
+		current = step.version
+	}
+	return nil
+}
+
+func writeMetadataSchemaVersion(session *driver.Session, keyspace string, version int) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	err = session.Execute(
+		fmt.Sprintf(`INSERT INTO %s.schema_info (key, version, updated_by, updated_at) VALUES (?, ?, ?, ?)`, keyspace),
+		metadataSchemaKey, version, hostname, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record metadata schema version %d: %w", version, err)
+	}
+	return nil
+}
+
+// alreadyApplied reports whether an upgrade statement failed only because an
+// earlier, interrupted run already made the change (e.g. ALTER TABLE ADD of
+// an existing column).
+func alreadyApplied(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already exist") || strings.Contains(msg, "conflicts with an existing column")
+}
@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingMetadataSteps(t *testing.T) {
+	steps, err := pendingMetadataSteps(0)
+	require.NoError(t, err)
+	require.NotEmpty(t, steps)
+	assert.Equal(t, 1, steps[0].version)
+	assert.Equal(t, MetadataSchemaVersion, steps[len(steps)-1].version)
+
+	steps, err = pendingMetadataSteps(MetadataSchemaVersion)
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	_, err = pendingMetadataSteps(MetadataSchemaVersion + 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upgrade scylla-migrate")
+}
+
+func TestMetadataSchemaSteps_Ordered(t *testing.T) {
+	for i, step := range metadataSchemaSteps {
+		assert.Equal(t, i+1, step.version, "steps must be numbered consecutively from 1")
+		assert.NotEmpty(t, step.description)
+	}
+}
+
+func TestAlreadyApplied(t *testing.T) {
+	assert.True(t, alreadyApplied(errors.New("Invalid column name notes because it conflicts with an existing column")))
+	assert.True(t, alreadyApplied(errors.New("Table ks.t already exists")))
+	assert.False(t, alreadyApplied(errors.New("no viable alternative at input")))
+}
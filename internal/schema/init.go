@@ -31,6 +31,16 @@ func InitializeMetadata(session *driver.Session, cfg *config.Config, logger zero
 		return fmt.Errorf("schema agreement timeout after creating keyspace: %w", err)
 	}
 
+	// An existing keyspace keeps its replication even if the config changed
+	if drift, err := CheckMetadataReplication(session, cfg, logger, false); err != nil {
+		logger.Warn().Err(err).Msg("Could not verify metadata keyspace replication")
+	} else if len(drift) > 0 {
+		logger.Warn().
+			Str("keyspace", keyspace).
+			Strs("differences", drift).
+			Msg("Metadata keyspace replication differs from metadata_replication — run 'scylla-migrate metadata check-replication --fix-replication'")
+	}
+
 	// Create schema_migrations table
 	createMigrations := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s.schema_migrations (
@@ -74,6 +84,34 @@ func InitializeMetadata(session *driver.Session, cfg *config.Config, logger zero
 		return fmt.Errorf("schema agreement timeout after creating lock table: %w", err)
 	}
 
+	// Create schema_events table (append-only event log, kept for 30 days)
+	createEvents := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s.schema_events (
+			bucket TEXT,
+			event_time TIMEUUID,
+			event_type TEXT,
+			version TEXT,
+			description TEXT,
+			actor TEXT,
+			message TEXT,
+			PRIMARY KEY ((bucket), event_time)
+		) WITH CLUSTERING ORDER BY (event_time ASC)
+		  AND comment = 'scylla-migrate: migration event log'
+		  AND default_time_to_live = %d`,
+		keyspace, int(EventTTL.Seconds()),
+	)
+	if err := session.Execute(createEvents); err != nil {
+		return fmt.Errorf("failed to create schema_events table: %w", err)
+	}
+
+	if err := session.WaitForSchemaAgreement(cfg.SchemaAgreementTimeout); err != nil {
+		return fmt.Errorf("schema agreement timeout after creating events table: %w", err)
+	}
+
+	if err := migrateMetadataSchema(session, cfg, logger); err != nil {
+		return err
+	}
+
 	logger.Info().Str("keyspace", keyspace).Msg("Metadata tables initialized")
 	return nil
 }
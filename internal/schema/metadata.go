@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/gocql/gocql"
 	"github.com/rs/zerolog"
 
 	"github.com/scylla-migrate/scylla-migrate/internal/driver"
@@ -23,36 +24,127 @@ type AppliedMigration struct {
 	Success         bool
 }
 
+// RecordTypeBaseline is the type of the record metadata prune leaves in
+// place of the versioned records it removes. It stands for its own version
+// and every older one.
+const RecordTypeBaseline = "baseline"
+
 type MigrationRecord struct {
 	Version     string
 	Description string
 	Type        string
 	Filename    string
 	Checksum    string
+	// Content is the migration file as applied, stored only with
+	// store_script_content.
+	Content string
 }
 
 type MetadataManager struct {
-	session  *driver.Session
-	keyspace string
-	Logger   zerolog.Logger
+	session         *driver.Session
+	keyspace        string
+	queries         metadataQueries
+	readConsistency *gocql.Consistency
+	safeRecord      bool
+	storeContent    bool
+	Logger          zerolog.Logger
+}
+
+// AlreadyRecordedError is returned by RecordMigration in safe_record mode
+// when the version already has a successful record, which is left as is.
+type AlreadyRecordedError struct {
+	Version   string
+	AppliedBy string
+	AppliedAt time.Time
+}
+
+func (e *AlreadyRecordedError) Error() string {
+	return fmt.Sprintf("version %s is already recorded as applied by %s at %s",
+		e.Version, e.AppliedBy, e.AppliedAt.UTC().Format(time.RFC3339))
+}
+
+// metadataQueries holds the schema_migrations statements, built once per
+// keyspace. gocql prepares a statement on its first execution and caches it
+// by query text, so reusing the same strings makes every later call a cache
+// hit without re-formatting the query.
+type metadataQueries struct {
+	selectApplied   string
+	insertMigration string
+	deleteMigration string
+	updateChecksum  string
+	// safe_record: insert only new versions, replace only failed attempts
+	insertMigrationIfAbsent string
+	replaceFailedMigration  string
+	// store_script_content
+	updateContent string
+	selectContent string
+	// schema_repeatables
+	selectRepeatables        string
+	selectRepeatable         string
+	insertRepeatableIfAbsent string
+	replaceRepeatable        string
+	completeRepeatable       string
+}
+
+func newMetadataQueries(keyspace string) metadataQueries {
+	return metadataQueries{
+		selectApplied: fmt.Sprintf(
+			`SELECT version, description, type, script, checksum, applied_by, applied_at, execution_time_ms, success
+		 FROM %s.schema_migrations`, keyspace),
+		insertMigration: fmt.Sprintf(
+			`INSERT INTO %s.schema_migrations
+		 (version, description, type, script, checksum, applied_by, applied_at, execution_time_ms, success)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, keyspace),
+		deleteMigration: fmt.Sprintf(`DELETE FROM %s.schema_migrations WHERE version = ?`, keyspace),
+		updateChecksum:  fmt.Sprintf(`UPDATE %s.schema_migrations SET checksum = ? WHERE version = ?`, keyspace),
+		insertMigrationIfAbsent: fmt.Sprintf(
+			`INSERT INTO %s.schema_migrations
+		 (version, description, type, script, checksum, applied_by, applied_at, execution_time_ms, success)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) IF NOT EXISTS`, keyspace),
+		replaceFailedMigration: fmt.Sprintf(
+			`UPDATE %s.schema_migrations
+		 SET description = ?, type = ?, script = ?, checksum = ?, applied_by = ?, applied_at = ?, execution_time_ms = ?, success = ?
+		 WHERE version = ? IF success = false`, keyspace),
+		updateContent: fmt.Sprintf(`UPDATE %s.schema_migrations SET content = ? WHERE version = ?`, keyspace),
+		selectContent: fmt.Sprintf(`SELECT version, content FROM %s.schema_migrations`, keyspace),
+		selectRepeatables: fmt.Sprintf(
+			`SELECT name, checksum, status, applied_by, applied_at, execution_time_ms FROM %s.schema_repeatables`, keyspace),
+		selectRepeatable: fmt.Sprintf(
+			`SELECT name, checksum, status, applied_by, applied_at, execution_time_ms FROM %s.schema_repeatables WHERE name = ?`, keyspace),
+		insertRepeatableIfAbsent: fmt.Sprintf(
+			`INSERT INTO %s.schema_repeatables (name, checksum, status, applied_by, applied_at)
+		 VALUES (?, ?, ?, ?, ?) IF NOT EXISTS`, keyspace),
+		replaceRepeatable: fmt.Sprintf(
+			`UPDATE %s.schema_repeatables SET status = ?, applied_by = ?, applied_at = ?, execution_time_ms = null
+		 WHERE name = ? AND checksum = ? IF applied_at = ?`, keyspace),
+		completeRepeatable: fmt.Sprintf(
+			`UPDATE %s.schema_repeatables SET status = ?, execution_time_ms = ? WHERE name = ? AND checksum = ?`, keyspace),
+	}
 }
 
 func NewMetadataManager(session *driver.Session, keyspace string, logger zerolog.Logger) *MetadataManager {
 	return &MetadataManager{
 		session:  session,
 		keyspace: keyspace,
+		queries:  newMetadataQueries(keyspace),
 		Logger:   logger,
 	}
 }
 
+// SetReadConsistency overrides the consistency used when reading applied
+// migrations. Only read-only commands should use this: a stale read must
+// never feed the checksum validation that gates migrate.
+func (m *MetadataManager) SetReadConsistency(c gocql.Consistency) {
+	m.readConsistency = &c
+}
+
 func (m *MetadataManager) GetAppliedMigrations() ([]AppliedMigration, error) {
-	query := fmt.Sprintf(
-		`SELECT version, description, type, script, checksum, applied_by, applied_at, execution_time_ms, success
-		 FROM %s.schema_migrations`,
-		m.keyspace,
-	)
+	q := m.session.QueryWithSpeculation(m.queries.selectApplied)
+	if m.readConsistency != nil {
+		q = q.Consistency(*m.readConsistency)
+	}
 
-	iter := m.session.Query(query).Iter()
+	iter := q.Iter()
 	var applied []AppliedMigration
 
 	var a AppliedMigration
@@ -68,27 +160,95 @@ func (m *MetadataManager) GetAppliedMigrations() ([]AppliedMigration, error) {
 		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
 	}
 
-	sort.Slice(applied, func(i, j int) bool {
-		ai, errA := strconv.Atoi(applied[i].Version)
-		bi, errB := strconv.Atoi(applied[j].Version)
-		if errA == nil && errB == nil {
-			return ai < bi
+	sortApplied(applied)
+	return applied, nil
+}
+
+// GetStoredScripts returns the content stored with store_script_content, by
+// recorded version. Versions without stored content are omitted.
+func (m *MetadataManager) GetStoredScripts() (map[string]string, error) {
+	iter := m.session.QueryWithSpeculation(m.queries.selectContent).Iter()
+	scripts := make(map[string]string)
+
+	var version, content string
+	for iter.Scan(&version, &content) {
+		if content != "" {
+			scripts[version] = content
 		}
-		return applied[i].Version < applied[j].Version
-	})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query stored scripts: %w", err)
+	}
+	return scripts, nil
+}
 
-	return applied, nil
+// sortApplied orders migrations by numeric version, falling back to string
+// order for non-numeric versions. Versions are parsed once up front rather
+// than on every comparison, which matters on long histories.
+func sortApplied(applied []AppliedMigration) {
+	keys := make([]versionKey, len(applied))
+	for i, a := range applied {
+		n, err := strconv.Atoi(a.Version)
+		keys[i] = versionKey{num: n, numeric: err == nil}
+	}
+	sort.Sort(appliedByVersion{applied: applied, keys: keys})
+}
+
+type versionKey struct {
+	num     int
+	numeric bool
+}
+
+type appliedByVersion struct {
+	applied []AppliedMigration
+	keys    []versionKey
+}
+
+func (s appliedByVersion) Len() int { return len(s.applied) }
+
+func (s appliedByVersion) Less(i, j int) bool {
+	if s.keys[i].numeric && s.keys[j].numeric {
+		return s.keys[i].num < s.keys[j].num
+	}
+	return s.applied[i].Version < s.applied[j].Version
+}
+
+func (s appliedByVersion) Swap(i, j int) {
+	s.applied[i], s.applied[j] = s.applied[j], s.applied[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// SetSafeRecord makes RecordMigration use lightweight transactions so an
+// existing successful record is never overwritten (safe_record).
+// Repeatable records are always overwritten, as they change on every re-run.
+func (m *MetadataManager) SetSafeRecord(enabled bool) {
+	m.safeRecord = enabled
+}
+
+// SetStoreScriptContent makes RecordMigration also store the content of each
+// successfully applied migration (store_script_content), so it can be
+// exported after the file is gone.
+func (m *MetadataManager) SetStoreScriptContent(enabled bool) {
+	m.storeContent = enabled
 }
 
 func (m *MetadataManager) RecordMigration(rec MigrationRecord, executionTime time.Duration, success bool, hostname string) error {
-	query := fmt.Sprintf(
-		`INSERT INTO %s.schema_migrations
-		 (version, description, type, script, checksum, applied_by, applied_at, execution_time_ms, success)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		m.keyspace,
-	)
+	if err := m.recordMigration(rec, executionTime, success, hostname); err != nil {
+		return err
+	}
+	if m.storeContent && success && rec.Content != "" {
+		if err := m.session.Execute(m.queries.updateContent, rec.Content, rec.Version); err != nil {
+			return fmt.Errorf("failed to store script content for %s: %w", rec.Version, err)
+		}
+	}
+	return nil
+}
 
-	return m.session.Execute(query,
+func (m *MetadataManager) recordMigration(rec MigrationRecord, executionTime time.Duration, success bool, hostname string) error {
+	if m.safeRecord && rec.Type != "repeatable" {
+		return m.recordMigrationIfAbsent(rec, executionTime, success, hostname)
+	}
+	return m.session.Execute(m.queries.insertMigration,
 		rec.Version,
 		rec.Description,
 		rec.Type,
@@ -101,20 +261,102 @@ func (m *MetadataManager) RecordMigration(rec MigrationRecord, executionTime tim
 	)
 }
 
+// recordRow is one schema_migrations row as written by RecordMigration.
+type recordRow struct {
+	MigrationRecord
+	AppliedBy       string
+	AppliedAt       time.Time
+	ExecutionTimeMS int
+	Success         bool
+}
+
+// recordStore is the schema_migrations access recordIfAbsent needs. Both
+// writes are lightweight transactions; when one is not applied, the
+// existing row's columns are returned.
+type recordStore interface {
+	// insertRecordIfAbsent inserts row unless its version exists
+	insertRecordIfAbsent(row recordRow) (bool, map[string]interface{}, error)
+	// replaceFailedRecord overwrites the row of its version if that row
+	// records a failure
+	replaceFailedRecord(row recordRow) (bool, map[string]interface{}, error)
+}
+
+func (m *MetadataManager) insertRecordIfAbsent(row recordRow) (bool, map[string]interface{}, error) {
+	existing := make(map[string]interface{})
+	applied, err := m.session.Query(m.queries.insertMigrationIfAbsent,
+		row.Version, row.Description, row.Type, row.Filename, row.Checksum,
+		row.AppliedBy, row.AppliedAt, row.ExecutionTimeMS, row.Success,
+	).MapScanCAS(existing)
+	return applied, existing, err
+}
+
+func (m *MetadataManager) replaceFailedRecord(row recordRow) (bool, map[string]interface{}, error) {
+	existing := make(map[string]interface{})
+	applied, err := m.session.Query(m.queries.replaceFailedMigration,
+		row.Description, row.Type, row.Filename, row.Checksum,
+		row.AppliedBy, row.AppliedAt, row.ExecutionTimeMS, row.Success, row.Version,
+	).MapScanCAS(existing)
+	return applied, existing, err
+}
+
+// recordMigrationIfAbsent inserts the record unless the version exists. A
+// previous failed attempt is replaced; a successful one is kept and reported
+// as an AlreadyRecordedError.
+func (m *MetadataManager) recordMigrationIfAbsent(rec MigrationRecord, executionTime time.Duration, success bool, hostname string) error {
+	return recordIfAbsent(m, recordRow{
+		MigrationRecord: rec,
+		AppliedBy:       hostname,
+		AppliedAt:       time.Now(),
+		ExecutionTimeMS: int(executionTime.Milliseconds()),
+		Success:         success,
+	})
+}
+
+func recordIfAbsent(store recordStore, row recordRow) error {
+	applied, existing, err := store.insertRecordIfAbsent(row)
+	if err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	if prevSuccess, _ := existing["success"].(bool); !prevSuccess {
+		applied, existing, err = store.replaceFailedRecord(row)
+		if err != nil {
+			return err
+		}
+		if applied {
+			return nil
+		}
+	}
+
+	// Someone recorded it successfully in the meantime, or before us
+	appliedBy, _ := existing["applied_by"].(string)
+	appliedAt, _ := existing["applied_at"].(time.Time)
+	return &AlreadyRecordedError{Version: row.Version, AppliedBy: appliedBy, AppliedAt: appliedAt}
+}
+
+// RecordBaseline replaces the record of version with a baseline record
+// covering it and all older versioned migrations.
+func (m *MetadataManager) RecordBaseline(version, description, hostname string) error {
+	err := m.session.Execute(m.queries.insertMigration,
+		version, description, RecordTypeBaseline, "", "", hostname, time.Now(), 0, true)
+	if err != nil {
+		return fmt.Errorf("failed to record baseline %s: %w", version, err)
+	}
+	if err := m.session.Execute(m.queries.updateContent, nil, version); err != nil {
+		return fmt.Errorf("failed to clear stored content of %s: %w", version, err)
+	}
+	return nil
+}
+
 func (m *MetadataManager) RemoveMigration(version string) error {
-	query := fmt.Sprintf(
-		`DELETE FROM %s.schema_migrations WHERE version = ?`,
-		m.keyspace,
-	)
-	return m.session.Execute(query, version)
+	return m.session.Execute(m.queries.deleteMigration, version)
 }
 
 func (m *MetadataManager) UpdateChecksum(version, newChecksum string) error {
-	query := fmt.Sprintf(
-		`UPDATE %s.schema_migrations SET checksum = ? WHERE version = ?`,
-		m.keyspace,
-	)
-	return m.session.Execute(query, newChecksum, version)
+	return m.session.Execute(m.queries.updateChecksum, newChecksum, version)
 }
 
 func (m *MetadataManager) GetLastAppliedVersion() (string, error) {
@@ -126,7 +368,7 @@ func (m *MetadataManager) GetLastAppliedVersion() (string, error) {
 	lastVersion := ""
 	lastNum := -1
 	for _, a := range applied {
-		if a.Success && a.Type == "versioned" {
+		if a.Success && (a.Type == "versioned" || a.Type == RecordTypeBaseline) {
 			num, err := strconv.Atoi(a.Version)
 			if err != nil {
 				// Non-numeric: fallback to lexicographic
@@ -160,3 +402,24 @@ func (m *MetadataManager) GetFailedMigrations() ([]AppliedMigration, error) {
 
 	return failed, nil
 }
+
+func (m *MetadataManager) GetLockRows() ([]LockRow, error) {
+	query := fmt.Sprintf(
+		`SELECT lock_id, locked_by, locked_at, expires_at FROM %s.schema_lock`,
+		m.keyspace,
+	)
+
+	iter := m.session.Query(query).Iter()
+	var rows []LockRow
+
+	var r LockRow
+	for iter.Scan(&r.LockID, &r.LockedBy, &r.LockedAt, &r.ExpiresAt) {
+		rows = append(rows, r)
+		r = LockRow{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query locks: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,182 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortApplied(t *testing.T) {
+	applied := []AppliedMigration{
+		{Version: "10"}, {Version: "R_views"}, {Version: "002"}, {Version: "1"},
+	}
+	sortApplied(applied)
+
+	var versions []string
+	for _, a := range applied {
+		versions = append(versions, a.Version)
+	}
+	assert.Equal(t, []string{"1", "002", "10", "R_views"}, versions)
+}
+
+func TestNewMetadataQueries(t *testing.T) {
+	q := newMetadataQueries("meta")
+	assert.Contains(t, q.selectApplied, "FROM meta.schema_migrations")
+	assert.Contains(t, q.insertMigration, "INSERT INTO meta.schema_migrations")
+	assert.Equal(t, "DELETE FROM meta.schema_migrations WHERE version = ?", q.deleteMigration)
+	assert.Equal(t, "UPDATE meta.schema_migrations SET checksum = ? WHERE version = ?", q.updateChecksum)
+	assert.Contains(t, q.insertMigrationIfAbsent, "INSERT INTO meta.schema_migrations")
+	assert.True(t, strings.HasSuffix(q.insertMigrationIfAbsent, "IF NOT EXISTS"))
+	assert.True(t, strings.HasSuffix(q.replaceFailedMigration, "WHERE version = ? IF success = false"))
+	// Both conditional writes bind the same nine values as the plain insert
+	assert.Equal(t, strings.Count(q.insertMigration, "?"), strings.Count(q.insertMigrationIfAbsent, "?"))
+	assert.Equal(t, strings.Count(q.insertMigration, "?"), strings.Count(q.replaceFailedMigration, "?"))
+	assert.Equal(t, "UPDATE meta.schema_migrations SET content = ? WHERE version = ?", q.updateContent)
+	assert.Equal(t, "SELECT version, content FROM meta.schema_migrations", q.selectContent)
+}
+
+// fakeRecordStore keeps schema_migrations in memory and applies the
+// conditional writes as Scylla does, returning the existing row's columns
+// when a condition fails.
+type fakeRecordStore struct {
+	rows map[string]recordRow
+	// beforeReplace runs just before replaceFailedRecord checks its
+	// condition, to simulate a concurrent writer
+	beforeReplace func()
+	err           error
+	replaced      bool
+}
+
+func (s *fakeRecordStore) columns(version string) map[string]interface{} {
+	row := s.rows[version]
+	return map[string]interface{}{
+		"version": row.Version, "success": row.Success,
+		"applied_by": row.AppliedBy, "applied_at": row.AppliedAt,
+	}
+}
+
+func (s *fakeRecordStore) insertRecordIfAbsent(row recordRow) (bool, map[string]interface{}, error) {
+	if s.err != nil {
+		return false, nil, s.err
+	}
+	if _, exists := s.rows[row.Version]; exists {
+		return false, s.columns(row.Version), nil
+	}
+	s.rows[row.Version] = row
+	return true, map[string]interface{}{}, nil
+}
+
+func (s *fakeRecordStore) replaceFailedRecord(row recordRow) (bool, map[string]interface{}, error) {
+	s.replaced = true
+	if s.beforeReplace != nil {
+		s.beforeReplace()
+	}
+	if s.rows[row.Version].Success {
+		return false, s.columns(row.Version), nil
+	}
+	s.rows[row.Version] = row
+	return true, map[string]interface{}{}, nil
+}
+
+func testRow(version, host string, success bool, at time.Time) recordRow {
+	return recordRow{
+		MigrationRecord: MigrationRecord{Version: version, Type: "versioned", Checksum: "c-" + host},
+		AppliedBy:       host,
+		AppliedAt:       at,
+		Success:         success,
+	}
+}
+
+func TestRecordIfAbsent_NotApplied(t *testing.T) {
+	store := &fakeRecordStore{rows: map[string]recordRow{}}
+	row := testRow("001", "runner-a", true, time.Now())
+
+	require.NoError(t, recordIfAbsent(store, row))
+	assert.Equal(t, row, store.rows["001"])
+	assert.False(t, store.replaced)
+}
+
+func TestRecordIfAbsent_AlreadyAppliedSuccessfully(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	prev := testRow("001", "runner-a", true, at)
+	store := &fakeRecordStore{rows: map[string]recordRow{"001": prev}}
+
+	err := recordIfAbsent(store, testRow("001", "runner-b", true, time.Now()))
+	var recorded *AlreadyRecordedError
+	require.ErrorAs(t, err, &recorded)
+	assert.Equal(t, AlreadyRecordedError{Version: "001", AppliedBy: "runner-a", AppliedAt: at}, *recorded)
+	assert.Equal(t, prev, store.rows["001"])
+	assert.False(t, store.replaced)
+}
+
+func TestRecordIfAbsent_ReplacesFailedAttempt(t *testing.T) {
+	store := &fakeRecordStore{rows: map[string]recordRow{
+		"001": testRow("001", "runner-a", false, time.Now().Add(-time.Hour)),
+	}}
+	row := testRow("001", "runner-b", true, time.Now())
+
+	require.NoError(t, recordIfAbsent(store, row))
+	assert.True(t, store.replaced)
+	assert.Equal(t, row, store.rows["001"])
+}
+
+func TestRecordIfAbsent_FailedAttemptReplacedConcurrently(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeRecordStore{rows: map[string]recordRow{
+		"001": testRow("001", "runner-a", false, at.Add(-time.Hour)),
+	}}
+	winner := testRow("001", "runner-c", true, at)
+	store.beforeReplace = func() { store.rows["001"] = winner }
+
+	err := recordIfAbsent(store, testRow("001", "runner-b", true, time.Now()))
+	var recorded *AlreadyRecordedError
+	require.ErrorAs(t, err, &recorded)
+	assert.Equal(t, "runner-c", recorded.AppliedBy)
+	assert.Equal(t, at, recorded.AppliedAt)
+	assert.Equal(t, winner, store.rows["001"])
+}
+
+func TestRecordIfAbsent_WriteError(t *testing.T) {
+	store := &fakeRecordStore{rows: map[string]recordRow{}, err: errors.New("timeout")}
+
+	err := recordIfAbsent(store, testRow("001", "runner-a", true, time.Now()))
+	assert.EqualError(t, err, "timeout")
+	assert.Empty(t, store.rows)
+}
+
+func largeHistory(n int) []AppliedMigration {
+	r := rand.New(rand.NewSource(1))
+	applied := make([]AppliedMigration, n)
+	for i, p := range r.Perm(n) {
+		applied[i] = AppliedMigration{Version: fmt.Sprintf("%05d", p)}
+	}
+	return applied
+}
+
+// BenchmarkSortApplied measures ordering a 10k-entry history, the dominant
+// client-side cost of GetAppliedMigrations once rows are fetched.
+func BenchmarkSortApplied(b *testing.B) {
+	history := largeHistory(10000)
+	applied := make([]AppliedMigration, len(history))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		copy(applied, history)
+		sortApplied(applied)
+	}
+}
+
+// BenchmarkMetadataQueryText measures building the statement texts, which
+// newMetadataQueries pays once per MetadataManager instead of once per
+// metadata write.
+func BenchmarkMetadataQueryText(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = newMetadataQueries("scylla_migrate")
+	}
+}
@@ -0,0 +1,177 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status of a row in schema_repeatables.
+const (
+	RepeatableRunning = "running"
+	RepeatableApplied = "applied"
+	RepeatableFailed  = "failed"
+)
+
+// RepeatableClaimTimeout is how long a running claim blocks other runners.
+// A runner that died mid-run leaves its claim behind; after this long the
+// content may be claimed again.
+const RepeatableClaimTimeout = time.Hour
+
+// RepeatableRun is one distinct content version of a repeatable migration,
+// keyed by the repeatable's record name and the content checksum.
+type RepeatableRun struct {
+	Name            string
+	Checksum        string
+	Status          string
+	AppliedBy       string
+	AppliedAt       time.Time
+	ExecutionTimeMS int
+}
+
+// RepeatableTakenError is returned by ClaimRepeatable when the content was
+// already applied, or is being applied, by another run.
+type RepeatableTakenError struct {
+	Run RepeatableRun
+}
+
+func (e *RepeatableTakenError) Error() string {
+	if e.Run.Status == RepeatableRunning {
+		return fmt.Sprintf("%s is being applied by %s since %s", e.Run.Name, e.Run.AppliedBy,
+			e.Run.AppliedAt.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s with this content was already applied by %s at %s", e.Run.Name, e.Run.AppliedBy,
+		e.Run.AppliedAt.UTC().Format(time.RFC3339))
+}
+
+// LatestRepeatableRun returns the most recently applied run of name, which
+// holds the content currently in effect.
+func LatestRepeatableRun(runs []RepeatableRun, name string) (RepeatableRun, bool) {
+	var latest RepeatableRun
+	found := false
+	for _, r := range runs {
+		if r.Name == name && r.Status == RepeatableApplied && (!found || r.AppliedAt.After(latest.AppliedAt)) {
+			latest, found = r, true
+		}
+	}
+	return latest, found
+}
+
+// repeatableStore is the schema_repeatables access claimRepeatable needs.
+// Both writes are lightweight transactions.
+type repeatableStore interface {
+	repeatableRuns(name string) ([]RepeatableRun, error)
+	// insertRunIfAbsent inserts run unless its (name, checksum) row exists
+	insertRunIfAbsent(run RepeatableRun) (bool, error)
+	// replaceRunIf overwrites the (name, checksum) row if its applied_at is
+	// still observedAt
+	replaceRunIf(run RepeatableRun, observedAt time.Time) (bool, error)
+}
+
+// claimRepeatable marks the content of name as running under hostname.
+// Each outcome is decided by a conditional write against the state read
+// here, so of several runners racing for the same content exactly one wins.
+func claimRepeatable(store repeatableStore, name, checksum, hostname string, now time.Time) error {
+	runs, err := store.repeatableRuns(name)
+	if err != nil {
+		return err
+	}
+	if latest, ok := LatestRepeatableRun(runs, name); ok && latest.Checksum == checksum {
+		return &RepeatableTakenError{Run: latest}
+	}
+
+	run := RepeatableRun{Name: name, Checksum: checksum, Status: RepeatableRunning, AppliedBy: hostname, AppliedAt: now}
+	var claimed bool
+	if existing, ok := findRepeatableRun(runs, checksum); !ok {
+		claimed, err = store.insertRunIfAbsent(run)
+	} else if existing.Status == RepeatableRunning && now.Sub(existing.AppliedAt) < RepeatableClaimTimeout {
+		return &RepeatableTakenError{Run: existing}
+	} else {
+		// content applied before and since replaced, or a failed or stale
+		// attempt
+		claimed, err = store.replaceRunIf(run, existing.AppliedAt)
+	}
+	if err != nil {
+		return err
+	}
+	if claimed {
+		return nil
+	}
+
+	// Lost the race; report the winner
+	runs, err = store.repeatableRuns(name)
+	if err != nil {
+		return err
+	}
+	winner, _ := findRepeatableRun(runs, checksum)
+	return &RepeatableTakenError{Run: winner}
+}
+
+func findRepeatableRun(runs []RepeatableRun, checksum string) (RepeatableRun, bool) {
+	for _, r := range runs {
+		if r.Checksum == checksum {
+			return r, true
+		}
+	}
+	return RepeatableRun{}, false
+}
+
+// GetRepeatableRuns returns every recorded content version of every
+// repeatable migration.
+func (m *MetadataManager) GetRepeatableRuns() ([]RepeatableRun, error) {
+	return m.scanRepeatableRuns(m.queries.selectRepeatables)
+}
+
+func (m *MetadataManager) repeatableRuns(name string) ([]RepeatableRun, error) {
+	return m.scanRepeatableRuns(m.queries.selectRepeatable, name)
+}
+
+func (m *MetadataManager) scanRepeatableRuns(query string, values ...interface{}) ([]RepeatableRun, error) {
+	iter := m.session.Query(query, values...).Iter()
+	var runs []RepeatableRun
+
+	var r RepeatableRun
+	for iter.Scan(&r.Name, &r.Checksum, &r.Status, &r.AppliedBy, &r.AppliedAt, &r.ExecutionTimeMS) {
+		runs = append(runs, r)
+		r = RepeatableRun{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to query repeatable runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (m *MetadataManager) insertRunIfAbsent(run RepeatableRun) (bool, error) {
+	return m.session.Query(m.queries.insertRepeatableIfAbsent,
+		run.Name, run.Checksum, run.Status, run.AppliedBy, run.AppliedAt,
+	).MapScanCAS(make(map[string]interface{}))
+}
+
+func (m *MetadataManager) replaceRunIf(run RepeatableRun, observedAt time.Time) (bool, error) {
+	return m.session.Query(m.queries.replaceRepeatable,
+		run.Status, run.AppliedBy, run.AppliedAt, run.Name, run.Checksum, observedAt,
+	).MapScanCAS(make(map[string]interface{}))
+}
+
+// ClaimRepeatable reserves this content of the repeatable name for the
+// caller. A *RepeatableTakenError means another run applied it, or is
+// applying it, and the caller must not execute it.
+func (m *MetadataManager) ClaimRepeatable(name, checksum, hostname string) error {
+	// applied_at is compared in a later claim; keep it at the stored precision
+	err := claimRepeatable(m, name, checksum, hostname, time.Now().Truncate(time.Millisecond))
+	var taken *RepeatableTakenError
+	if err != nil && !errors.As(err, &taken) {
+		return fmt.Errorf("failed to claim repeatable %s: %w", name, err)
+	}
+	return err
+}
+
+// CompleteRepeatable records the outcome of a claimed repeatable run.
+func (m *MetadataManager) CompleteRepeatable(name, checksum string, executionTime time.Duration, success bool) error {
+	status := RepeatableApplied
+	if !success {
+		status = RepeatableFailed
+	}
+	return m.session.Execute(m.queries.completeRepeatable,
+		status, int(executionTime.Milliseconds()), name, checksum)
+}
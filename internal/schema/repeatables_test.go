@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepeatableStore keeps schema_repeatables in memory and applies the
+// conditional writes atomically, as Scylla's lightweight transactions do.
+type fakeRepeatableStore struct {
+	mu   sync.Mutex
+	rows map[string]RepeatableRun
+}
+
+func newFakeRepeatableStore(runs ...RepeatableRun) *fakeRepeatableStore {
+	s := &fakeRepeatableStore{rows: make(map[string]RepeatableRun)}
+	for _, r := range runs {
+		s.rows[r.Name+"/"+r.Checksum] = r
+	}
+	return s
+}
+
+func (s *fakeRepeatableStore) repeatableRuns(name string) ([]RepeatableRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []RepeatableRun
+	for _, r := range s.rows {
+		if r.Name == name {
+			runs = append(runs, r)
+		}
+	}
+	return runs, nil
+}
+
+func (s *fakeRepeatableStore) insertRunIfAbsent(run RepeatableRun) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := run.Name + "/" + run.Checksum
+	if _, exists := s.rows[key]; exists {
+		return false, nil
+	}
+	s.rows[key] = run
+	return true, nil
+}
+
+func (s *fakeRepeatableStore) replaceRunIf(run RepeatableRun, observedAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := run.Name + "/" + run.Checksum
+	if existing, ok := s.rows[key]; !ok || !existing.AppliedAt.Equal(observedAt) {
+		return false, nil
+	}
+	s.rows[key] = run
+	return true, nil
+}
+
+func (s *fakeRepeatableStore) complete(name, checksum string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.rows[name+"/"+checksum]
+	r.Status = RepeatableApplied
+	s.rows[name+"/"+checksum] = r
+}
+
+// claimConcurrently has n runners claim the same content at once and
+// returns how many won.
+func claimConcurrently(t *testing.T, store *fakeRepeatableStore, n int, checksum string, now time.Time) int {
+	t.Helper()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	won := 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := claimRepeatable(store, "R_views", checksum, "runner", now)
+			var taken *RepeatableTakenError
+			if err != nil {
+				assert.True(t, errors.As(err, &taken), "unexpected error: %v", err)
+				return
+			}
+			mu.Lock()
+			won++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return won
+}
+
+func TestClaimRepeatable_NewContentClaimedOnce(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeRepeatableStore(
+		RepeatableRun{Name: "R_views", Checksum: "v1", Status: RepeatableApplied, AppliedAt: now.Add(-time.Hour)},
+	)
+
+	assert.Equal(t, 1, claimConcurrently(t, store, 8, "v2", now))
+
+	// Still running: a later runner backs off
+	err := claimRepeatable(store, "R_views", "v2", "late", now.Add(time.Minute))
+	var taken *RepeatableTakenError
+	require.ErrorAs(t, err, &taken)
+	assert.Equal(t, RepeatableRunning, taken.Run.Status)
+
+	// Applied: it is the content in effect and is not claimed again
+	store.complete("R_views", "v2")
+	err = claimRepeatable(store, "R_views", "v2", "late", now.Add(2*time.Minute))
+	require.ErrorAs(t, err, &taken)
+	assert.Equal(t, RepeatableApplied, taken.Run.Status)
+}
+
+func TestClaimRepeatable_RevertedContentClaimedOnce(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeRepeatableStore(
+		RepeatableRun{Name: "R_views", Checksum: "v1", Status: RepeatableApplied, AppliedAt: now.Add(-2 * time.Hour)},
+		RepeatableRun{Name: "R_views", Checksum: "v2", Status: RepeatableApplied, AppliedAt: now.Add(-time.Hour)},
+	)
+
+	// v1 was applied before, but v2 is in effect, so v1 runs again — once
+	assert.Equal(t, 1, claimConcurrently(t, store, 8, "v1", now))
+}
+
+func TestClaimRepeatable_FailedOrStaleClaimRetried(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newFakeRepeatableStore(
+		RepeatableRun{Name: "R_views", Checksum: "failed", Status: RepeatableFailed, AppliedAt: now.Add(-time.Minute)},
+		RepeatableRun{Name: "R_views", Checksum: "stale", Status: RepeatableRunning, AppliedAt: now.Add(-RepeatableClaimTimeout)},
+	)
+
+	assert.Equal(t, 1, claimConcurrently(t, store, 4, "failed", now))
+	assert.Equal(t, 1, claimConcurrently(t, store, 4, "stale", now))
+}
+
+func TestLatestRepeatableRun(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := []RepeatableRun{
+		{Name: "R_views", Checksum: "a", Status: RepeatableApplied, AppliedAt: at.Add(time.Hour)},
+		{Name: "R_views", Checksum: "b", Status: RepeatableApplied, AppliedAt: at},
+		{Name: "R_views", Checksum: "c", Status: RepeatableRunning, AppliedAt: at.Add(2 * time.Hour)},
+		{Name: "R_other", Checksum: "d", Status: RepeatableApplied, AppliedAt: at.Add(3 * time.Hour)},
+	}
+
+	latest, ok := LatestRepeatableRun(runs, "R_views")
+	require.True(t, ok)
+	assert.Equal(t, "a", latest.Checksum)
+
+	_, ok = LatestRepeatableRun(runs, "R_missing")
+	assert.False(t, ok)
+}
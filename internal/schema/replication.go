@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
+)
+
+const strategyPackage = "org.apache.cassandra.locator."
+
+// KeyspaceReplication reads a keyspace's replication options from
+// system_schema.keyspaces.
+func KeyspaceReplication(session *driver.Session, keyspace string) (map[string]string, error) {
+	var replication map[string]string
+	err := session.Query(
+		"SELECT replication FROM system_schema.keyspaces WHERE keyspace_name = ?", keyspace,
+	).Scan(&replication)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication of keyspace %s: %w", keyspace, err)
+	}
+	return replication, nil
+}
+
+// ReplicationDrift lists the differences between the actual replication
+// options of a keyspace and the expected ones, sorted by option name. Strategy
+// classes are compared without their Java package.
+func ReplicationDrift(actual, expected map[string]string) []string {
+	keys := make(map[string]bool)
+	for k := range actual {
+		keys[k] = true
+	}
+	for k := range expected {
+		keys[k] = true
+	}
+
+	var drift []string
+	for k := range keys {
+		got, hasGot := actual[k]
+		want, hasWant := expected[k]
+		if k == "class" {
+			got, want = strings.TrimPrefix(got, strategyPackage), strings.TrimPrefix(want, strategyPackage)
+		}
+
+		switch {
+		case !hasWant:
+			drift = append(drift, fmt.Sprintf("%s: %s (not configured)", k, got))
+		case !hasGot:
+			drift = append(drift, fmt.Sprintf("%s: missing (config: %s)", k, want))
+		case got != want:
+			drift = append(drift, fmt.Sprintf("%s: %s (config: %s)", k, got, want))
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// CheckMetadataReplication compares the metadata keyspace's replication with
+// metadata_replication and returns the differences. CREATE KEYSPACE IF NOT
+// EXISTS never alters an existing keyspace, so a config change is otherwise
+// silently ignored. With fix set, the keyspace is altered to match the config.
+func CheckMetadataReplication(session *driver.Session, cfg *config.Config, logger zerolog.Logger, fix bool) ([]string, error) {
+	actual, err := KeyspaceReplication(session, cfg.MetadataKeyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := ReplicationDrift(actual, cfg.ReplicationOptions())
+	if len(drift) == 0 || !fix {
+		return drift, nil
+	}
+
+	alter := fmt.Sprintf("ALTER KEYSPACE %s WITH replication = %s", cfg.MetadataKeyspace, cfg.ReplicationCQL())
+	if err := session.Execute(alter); err != nil {
+		return drift, fmt.Errorf("failed to alter metadata keyspace replication: %w", err)
+	}
+	if err := session.WaitForSchemaAgreement(cfg.SchemaAgreementTimeout); err != nil {
+		return drift, fmt.Errorf("schema agreement timeout after altering keyspace: %w", err)
+	}
+
+	logger.Info().
+		Str("keyspace", cfg.MetadataKeyspace).
+		Str("replication", cfg.ReplicationCQL()).
+		Msg("Metadata keyspace replication updated — run a full repair of the keyspace to stream existing data")
+	return drift, nil
+}
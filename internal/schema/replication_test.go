@@ -0,0 +1,30 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicationDrift(t *testing.T) {
+	actual := map[string]string{
+		"class":              "org.apache.cassandra.locator.SimpleStrategy",
+		"replication_factor": "1",
+	}
+
+	assert.Empty(t, ReplicationDrift(actual, map[string]string{
+		"class": "SimpleStrategy", "replication_factor": "1",
+	}))
+
+	assert.Equal(t, []string{"replication_factor: 1 (config: 3)"}, ReplicationDrift(actual, map[string]string{
+		"class": "SimpleStrategy", "replication_factor": "3",
+	}))
+
+	assert.Equal(t, []string{
+		"class: SimpleStrategy (config: NetworkTopologyStrategy)",
+		"dc1: missing (config: 3)",
+		"replication_factor: 1 (not configured)",
+	}, ReplicationDrift(actual, map[string]string{
+		"class": "NetworkTopologyStrategy", "dc1": "3",
+	}))
+}
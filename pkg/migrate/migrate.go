@@ -38,7 +38,7 @@ type Migrator struct {
 func New(opts ...Option) (*Migrator, error) {
 	cfg := &config.Config{
 		Hosts:                  []string{"localhost:9042"},
-		MigrationsDir:          "./migrations",
+		MigrationsDirs:         []string{"./migrations"},
 		Consistency:            "quorum",
 		Timeout:                30 * time.Second,
 		ConnectionTimeout:      10 * time.Second,
@@ -49,13 +49,18 @@ func New(opts ...Option) (*Migrator, error) {
 			Class:             "SimpleStrategy",
 			ReplicationFactor: 1,
 		},
-		MaxRetries:      3,
-		ProtocolVersion: 4,
+		MaxRetries:            3,
+		ProtocolVersion:       4,
+		OutOfOrder:            "fail",
+		LockStrategy:          "lwt",
+		StatementSeparator:    ";",
+		ChecksumNormalization: config.DefaultChecksumNormalization(),
 	}
 
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	cfg.ApplyEnvironment()
 
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -79,6 +84,12 @@ func New(opts ...Option) (*Migrator, error) {
 }
 
 func (m *Migrator) Migrate() error {
+	if err := migration.CheckSchemaAgreement(m.ctx, false); err != nil {
+		return err
+	}
+	if err := migration.WaitForReadiness(m.ctx); err != nil {
+		return err
+	}
 	if err := m.ctx.LockManager.Acquire(m.config.LockTimeout); err != nil {
 		return fmt.Errorf("failed to acquire lock: %w", err)
 	}
@@ -88,7 +99,7 @@ func (m *Migrator) Migrate() error {
 		}
 	}()
 
-	scanned, err := migration.ScanMigrationsDir(m.config.MigrationsDir)
+	scanned, err := migration.ScanMigrationsDirs(m.config.MigrationsDirs, migration.ParseOptionsFor(m.config))
 	if err != nil {
 		return err
 	}
@@ -98,8 +109,16 @@ func (m *Migrator) Migrate() error {
 		return err
 	}
 
-	resolver := migration.NewResolver(scanned)
-	if errors := resolver.ValidateAppliedChecksums(applied); len(errors) > 0 {
+	resolver := migration.NewConfiguredResolver(m.ctx, scanned)
+	var errors []string
+	for _, issue := range resolver.ValidateAppliedChecksumsDetailed(applied) {
+		if m.config.AllowLocalModifications && issue.Kind == migration.IssueChecksumMismatch {
+			m.logger.Warn().Msg("Ignoring locally modified migration (allow_local_modifications): " + issue.Message)
+			continue
+		}
+		errors = append(errors, issue.Message)
+	}
+	if len(errors) > 0 {
 		return fmt.Errorf("checksum validation failed: %v", errors)
 	}
 
@@ -112,6 +131,9 @@ func (m *Migrator) Migrate() error {
 		m.logger.Info().Msg("Schema is up to date")
 		return nil
 	}
+	if err := migration.CheckStatementTypes(pending, m.config.AllowedStatementTypes); err != nil {
+		return err
+	}
 
 	executor := migration.NewExecutor(m.ctx)
 	_, err = executor.ExecuteAll(pending)
@@ -119,7 +141,7 @@ func (m *Migrator) Migrate() error {
 }
 
 func (m *Migrator) Status() (int, int, error) {
-	scanned, err := migration.ScanMigrationsDir(m.config.MigrationsDir)
+	scanned, err := migration.ScanMigrationsDirs(m.config.MigrationsDirs, migration.ParseOptionsFor(m.config))
 	if err != nil {
 		return 0, 0, err
 	}
@@ -129,8 +151,7 @@ func (m *Migrator) Status() (int, int, error) {
 		return 0, 0, err
 	}
 
-	resolver := migration.NewResolver(scanned)
-	pending, err := resolver.GetPendingMigrations(applied)
+	pending, err := migration.NewConfiguredResolver(m.ctx, scanned).GetPendingMigrations(applied)
 	if err != nil {
 		return 0, 0, err
 	}
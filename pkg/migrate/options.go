@@ -3,7 +3,10 @@ package migrate
 import (
 	"time"
 
+	"github.com/gocql/gocql"
+
 	"github.com/scylla-migrate/scylla-migrate/internal/config"
+	"github.com/scylla-migrate/scylla-migrate/internal/driver"
 )
 
 type Option func(*config.Config)
@@ -20,9 +23,27 @@ func WithKeyspace(keyspace string) Option {
 	}
 }
 
-func WithMigrationsDir(dir string) Option {
+// WithMigrationsDir sets the migrations directory. Passing several
+// directories merges them into one ordered sequence.
+func WithMigrationsDir(dirs ...string) Option {
+	return func(c *config.Config) {
+		c.MigrationsDirs = dirs
+	}
+}
+
+// WithEnvironment isolates the migration history of an environment sharing
+// the cluster by suffixing the metadata keyspace and lock id.
+func WithEnvironment(env string) Option {
+	return func(c *config.Config) {
+		c.Environment = env
+	}
+}
+
+// WithMaxMigrationFileSize rejects migration files larger than n bytes unless
+// they opt in to streaming. The limit is process-wide.
+func WithMaxMigrationFileSize(n int64) Option {
 	return func(c *config.Config) {
-		c.MigrationsDir = dir
+		c.MaxMigrationFileSize = n
 	}
 }
 
@@ -33,6 +54,27 @@ func WithAuth(username, password string) Option {
 	}
 }
 
+// CredentialSource supplies the username and password when WithAuth is not
+// used, e.g. from a secrets manager.
+type CredentialSource = config.CredentialSource
+
+// VaultConfig describes a HashiCorp Vault KV secret holding the credentials.
+type VaultConfig = config.VaultConfig
+
+// WithCredentialSource fetches the credentials from src when New runs.
+func WithCredentialSource(src CredentialSource) Option {
+	return func(c *config.Config) {
+		c.CredentialSource = src
+	}
+}
+
+// WithVault fetches the credentials from Vault when New runs.
+func WithVault(vault VaultConfig) Option {
+	return func(c *config.Config) {
+		c.Vault = vault
+	}
+}
+
 func WithConsistency(level string) Option {
 	return func(c *config.Config) {
 		c.Consistency = level
@@ -59,3 +101,59 @@ func WithSSL(caCert, clientCert, clientKey string) Option {
 		c.SSL.ClientKey = clientKey
 	}
 }
+
+// WithDDLCoordinator sends all DDL to one host ("auto" or an address) while
+// other statements are spread across the cluster.
+func WithDDLCoordinator(host string) Option {
+	return func(c *config.Config) {
+		c.DDLCoordinator = host
+	}
+}
+
+// NopObserver implements all gocql observer hooks as no-ops; embed it in an
+// observer that only needs some of them.
+type NopObserver = driver.NopObserver
+
+// LatencyObserver is a sample observer that logs each driver query attempt
+// and connection with its host and latency.
+type LatencyObserver = driver.LatencyObserver
+
+// WithQueryObserver receives every query attempt gocql makes, including its
+// host, attempt number and latency, so retries done inside the driver are
+// visible.
+func WithQueryObserver(o gocql.QueryObserver) Option {
+	return func(c *config.Config) {
+		c.Observers.Query = o
+	}
+}
+
+// WithConnectObserver receives every connection attempt gocql makes.
+func WithConnectObserver(o gocql.ConnectObserver) Option {
+	return func(c *config.Config) {
+		c.Observers.Connect = o
+	}
+}
+
+// WithFrameHeaderObserver receives every frame header gocql reads.
+func WithFrameHeaderObserver(o gocql.FrameHeaderObserver) Option {
+	return func(c *config.Config) {
+		c.Observers.FrameHeader = o
+	}
+}
+
+// WithQueryLog logs every driver query and connection through the
+// migrator's logger, warning on failures and on queries slower than
+// slowThreshold (0 = never).
+func WithQueryLog(slowThreshold time.Duration) Option {
+	return func(c *config.Config) {
+		c.QueryLog = config.QueryLogConfig{Enabled: true, SlowThreshold: slowThreshold}
+	}
+}
+
+// WithStatementSeparator splits migration files on sep instead of ";", for
+// files written for other tools (e.g. ";;" or "GO").
+func WithStatementSeparator(sep string) Option {
+	return func(c *config.Config) {
+		c.StatementSeparator = sep
+	}
+}